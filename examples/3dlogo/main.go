@@ -166,7 +166,10 @@ func main() {
 
 	pw.SetColor("none")
 	mwf.SetImageBackgroundColor(pw)
-	mwc = mwf.MergeImageLayers(imagick.IMAGE_LAYER_MERGE)
+	mwc, err = mwf.MergeImageLayers(imagick.IMAGE_LAYER_MERGE)
+	if err != nil {
+		panic(err)
+	}
 	mwc.WriteImage("logo_shadow_3D.png")
 
 	mw.Destroy()
@@ -218,7 +221,10 @@ func main() {
 	mwc.AddImage(mwf)
 	mwc.AddImage(mw)
 
-	mwf = mwc.MergeImageLayers(imagick.IMAGE_LAYER_FLATTEN)
+	mwf, err = mwc.MergeImageLayers(imagick.IMAGE_LAYER_FLATTEN)
+	if err != nil {
+		panic(err)
+	}
 
 	if err := mwf.DisplayImage(os.Getenv("DISPLAY")); err != nil {
 		panic(err)
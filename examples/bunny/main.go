@@ -30,7 +30,10 @@ func main() {
 	// for the next operation
 	aw.Destroy()
 	// -coalesce
-	aw = mw.CoalesceImages()
+	aw, err := mw.CoalesceImages()
+	if err != nil {
+		panic(err)
+	}
 
 	// do "-delete 0" by copying the images from the "aw" wand to
 	// the "mw" wand but omit the first one
@@ -40,7 +43,10 @@ func main() {
 
 	for i := 1; i < int(aw.GetNumberImages()); i++ {
 		aw.SetIteratorIndex(i)
-		tw := aw.GetImage()
+		tw, err := aw.GetImage()
+		if err != nil {
+			panic(err)
+		}
 		mw.AddImage(tw)
 		tw.Destroy()
 	}
@@ -50,7 +56,10 @@ func main() {
 	// -deconstruct
 	// Anthony says that MagickDeconstructImages is equivalent
 	// to MagickCompareImagesLayers so we'll use that
-	aw = mw.CompareImageLayers(imagick.IMAGE_LAYER_COMPARE_ANY)
+	aw, err = mw.CompareImageLayers(imagick.IMAGE_LAYER_COMPARE_ANY)
+	if err != nil {
+		panic(err)
+	}
 	// -loop 0
 	aw.SetOption("loop", "0")
 
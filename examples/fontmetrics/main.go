@@ -31,7 +31,10 @@ func draw_setfont(mw *imagick.MagickWand, dw *imagick.DrawingWand, font string,
 	// If either the font or the fontsize (or both) have changed
 	// we need to get the size of a space again
 	if sflag {
-		fm := mw.QueryFontMetrics(dw, " ")
+		fm, err := mw.QueryFontMetrics(dw, " ")
+		if err != nil {
+			panic(err)
+		}
 		*sx = fm.TextWidth
 	}
 }
@@ -49,8 +52,8 @@ func draw_metrics(mw *imagick.MagickWand, dw *imagick.DrawingWand, dx *float64,
 	mw.DrawImage(dw)
 
 	// get the font metrics
-	fm := mw.QueryFontMetrics(dw, text)
-	if fm != nil {
+	fm, err := mw.QueryFontMetrics(dw, text)
+	if err == nil {
 		// Adjust the new x coordinate
 		*dx += fm.TextWidth + sx
 	}
@@ -88,7 +91,10 @@ func main() {
 	// to compute the baseline
 	dw.SetFontSize(72)
 	dw.SetFont("Times-New-Roman")
-	fm := mw.QueryFontMetrics(dw, "M")
+	fm, err := mw.QueryFontMetrics(dw, "M")
+	if err != nil {
+		panic(err)
+	}
 	dy = fm.CharacterHeight + fm.Descender
 	// Note that we must free up the fontmetric array once we're done with it
 
@@ -155,7 +155,10 @@ func main() {
 
 	pw.SetColor("none")
 	mwf.SetImageBackgroundColor(pw)
-	mw = mwf.MergeImageLayers(imagick.IMAGE_LAYER_FLATTEN)
+	mw, err = mwf.MergeImageLayers(imagick.IMAGE_LAYER_FLATTEN)
+	if err != nil {
+		panic(err)
+	}
 	mw.WriteImage("gel_button.png")
 
 	mw.Destroy()
@@ -10,12 +10,18 @@ func make_tile(mw *imagick.MagickWand, outfile string) {
 	mwc.FlopImage()
 	mw.AddImage(mwc)
 	mwc.Destroy()
-	mwc = mw.AppendImages(false)
+	mwc, err := mw.AppendImages(false)
+	if err != nil {
+		panic(err)
+	}
 	mwf := mwc.Clone()
 	mwf.FlipImage()
 	mwc.AddImage(mwf)
 	mwf.Destroy()
-	mwf = mwc.AppendImages(true)
+	mwf, err = mwc.AppendImages(true)
+	if err != nil {
+		panic(err)
+	}
 
 	w := mwf.GetImageWidth()
 	h := mwf.GetImageHeight()
@@ -40,7 +40,10 @@ func usePixelIterator() {
 	/* Read the input image */
 	mw.ReadImage("logo:")
 	// Get a one-pixel region at coordinate 200,100
-	iterator := mw.NewPixelRegionIterator(200, 100, 1, 1)
+	iterator, err := mw.NewPixelRegionIterator(200, 100, 1, 1)
+	if err != nil {
+		panic(err)
+	}
 	pixels := iterator.GetNextIteratorRow()
 	// Modify the pixel
 	pixels[0].SetColor("red")
@@ -41,7 +41,10 @@ func main() {
 	mw.SetFirstIterator()
 
 	// Append the reflection to the bottom (MagickTrue) of the original image
-	mwout := mw.AppendImages(true)
+	mwout, err := mw.AppendImages(true)
+	if err != nil {
+		panic(err)
+	}
 
 	// and save the result
 	mwout.WriteImage("logo_reflect.png")
@@ -0,0 +1,226 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+type AutoThresholdMethod int
+
+const (
+	// Minimizes the variance within the foreground and background
+	AUTO_THRESHOLD_OTSU AutoThresholdMethod = iota + 1
+	// Cuts where the histogram is farthest from the line between its peak
+	// and its far end, suited to a small foreground on a large background
+	AUTO_THRESHOLD_TRIANGLE
+)
+
+// Converts the current image to grayscale and thresholds it to black and
+// white at a threshold computed from its histogram by method. Pixels above
+// the threshold become white. ImageMagick 6 has no MagickAutoThresholdImage,
+// so the threshold is computed in Go from an 8-bit histogram.
+func (mw *MagickWand) AutoThresholdImage(method AutoThresholdMethod) error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("AutoThresholdImage: wand has no image")
+	}
+	if err := mw.TransformImageColorspace(COLORSPACE_GRAY); err != nil {
+		return err
+	}
+	pixels := make([]byte, int(width)*int(height))
+	if err := mw.exportPixels(0, 0, width, height, "I", PIXEL_CHAR, unsafe.Pointer(&pixels[0])); err != nil {
+		return err
+	}
+	var histogram [256]int
+	for _, v := range pixels {
+		histogram[v]++
+	}
+
+	var threshold int
+	switch method {
+	case AUTO_THRESHOLD_OTSU:
+		threshold = otsuThreshold(&histogram)
+	case AUTO_THRESHOLD_TRIANGLE:
+		threshold = triangleThreshold(&histogram)
+	default:
+		return fmt.Errorf("AutoThresholdImage: unknown method %d", method)
+	}
+
+	// Values which round to threshold in 8 bits are background too
+	_, quantumRange := GetQuantumRange()
+	return mw.ThresholdImage((float64(threshold) + 0.5) * float64(quantumRange) / 255)
+}
+
+// Returns the 8-bit value at or below which pixels are background, choosing
+// the split with the largest variance between the two classes.
+func otsuThreshold(histogram *[256]int) int {
+	var total, sum float64
+	for v, n := range histogram {
+		total += float64(n)
+		sum += float64(v * n)
+	}
+
+	var background, backgroundSum, best float64
+	threshold := 0
+	for v, n := range histogram {
+		background += float64(n)
+		if background == 0 {
+			continue
+		}
+		foreground := total - background
+		if foreground == 0 {
+			break
+		}
+		backgroundSum += float64(v * n)
+		meanBackground := backgroundSum / background
+		meanForeground := (sum - backgroundSum) / foreground
+		between := background * foreground * (meanBackground - meanForeground) * (meanBackground - meanForeground)
+		if between > best {
+			best, threshold = between, v
+		}
+	}
+	return threshold
+}
+
+// Returns the 8-bit value at or below which pixels are background, using
+// Zack's triangle method on the longer side of the histogram peak.
+func triangleThreshold(histogram *[256]int) int {
+	first, last, peak := -1, -1, 0
+	for v, n := range histogram {
+		if n == 0 {
+			continue
+		}
+		if first < 0 {
+			first = v
+		}
+		last = v
+		if n > histogram[peak] {
+			peak = v
+		}
+	}
+	if first < 0 || first == last {
+		return first
+	}
+
+	// Walk from the peak towards the end of the longer tail
+	end, step := last, 1
+	if peak-first > last-peak {
+		end, step = first, -1
+	}
+	dx, dy := float64(end-peak), float64(histogram[end]-histogram[peak])
+	threshold, best := peak, 0.0
+	for v := peak; v != end; v += step {
+		// Distance to the line from the peak to the end, up to a constant
+		d := dy*float64(v-peak) - dx*float64(histogram[v]-histogram[peak])
+		if d < 0 {
+			d = -d
+		}
+		if d > best {
+			best, threshold = d, v
+		}
+	}
+	if step < 0 {
+		// Background is the bright side, so the cut falls just below it
+		threshold--
+	}
+	return threshold
+}
+
+// BinarizeOptions overrides the stages of PrepareForBinarization, the zero
+// value selects the defaults
+type BinarizeOptions struct {
+	// Leave the image rotated as it is
+	SkipDeskew bool
+	// Deskew threshold as a fraction of QuantumRange, 0 means 0.40
+	DeskewThreshold float64
+	// Side of the adaptive threshold neighborhood in pixels, 0 means an
+	// eighth of the smaller image side, at least 15 pixels
+	Window uint
+	// Offset of the threshold from the neighborhood mean as a fraction of
+	// QuantumRange, 0 means -0.05 so that flat areas stay white
+	Offset float64
+	// Upscaling filter, FILTER_UNDEFINED means point sampling. Other
+	// filters are thresholded again at 50% after upscaling.
+	Filter FilterType
+}
+
+// Prepares the current image for barcode and QR code decoders: it is
+// flattened on white, converted to grayscale, deskewed, thresholded to black
+// and white by its local neighborhood and enlarged scale times without
+// smoothing, so that modules keep sharp edges. A scale of 0 or 1 keeps the
+// size. opts may be nil.
+func (mw *MagickWand) PrepareForBinarization(scale uint, opts *BinarizeOptions) error {
+	if opts == nil {
+		opts = &BinarizeOptions{}
+	}
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("PrepareForBinarization: wand has no image")
+	}
+	_, quantumRange := GetQuantumRange()
+
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+	// Deskewing fills the corners with the background color
+	if err := mw.SetImageBackgroundColor(white); err != nil {
+		return err
+	}
+	if mw.GetImageAlphaChannel() {
+		if err := mw.SetImageAlphaChannel(ALPHA_CHANNEL_REMOVE); err != nil {
+			return err
+		}
+	}
+	if err := mw.TransformImageColorspace(COLORSPACE_GRAY); err != nil {
+		return err
+	}
+
+	if !opts.SkipDeskew {
+		threshold := opts.DeskewThreshold
+		if threshold == 0 {
+			threshold = 0.40
+		}
+		if err := mw.DeskewImage(threshold * float64(quantumRange)); err != nil {
+			return err
+		}
+		if err := mw.ResetImagePage("0x0+0+0"); err != nil {
+			return err
+		}
+		width, height = mw.GetImageWidth(), mw.GetImageHeight()
+	}
+
+	window := opts.Window
+	if window == 0 {
+		window = maxUint(minUint(width, height)/8, 15)
+	}
+	window = minUint(window, minUint(width, height))
+	offset := opts.Offset
+	if offset == 0 {
+		offset = -0.05
+	}
+	if err := mw.AdaptiveThresholdImage(window, window, int(math.Round(offset*float64(quantumRange)))); err != nil {
+		return err
+	}
+
+	if scale > 1 {
+		if opts.Filter == FILTER_UNDEFINED {
+			if err := mw.SampleImage(width*scale, height*scale); err != nil {
+				return err
+			}
+		} else {
+			if err := mw.ResizeImage(width*scale, height*scale, opts.Filter, 1); err != nil {
+				return err
+			}
+			if err := mw.ThresholdImage(float64(quantumRange) / 2); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.SetImageType(IMAGE_TYPE_BILEVEL)
+}
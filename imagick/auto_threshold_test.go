@@ -0,0 +1,131 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// Returns a grayscale image with 30% of its pixels around 60 and the rest
+// around 190
+func bimodalPixels(width, height int) []byte {
+	r := rand.New(rand.NewSource(1))
+	pixels := make([]byte, width*height)
+	for i := range pixels {
+		mode := 190
+		if r.Intn(10) < 3 {
+			mode = 60
+		}
+		pixels[i] = byte(maxInt(0, minInt(255, mode+int(r.NormFloat64()*12))))
+	}
+	return pixels
+}
+
+func TestAutoThresholdImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const width, height = 100, 100
+	pixels := bimodalPixels(width, height)
+
+	var histogram [256]int
+	for _, v := range pixels {
+		histogram[v]++
+	}
+	if threshold := otsuThreshold(&histogram); threshold <= 90 || threshold >= 160 {
+		t.Fatalf("Expected the Otsu threshold between the modes, got %d", threshold)
+	}
+	if threshold := triangleThreshold(&histogram); threshold <= 60 || threshold >= 190 {
+		t.Fatalf("Expected the triangle threshold between the modes, got %d", threshold)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(width, height, "I", PIXEL_CHAR, pixels); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.AutoThresholdImage(AUTO_THRESHOLD_OTSU); err != nil {
+		t.Fatal(err)
+	}
+	val, err := mw.ExportImagePixels(0, 0, width, height, "I", PIXEL_CHAR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreground := 0
+	for _, v := range val.([]byte) {
+		switch v {
+		case 255:
+			foreground++
+		case 0:
+		default:
+			t.Fatalf("Expected a black and white image, got value %d", v)
+		}
+	}
+	if want := width * height * 7 / 10; foreground < want-width*height/50 || foreground > want+width*height/50 {
+		t.Fatalf("Expected about %d foreground pixels, got %d", want, foreground)
+	}
+
+	if err := mw.AutoThresholdImage(AutoThresholdMethod(0)); err == nil {
+		t.Fatal("Expected an error for an unknown method")
+	}
+}
+
+func TestPrepareForBinarization(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// A checker pattern of 20 pixel squares, skewed by 5 degrees
+	const size, square = 160, 20
+	pixels := make([]byte, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x/square+y/square)%2 == 1 {
+				pixels[y*size+x] = 255
+			}
+		}
+	}
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(size, size, "I", PIXEL_CHAR, pixels); err != nil {
+		t.Fatal(err)
+	}
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+	if err := mw.RotateImage(white, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.PrepareForBinarization(2, nil); err != nil {
+		t.Fatal(err)
+	}
+	angle, err := mw.GetImageProperty("deskew:angle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if degrees, err := strconv.ParseFloat(angle, 64); err != nil || math.Abs(math.Abs(degrees)-5) > 1 {
+		t.Errorf("Expected a deskew angle of 5 degrees, got %q", angle)
+	}
+	if _, _, x, y, err := mw.GetImagePage(); err != nil || x != 0 || y != 0 {
+		t.Errorf("Expected no page offset, got %d,%d (%v)", x, y, err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w < 2*size || h < 2*size {
+		t.Errorf("Expected the image scaled up twice, at least %dx%d, got %dx%d", 2*size, 2*size, w, h)
+	}
+	if colors := mw.GetImageColors(); colors != 2 {
+		t.Errorf("Expected black and white only, got %d colors", colors)
+	}
+	if imageType := mw.GetImageType(); imageType != IMAGE_TYPE_BILEVEL && imageType != IMAGE_TYPE_GRAYSCALE {
+		t.Errorf("Expected a bilevel image, got type %d", imageType)
+	}
+}
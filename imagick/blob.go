@@ -0,0 +1,117 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Blob is an encoded image in memory allocated by ImageMagick, returned by
+// GetImageBlobNoCopy and GetImagesBlobNoCopy. It reads from that memory
+// without copying it into Go memory. Free it when done, it is freed by the
+// garbage collector otherwise. Using a Blob after Free panics. A Blob is not
+// safe for concurrent use.
+type Blob struct {
+	data unsafe.Pointer
+	size int
+	// Read offset
+	off int
+}
+
+func newBlob(data unsafe.Pointer, size int) *Blob {
+	b := &Blob{data: data, size: size}
+	runtime.SetFinalizer(b, (*Blob).Free)
+	atomic.AddInt64(&blobCounter, int64(1))
+	trackObject("Blob", unsafe.Pointer(b))
+	unsetCanTerminate()
+	return b
+}
+
+// Relinquishes the memory of the blob. Further calls do nothing.
+func (b *Blob) Free() {
+	if b.data == nil {
+		return
+	}
+	relinquishMemory(b.data)
+	runtime.SetFinalizer(b, nil)
+	b.data = nil
+
+	atomic.AddInt64(&blobCounter, int64(-1))
+	untrackObject(unsafe.Pointer(b))
+	setCanTerminate()
+}
+
+// Frees the blob, implementing io.Closer. It always returns nil.
+func (b *Blob) Close() error {
+	b.Free()
+	return nil
+}
+
+// Returns the size of the blob in bytes
+func (b *Blob) Len() int {
+	b.checkLive()
+	return b.size
+}
+
+// Largest slice of the blob memory, see chunk
+const maxBlobChunk = 1 << 30
+
+// Returns the blob as a slice of the ImageMagick memory. The slice must not
+// be used after Free, and must not be modified if the blob is read again.
+// Copy it to keep it. Bytes panics for a blob larger than 1 GiB, use Read
+// or WriteTo for those.
+func (b *Blob) Bytes() []byte {
+	b.checkLive()
+	return b.chunk(0, b.size)
+}
+
+// Reads the blob from the read offset, implementing io.Reader
+func (b *Blob) Read(p []byte) (int, error) {
+	b.checkLive()
+	if b.off >= b.size {
+		return 0, io.EOF
+	}
+	n := copy(p, b.chunk(b.off, minInt(b.size-b.off, maxBlobChunk)))
+	b.off += n
+	runtime.KeepAlive(b)
+	return n, nil
+}
+
+// Writes the blob from the read offset to w straight from the ImageMagick
+// memory, implementing io.WriterTo, so that io.Copy does not copy it
+func (b *Blob) WriteTo(w io.Writer) (int64, error) {
+	b.checkLive()
+	var written int64
+	for b.off < b.size {
+		chunk := b.chunk(b.off, minInt(b.size-b.off, maxBlobChunk))
+		n, err := w.Write(chunk)
+		b.off += n
+		written += int64(n)
+		if err == nil && n < len(chunk) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			runtime.KeepAlive(b)
+			return written, err
+		}
+	}
+	runtime.KeepAlive(b)
+	return written, nil
+}
+
+// Returns n bytes of the blob memory from off. The array type only
+// describes the memory, n must not exceed maxBlobChunk.
+func (b *Blob) chunk(off, n int) []byte {
+	return (*[maxBlobChunk]byte)(unsafe.Pointer(uintptr(b.data) + uintptr(off)))[:n:n]
+}
+
+func (b *Blob) checkLive() {
+	if b.data == nil {
+		panic("imagick: Blob used after Free")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var _ interface {
+	io.ReadCloser
+	io.WriterTo
+} = (*Blob)(nil)
+
+func TestGetImageBlobNoCopy(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("TIFF"); err != nil {
+		t.Fatal(err)
+	}
+	copied := mw.GetImageBlob()
+
+	blob, err := mw.GetImageBlobNoCopy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var live bytes.Buffer
+	if err := DumpLiveObjects(&live); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(live.String(), "Blob: 1 live") {
+		t.Errorf("Expected the blob to be counted as live:\n%s", live.String())
+	}
+
+	var written bytes.Buffer
+	if n, err := io.Copy(&written, blob); err != nil || n != int64(len(copied)) {
+		t.Fatalf("Expected to write %d bytes, wrote %d (%v)", len(copied), n, err)
+	}
+	if !bytes.Equal(written.Bytes(), copied) {
+		t.Fatal("Expected WriteTo to write the same bytes as GetImageBlob")
+	}
+	if n, err := blob.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("Expected EOF after WriteTo, got %d, %v", n, err)
+	}
+	if blob.Len() != len(copied) || !bytes.Equal(blob.Bytes(), copied) {
+		t.Error("Expected Bytes to return the blob")
+	}
+	blob.Free()
+	blob.Free()
+
+	blob, err = mw.GetImageBlobNoCopy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A reader without WriteTo, so that Read is used
+	read, err := ioutil.ReadAll(io.LimitReader(blob, int64(len(copied))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, copied) {
+		t.Fatal("Expected Read to return the same bytes as GetImageBlob")
+	}
+	if err := blob.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "after Free") {
+			t.Errorf("Expected a panic using the blob after Free, got %v", r)
+		}
+	}()
+	blob.Bytes()
+}
+
+func TestGetImagesBlobNoCopy(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := gradientSequence(t, 3)
+	defer mw.Destroy()
+	if err := mw.SetImageFormat("GIF"); err != nil {
+		t.Fatal(err)
+	}
+	copied := mw.GetImagesBlob()
+	blob, err := mw.GetImagesBlobNoCopy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blob.Free()
+	if !bytes.Equal(blob.Bytes(), copied) {
+		t.Error("Expected the same bytes as GetImagesBlob")
+	}
+
+	empty := NewMagickWand()
+	defer empty.Destroy()
+	if _, err := empty.GetImageBlobNoCopy(); err == nil {
+		t.Error("Expected an error encoding an empty wand")
+	}
+}
+
+// The allocations reported are those of Go memory: GetImageBlob allocates
+// a copy of the blob on top of the memory ImageMagick encodes it to, which
+// GetImageBlobNoCopy does not
+func BenchmarkGetImageBlob(b *testing.B) {
+	benchmarkWriteBlob(b, func(mw *MagickWand) (int64, error) {
+		return io.Copy(ioutil.Discard, bytes.NewReader(mw.GetImageBlob()))
+	})
+}
+
+func BenchmarkGetImageBlobNoCopy(b *testing.B) {
+	benchmarkWriteBlob(b, func(mw *MagickWand) (int64, error) {
+		blob, err := mw.GetImageBlobNoCopy()
+		if err != nil {
+			return 0, err
+		}
+		defer blob.Free()
+		return io.Copy(ioutil.Discard, blob)
+	})
+}
+
+func benchmarkWriteBlob(b *testing.B, write func(*MagickWand) (int64, error)) {
+	Initialize()
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		b.Fatal(err)
+	}
+	if err := mw.SetImageFormat("TIFF"); err != nil {
+		b.Fatal(err)
+	}
+	if err := mw.SetImageCompression(COMPRESSION_NO); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, err := write(mw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(n)
+	}
+}
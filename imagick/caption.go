@@ -0,0 +1,82 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"strings"
+)
+
+// Returns a new wand holding text rendered with the caption: pseudo format,
+// wrapped to width. A height of 0 grows the image to fit the text, a
+// pointSize of 0 picks the largest size which fits width x height. An empty
+// font, nil fill or nil background keep the ImageMagick defaults of black
+// text on white.
+//
+// The text is rendered literally: it is not parsed as a filename and
+// neither % escapes nor a leading @ (which would read a file) are
+// interpreted.
+func NewCaptionWand(text string, width, height uint, font string, pointSize float64, fill, background *PixelWand) (*MagickWand, error) {
+	if width == 0 {
+		return nil, errors.New("NewCaptionWand: width must not be 0")
+	}
+
+	mw := NewMagickWand()
+	err := mw.setCaptionSettings(width, height, font, pointSize, fill, background)
+	if err == nil {
+		// The caption coder takes its text from the filename option, which
+		// avoids the filename parsing of ReadImage
+		err = mw.SetOption("filename", "caption:"+escapeImageProperties(text))
+	}
+	if err == nil {
+		err = mw.ReadImage("caption:")
+	}
+	if err == nil {
+		err = mw.DeleteOption("filename")
+	}
+	if err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+func (mw *MagickWand) setCaptionSettings(width, height uint, font string, pointSize float64, fill, background *PixelWand) error {
+	if err := mw.SetSize(width, height); err != nil {
+		return err
+	}
+	if font != "" {
+		if err := mw.SetFont(font); err != nil {
+			return err
+		}
+	}
+	if pointSize > 0 {
+		if err := mw.SetPointsize(pointSize); err != nil {
+			return err
+		}
+	}
+	if fill != nil {
+		if err := mw.SetOption("fill", fill.GetColorAsString()); err != nil {
+			return err
+		}
+	}
+	if background != nil {
+		if err := mw.SetBackgroundColor(background); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Escapes text so that ImageMagick's property interpretation returns it
+// unchanged: % escapes and backslashes are doubled, and a leading @ is
+// escaped so it is not read as "@file".
+func escapeImageProperties(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, `%`, `%%`).Replace(text)
+	if strings.HasPrefix(text, "@") {
+		text = `\` + text
+	}
+	return text
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestNewCaptionWand(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	text := "The quick brown fox jumps over the lazy dog, then naps in the sun for the rest of the afternoon."
+	mw, err := NewCaptionWand(text, 200, 100, "", 0, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mw.Destroy()
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 200 || h != 100 {
+		t.Fatalf("Expected 200x100, got %dx%d", w, h)
+	}
+
+	trimmed, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trimmed.Destroy()
+	if err := trimmed.TrimImage(0); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := trimmed.GetImageWidth(), trimmed.GetImageHeight(); w <= 1 || h <= 1 {
+		t.Fatalf("Expected text to be drawn, trim box is %dx%d", w, h)
+	}
+}
+
+func TestNewCaptionWandLiteral(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	for _, text := range []string{"@/etc/passwd", "100% sure: %w", `C:\path[0]`, "two\nlines"} {
+		mw, err := NewCaptionWand(text, 300, 0, "", 12, nil, nil)
+		if err != nil {
+			t.Fatalf("%q: %s", text, err)
+		}
+		caption, err := mw.GetImageProperty("caption")
+		if err != nil {
+			t.Errorf("%q: %s", text, err)
+		} else if caption != text {
+			t.Errorf("Expected caption %q to be rendered literally, got %q", text, caption)
+		}
+		mw.Destroy()
+	}
+}
+
+func TestEscapeImageProperties(t *testing.T) {
+	tests := []struct {
+		text, escaped string
+	}{
+		{"plain", "plain"},
+		{"@file", `\@file`},
+		{"a@b", "a@b"},
+		{"50%", "50%%"},
+		{`a\nb`, `a\\nb`},
+	}
+	for _, tt := range tests {
+		if escaped := escapeImageProperties(tt.text); escaped != tt.escaped {
+			t.Errorf("%q: expected %q, got %q", tt.text, tt.escaped, escaped)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"math"
+	"unsafe"
+)
+
+// Makes the pixels of the current image whose hue is within hueTolerance of
+// targetHue transparent, e.g. a green screen with a targetHue of 1/3. Hues
+// are fractions of a full turn from 0 to 1, as in PixelWand.GetHSL.
+//
+// saturationMin: pixels less saturated than this are kept, grays have no
+// reliable hue.
+//
+// lightnessRange: pixels whose lightness is further than lightnessRange/2
+// from 0.5 are kept, 1 keys pixels of any lightness.
+//
+// despill: desaturates the kept pixels whose hue is within twice the
+// tolerance, removing the tint the screen casts onto the edges of the
+// subject.
+//
+// Pixels which are kept and not despilled are left untouched, existing
+// transparency is kept.
+func (mw *MagickWand) ChromaKey(targetHue, hueTolerance, saturationMin, lightnessRange float64, despill bool) error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("ChromaKey: wand has no image")
+	}
+	if hueTolerance <= 0 {
+		return errors.New("ChromaKey: hueTolerance must be above 0")
+	}
+
+	pixels := make([]float32, int(width)*int(height)*4)
+	if err := mw.exportPixels(0, 0, width, height, "RGBA", PIXEL_FLOAT, unsafe.Pointer(&pixels[0])); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(pixels); i += 4 {
+		r, g, b := float64(pixels[i]), float64(pixels[i+1]), float64(pixels[i+2])
+		h, s, l := rgbToHSL(r, g, b)
+		if s < saturationMin || math.Abs(l-0.5) > lightnessRange/2 {
+			continue
+		}
+		distance := hueDistance(h, targetHue)
+		switch {
+		case distance <= hueTolerance:
+			pixels[i+3] = 0
+		case despill && distance < 2*hueTolerance:
+			s *= (distance - hueTolerance) / hueTolerance
+			r, g, b = hslToRGB(h, s, l)
+			pixels[i], pixels[i+1], pixels[i+2] = float32(r), float32(g), float32(b)
+		}
+	}
+
+	if err := mw.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		return err
+	}
+	return mw.ImportImagePixels(0, 0, width, height, "RGBA", PIXEL_FLOAT, pixels)
+}
+
+// Returns the distance between two hues on the color wheel, 0 to 0.5
+func hueDistance(a, b float64) float64 {
+	d := math.Abs(a - b)
+	d -= math.Floor(d)
+	if d > 0.5 {
+		d = 1 - d
+	}
+	return d
+}
+
+// Converts RGB to HSL, all values from 0 to 1
+func rgbToHSL(r, g, b float64) (h, s, l float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	delta := max - min
+	if delta == 0 {
+		return 0, 0, l
+	}
+	if l < 0.5 {
+		s = delta / (max + min)
+	} else {
+		s = delta / (2 - max - min)
+	}
+	switch max {
+	case r:
+		h = (g - b) / delta
+	case g:
+		h = 2 + (b-r)/delta
+	default:
+		h = 4 + (r-g)/delta
+	}
+	h /= 6
+	if h < 0 {
+		h++
+	}
+	return h, s, l
+}
+
+// Converts HSL to RGB, all values from 0 to 1
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	if s == 0 {
+		return l, l, l
+	}
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	channel := func(t float64) float64 {
+		t -= math.Floor(t)
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 0.5:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		}
+		return p
+	}
+	return channel(h + 1.0/3), channel(h), channel(h - 1.0/3)
+}
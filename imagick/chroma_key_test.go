@@ -0,0 +1,82 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChromaKey(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:#00C000[40x40]"); err != nil {
+		t.Fatal(err)
+	}
+	// A brighter part of the screen is keyed as well
+	for _, part := range []struct {
+		image string
+		x, y  int
+	}{
+		{"xc:#40FF40[40x10]", 0, 30},
+		{"xc:#CC2211[10x10]", 15, 10},
+	} {
+		overlay := NewMagickWand()
+		if err := overlay.ReadImage(part.image); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.CompositeImage(overlay, COMPOSITE_OP_OVER, part.x, part.y); err != nil {
+			t.Fatal(err)
+		}
+		overlay.Destroy()
+	}
+
+	if err := mw.ChromaKey(1.0/3, 0.05, 0.3, 0.9, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range [][2]int{{0, 0}, {39, 20}, {5, 35}} {
+		pw, err := mw.GetImagePixelColor(p[0], p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alpha := pw.GetAlpha(); alpha != 0 {
+			t.Errorf("Pixel %v: expected the screen to be transparent, got alpha %f", p, alpha)
+		}
+		pw.Destroy()
+	}
+
+	expectPixelColor(t, mw, 20, 15, "#CC2211")
+	red, err := mw.GetImagePixelColor(20, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer red.Destroy()
+	if alpha := red.GetAlpha(); alpha != 1 {
+		t.Errorf("Expected the subject to be opaque, got alpha %f", alpha)
+	}
+}
+
+func TestHSLConversion(t *testing.T) {
+	for _, rgb := range [][3]float64{{1, 0, 0}, {0, 0.75, 0}, {0.2, 0.4, 0.9}, {0.5, 0.5, 0.5}, {1, 1, 0}} {
+		h, s, l := rgbToHSL(rgb[0], rgb[1], rgb[2])
+		r, g, b := hslToRGB(h, s, l)
+		if math.Abs(r-rgb[0]) > 1e-9 || math.Abs(g-rgb[1]) > 1e-9 || math.Abs(b-rgb[2]) > 1e-9 {
+			t.Errorf("%v: round trip through HSL %f,%f,%f gave %f,%f,%f", rgb, h, s, l, r, g, b)
+		}
+	}
+	if h, _, _ := rgbToHSL(0, 1, 0); math.Abs(h-1.0/3) > 1e-9 {
+		t.Errorf("Expected green at a hue of 1/3, got %f", h)
+	}
+	if d := hueDistance(0.95, 0.05); math.Abs(d-0.1) > 1e-9 {
+		t.Errorf("Expected hue distance to wrap around, got %f", d)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// CMYKFallbackProfile is the ICC profile ToSRGB assumes for CMYK images
+// without an embedded profile, e.g. the U.S. Web Coated (SWOP) profile
+// Photoshop uses. No profile ships with this package, so if it is nil such
+// images are converted by TransformImageColorspace.
+var CMYKFallbackProfile []byte
+
+// Returns true if the current image is in the CMYK colorspace, whose pixels
+// must not be treated as RGB.
+func (mw *MagickWand) IsCMYK() bool {
+	return mw.GetImageColorspace() == COLORSPACE_CMYK
+}
+
+// Converts the current image to sRGB. CMYK images are color managed with
+// ProfileImage from their embedded ICC profile, or CMYKFallbackProfile, to
+// targetProfile, an RGB ICC profile such as sRGB. If targetProfile is empty,
+// or the image has no source profile, the image is converted with
+// TransformImageColorspace, which ignores the characteristics of the press
+// and so is only an approximation.
+//
+// Color management needs ImageMagick built with lcms, otherwise an error
+// wrapping ErrMissingDelegate is returned.
+func (mw *MagickWand) ToSRGB(targetProfile []byte) error {
+	if !mw.IsCMYK() {
+		if mw.GetImageColorspace() == COLORSPACE_SRGB {
+			return nil
+		}
+		return mw.TransformImageColorspace(COLORSPACE_SRGB)
+	}
+
+	hasProfile := len(mw.GetImageProfile("icc")) > 0
+	if len(targetProfile) == 0 || !hasProfile && len(CMYKFallbackProfile) == 0 {
+		return mw.TransformImageColorspace(COLORSPACE_SRGB)
+	}
+	if err := requireDelegate("ToSRGB", "lcms"); err != nil {
+		return err
+	}
+	if !hasProfile {
+		// The first profile of an image is only assigned, not converted to
+		if err := mw.ProfileImage("icc", CMYKFallbackProfile); err != nil {
+			return err
+		}
+	}
+	if err := mw.ProfileImage("icc", targetProfile); err != nil {
+		return err
+	}
+	if mw.GetImageColorspace() != COLORSPACE_SRGB {
+		return mw.SetImageColorspace(COLORSPACE_SRGB)
+	}
+	return nil
+}
+
+// Returns the raw cyan, magenta, yellow and black separations of a region of
+// the current image, 4 bytes per pixel. Returns an error if the image is not
+// CMYK, see ToSRGB.
+func (mw *MagickWand) ExportCMYK(x, y int, cols, rows uint) ([]uint8, error) {
+	if !mw.IsCMYK() {
+		return nil, errors.New("ExportCMYK: image is not CMYK")
+	}
+	if cols == 0 || rows == 0 {
+		return nil, errors.New("ExportCMYK: cols and rows must be above 0")
+	}
+	pixels := make([]uint8, int(cols)*int(rows)*4)
+	if err := mw.exportPixels(x, y, cols, rows, "CMYK", PIXEL_CHAR, unsafe.Pointer(&pixels[0])); err != nil {
+		return nil, err
+	}
+	return pixels, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestCMYKToSRGB(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	if !HasDelegate("jpeg") {
+		t.Skip("ImageMagick has no JPEG support")
+	}
+
+	// A CMYK JPEG without an embedded profile
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	pw := NewPixelWand()
+	defer pw.Destroy()
+	pw.SetColor("red")
+	if err := mw.NewImage(16, 16, pw); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.TransformImageColorspace(COLORSPACE_CMYK); err != nil {
+		t.Fatal(err)
+	}
+	cmyk := encodeDecode(t, mw, "JPEG")
+	defer cmyk.Destroy()
+
+	if !cmyk.IsCMYK() {
+		t.Fatalf("Expected a CMYK image, got colorspace %d", cmyk.GetImageColorspace())
+	}
+	separations, err := cmyk.ExportCMYK(8, 8, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{0, 255, 255, 0} {
+		if d := int(separations[i]) - want; d < -8 || d > 8 {
+			t.Fatalf("Expected CMYK separations near 0,255,255,0, got %v", separations)
+		}
+	}
+
+	if err := cmyk.ToSRGB(nil); err != nil {
+		t.Fatal(err)
+	}
+	if cs := cmyk.GetImageColorspace(); cs != COLORSPACE_SRGB {
+		t.Fatalf("Expected sRGB after ToSRGB, got colorspace %d", cs)
+	}
+	if cmyk.IsCMYK() {
+		t.Fatal("IsCMYK is true after ToSRGB")
+	}
+	expectPixelColor(t, cmyk, 8, 8, "red")
+	if _, err := cmyk.ExportCMYK(0, 0, 1, 1); err == nil {
+		t.Fatal("Expected an error exporting CMYK from an sRGB image")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+)
+
+// CompareReport is the result of CompareWithReport()
+type CompareReport struct {
+	// The value of the metric as returned by CompareImages
+	Distortion float64
+	// The difference from 0 (identical) to 1, see CompareWithReport
+	Score float64
+	// Whether the distortion is within the threshold
+	Passed bool
+	// The image with the changed pixels highlighted in red, like the
+	// compare command produces. Free it with Destroy.
+	DiffImage *MagickWand
+	// The number of differing pixels, only set for METRIC_ABSOLUTE_ERROR
+	PixelErrorCount uint
+}
+
+// Destroys the difference image of the report
+func (r *CompareReport) Destroy() {
+	if r.DiffImage != nil {
+		r.DiffImage.Destroy()
+		r.DiffImage = nil
+	}
+}
+
+// Compares the current image to reference, which must be of the same size,
+// and reports the result. For METRIC_PEAK_SIGNAL_TO_NOISE_RATIO higher is
+// better, so the comparison passes if the distortion is at or above
+// threshold, for all other metrics if it is at or below.
+//
+// The Score is the fraction of differing pixels for METRIC_ABSOLUTE_ERROR,
+// the equivalent normalized root mean squared error for
+// METRIC_PEAK_SIGNAL_TO_NOISE_RATIO, and the distortion clamped to 0..1
+// for the other metrics, which ImageMagick already normalizes.
+func (mw *MagickWand) CompareWithReport(reference *MagickWand, metric MetricType, threshold float64) (*CompareReport, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if err := reference.valid(); err != nil {
+		return nil, err
+	}
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	refW, refH := reference.GetImageWidth(), reference.GetImageHeight()
+	if width != refW || height != refH {
+		return nil, fmt.Errorf("CompareWithReport: image sizes differ, %dx%d and %dx%d", width, height, refW, refH)
+	}
+
+	// ImageMagick 6 reads the unprefixed names, later versions the
+	// compare: prefixed ones
+	artifacts := map[string]string{
+		"highlight-color":         "#f1001ecc",
+		"lowlight-color":          "#ffffffcc",
+		"compare:highlight-color": "#f1001ecc",
+		"compare:lowlight-color":  "#ffffffcc",
+	}
+	for artifact, value := range artifacts {
+		if err := mw.SetImageArtifact(artifact, value); err != nil {
+			return nil, err
+		}
+		defer mw.DeleteImageArtifact(artifact)
+	}
+
+	var distortion C.double
+	// Not unlocked by defer, the deferred artifact deletions lock mw
+	mw.mu.Lock()
+	cdiff := C.MagickCompareImages(mw.mw, reference.mw, C.MetricType(metric), &distortion)
+	runtime.KeepAlive(mw)
+	runtime.KeepAlive(reference)
+	var err error
+	if cdiff == nil {
+		err = mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	mw.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CompareReport{
+		Distortion: float64(distortion),
+		DiffImage:  newMagickWand(cdiff),
+	}
+	switch metric {
+	case METRIC_ABSOLUTE_ERROR:
+		report.PixelErrorCount = uint(report.Distortion)
+		report.Score = report.Distortion / float64(width*height)
+		report.Passed = report.Distortion <= threshold
+	case METRIC_PEAK_SIGNAL_TO_NOISE_RATIO:
+		if !math.IsInf(report.Distortion, 1) && report.Distortion != 0 {
+			report.Score = math.Pow(10, -report.Distortion/20)
+		}
+		report.Passed = report.Distortion >= threshold || math.IsInf(report.Distortion, 1)
+	default:
+		report.Score = report.Distortion
+		report.Passed = report.Distortion <= threshold
+	}
+	report.Score = math.Max(0, math.Min(1, report.Score))
+	return report, nil
+}
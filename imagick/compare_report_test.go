@@ -0,0 +1,93 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestCompareWithReport(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:gray50[40x40]"); err != nil {
+		t.Fatal(err)
+	}
+	blanked, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blanked.Destroy()
+	black := NewMagickWand()
+	defer black.Destroy()
+	if err := black.ReadImage("xc:black[10x10]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := blanked.CompositeImage(black, COMPOSITE_OP_COPY, 10, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := mw.CompareWithReport(blanked, METRIC_ABSOLUTE_ERROR, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer report.Destroy()
+	if report.Passed {
+		t.Errorf("Expected 100 changed pixels to fail a threshold of 10")
+	}
+	if report.PixelErrorCount != 100 {
+		t.Errorf("Expected 100 changed pixels, got %d", report.PixelErrorCount)
+	}
+	if report.Score != 100.0/1600 {
+		t.Errorf("Expected a score of %f, got %f", 100.0/1600, report.Score)
+	}
+
+	for _, p := range []struct {
+		x, y    int
+		changed bool
+	}{
+		{10, 10, true},
+		{19, 19, true},
+		{5, 5, false},
+		{20, 15, false},
+		{35, 35, false},
+	} {
+		pw, err := report.DiffImage.GetImagePixelColor(p.x, p.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		highlighted := pw.GetRed() > 0.7 && pw.GetGreen() < 0.3
+		if highlighted != p.changed {
+			t.Errorf("Pixel (%d,%d): expected highlighted=%v, got %s", p.x, p.y, p.changed, pw.GetColorAsString())
+		}
+		pw.Destroy()
+	}
+	if _, err := mw.GetImageArtifact("highlight-color"); err == nil {
+		t.Errorf("Expected the highlight artifact to be removed again")
+	}
+
+	same, err := mw.CompareWithReport(mw, METRIC_ROOT_MEAN_SQUARED_ERROR, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer same.Destroy()
+	if !same.Passed || same.Score != 0 {
+		t.Errorf("Expected an image to match itself, got %+v", same)
+	}
+
+	rose := NewMagickWand()
+	defer rose.Destroy()
+	if err := rose.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.CompareWithReport(rose, METRIC_ABSOLUTE_ERROR, 0); err == nil {
+		t.Fatal("Expected an error comparing images of different sizes")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"unsafe"
+)
+
+// ComponentObject is an object found by ConnectedComponents
+type ComponentObject struct {
+	// Starts at 1, in the order of the first pixel of each object in
+	// scanline order
+	Id          int
+	BoundingBox RectangleInfo
+	// Number of pixels
+	Area     int
+	Centroid PointInfo
+	// Mean color of the pixels of the object in the original image
+	MeanColor color.NRGBA
+}
+
+// Finds the objects of the current image, the groups of connected pixels
+// brighter than 50% gray, e.g. the stickers on a scanned sheet. connectivity
+// is 4 to connect pixels to their horizontal and vertical neighbours, or 8
+// to also connect diagonal neighbours. Objects smaller than minArea pixels
+// are treated as background.
+//
+// The objects are labelled in Go with a two pass union-find. ImageMagick 6
+// only reports the objects of its ConnectedComponentsImage by printing them,
+// so the results do not depend on the ImageMagick version.
+func (mw *MagickWand) ConnectedComponents(connectivity int, minArea int) ([]ComponentObject, error) {
+	if connectivity != 4 && connectivity != 8 {
+		return nil, fmt.Errorf("ConnectedComponents: connectivity must be 4 or 8, got %d", connectivity)
+	}
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return nil, errors.New("ConnectedComponents: wand has no image")
+	}
+	w, h := int(width), int(height)
+
+	gray := make([]byte, w*h)
+	if err := mw.exportPixels(0, 0, width, height, "I", PIXEL_CHAR, unsafe.Pointer(&gray[0])); err != nil {
+		return nil, err
+	}
+	labels := labelComponents(gray, w, h, connectivity)
+
+	rgba := make([]byte, w*h*4)
+	if err := mw.exportPixels(0, 0, width, height, "RGBA", PIXEL_CHAR, unsafe.Pointer(&rgba[0])); err != nil {
+		return nil, err
+	}
+
+	type stats struct {
+		area, minX, minY, maxX, maxY int
+		sumX, sumY                   float64
+		sum                          [4]float64
+	}
+	var objects []*stats
+	for i, label := range labels {
+		if label == 0 {
+			continue
+		}
+		x, y := i%w, i/w
+		if label > len(objects) {
+			objects = append(objects, &stats{minX: x, minY: y, maxX: x, maxY: y})
+		}
+		s := objects[label-1]
+		s.area++
+		s.minX, s.maxX = minInt(s.minX, x), maxInt(s.maxX, x)
+		s.minY, s.maxY = minInt(s.minY, y), maxInt(s.maxY, y)
+		s.sumX += float64(x)
+		s.sumY += float64(y)
+		for c := range s.sum {
+			s.sum[c] += float64(rgba[4*i+c])
+		}
+	}
+
+	var components []ComponentObject
+	for _, s := range objects {
+		if s.area < minArea {
+			continue
+		}
+		n := float64(s.area)
+		mean := func(c int) uint8 { return uint8(s.sum[c]/n + 0.5) }
+		components = append(components, ComponentObject{
+			Id:          len(components) + 1,
+			BoundingBox: newRectangleInfo(s.minX, s.minY, uint(s.maxX-s.minX+1), uint(s.maxY-s.minY+1)),
+			Area:        s.area,
+			Centroid:    PointInfo{s.sumX / n, s.sumY / n},
+			MeanColor:   color.NRGBA{mean(0), mean(1), mean(2), mean(3)},
+		})
+	}
+	return components, nil
+}
+
+// Returns the object label of every pixel, 0 for background and from 1 in
+// the order of the first pixel of each object. Pixels above 127 are
+// foreground.
+func labelComponents(gray []byte, width, height, connectivity int) []int {
+	labels := make([]int, len(gray))
+	parent := []int{0}
+	find := func(l int) int {
+		for parent[l] != l {
+			parent[l] = parent[parent[l]]
+			l = parent[l]
+		}
+		return l
+	}
+	union := func(a, b int) {
+		a, b = find(a), find(b)
+		if a < b {
+			parent[b] = a
+		} else if b < a {
+			parent[a] = b
+		}
+	}
+
+	// First pass: provisional labels, recording which ones touch
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			if gray[i] <= 127 {
+				continue
+			}
+			var neighbours [4]int
+			n := 0
+			if x > 0 {
+				neighbours[n], n = labels[i-1], n+1
+			}
+			if y > 0 {
+				neighbours[n], n = labels[i-width], n+1
+				if connectivity == 8 {
+					if x > 0 {
+						neighbours[n], n = labels[i-width-1], n+1
+					}
+					if x < width-1 {
+						neighbours[n], n = labels[i-width+1], n+1
+					}
+				}
+			}
+			label := 0
+			for _, l := range neighbours[:n] {
+				if l == 0 {
+					continue
+				}
+				if label == 0 {
+					label = l
+				} else {
+					union(label, l)
+				}
+			}
+			if label == 0 {
+				label = len(parent)
+				parent = append(parent, label)
+			}
+			labels[i] = label
+		}
+	}
+
+	// Second pass: resolve to the root and renumber in scanline order
+	final := make([]int, len(parent))
+	next := 1
+	for i, l := range labels {
+		if l == 0 {
+			continue
+		}
+		root := find(l)
+		if final[root] == 0 {
+			final[root] = next
+			next++
+		}
+		labels[i] = final[root]
+	}
+	return labels
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestConnectedComponents(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const width, height = 40, 30
+	type box struct{ x, y, w, h int }
+	boxes := []box{{2, 3, 10, 5}, {20, 2, 6, 12}, {5, 20, 30, 6}}
+	pixels := make([]byte, width*height)
+	for _, b := range boxes {
+		for y := b.y; y < b.y+b.h; y++ {
+			for x := b.x; x < b.x+b.w; x++ {
+				pixels[y*width+x] = 255
+			}
+		}
+	}
+	// Specks that minArea must drop
+	pixels[0] = 255
+	pixels[width*height-1] = 255
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(width, height, "I", PIXEL_CHAR, pixels); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, connectivity := range []int{4, 8} {
+		objects, err := mw.ConnectedComponents(connectivity, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(objects) != len(boxes) {
+			t.Fatalf("Connectivity %d: expected %d objects, got %d", connectivity, len(boxes), len(objects))
+		}
+		for i, b := range boxes {
+			o := objects[i]
+			bb := o.BoundingBox
+			if o.Id != i+1 || bb.GetX() != b.x || bb.GetY() != b.y || int(bb.GetWidth()) != b.w || int(bb.GetHeight()) != b.h {
+				t.Errorf("Connectivity %d: object %d is %+v at %d,%d %dx%d, want %+v",
+					connectivity, i, o, bb.GetX(), bb.GetY(), bb.GetWidth(), bb.GetHeight(), b)
+			}
+			if o.Area != b.w*b.h || o.MeanColor.R != 255 {
+				t.Errorf("Connectivity %d: object %d has area %d and color %v", connectivity, i, o.Area, o.MeanColor)
+			}
+			cx, cy := float64(b.x)+float64(b.w-1)/2, float64(b.y)+float64(b.h-1)/2
+			if o.Centroid.X != cx || o.Centroid.Y != cy {
+				t.Errorf("Connectivity %d: object %d centroid %v, want %v,%v", connectivity, i, o.Centroid, cx, cy)
+			}
+		}
+	}
+
+	if _, err := mw.ConnectedComponents(6, 0); err == nil {
+		t.Fatal("Expected an error for connectivity 6")
+	}
+}
+
+func TestLabelComponentsDiagonal(t *testing.T) {
+	gray := []byte{
+		255, 0, 0,
+		0, 255, 0,
+		255, 0, 255,
+	}
+	count := func(labels []int) int {
+		max := 0
+		for _, l := range labels {
+			max = maxInt(max, l)
+		}
+		return max
+	}
+	if n := count(labelComponents(gray, 3, 3, 4)); n != 4 {
+		t.Errorf("Expected 4 objects with connectivity 4, got %d", n)
+	}
+	if n := count(labelComponents(gray, 3, 3, 8)); n != 1 {
+		t.Errorf("Expected 1 object with connectivity 8, got %d", n)
+	}
+}
@@ -52,7 +52,7 @@ func sizedDoubleArrayToFloat64Slice(p *C.double, num C.size_t) []float64 {
 	for i := 0; i < int(num); i++ {
 		p = (*C.double)(unsafe.Pointer(q))
 		nums = append(nums, float64(*p))
-		q += unsafe.Sizeof(q)
+		q += unsafe.Sizeof(*p)
 	}
 	return nums
 }
@@ -0,0 +1,166 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// Returns the CIEDE2000 color difference between two sRGB colors. A
+// difference below 1 is generally not visible, alpha is ignored.
+func (pw *PixelWand) DeltaE(other *PixelWand) float64 {
+	return deltaE2000(
+		srgbToLab(pw.GetRed(), pw.GetGreen(), pw.GetBlue()),
+		srgbToLab(other.GetRed(), other.GetGreen(), other.GetBlue()))
+}
+
+// Returns the mean CIEDE2000 color difference between the pixels of the
+// current images of mw and reference, which must be of the same size. The
+// pixels are exported as sRGB and converted to CIELAB (D65) in Go, alpha is
+// ignored.
+func (mw *MagickWand) MeanDeltaE(reference *MagickWand) (float64, error) {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return 0, errors.New("MeanDeltaE: wand has no image")
+	}
+	if refW, refH := reference.GetImageWidth(), reference.GetImageHeight(); refW != width || refH != height {
+		return 0, fmt.Errorf("MeanDeltaE: image sizes differ, %dx%d and %dx%d", width, height, refW, refH)
+	}
+
+	a, err := mw.exportSRGB(width, height)
+	if err != nil {
+		return 0, err
+	}
+	b, err := reference.exportSRGB(width, height)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum float64
+	for i := 0; i < len(a); i += 3 {
+		sum += deltaE2000(
+			srgbToLab(float64(a[i]), float64(a[i+1]), float64(a[i+2])),
+			srgbToLab(float64(b[i]), float64(b[i+1]), float64(b[i+2])))
+	}
+	return sum / float64(width*height), nil
+}
+
+// Returns the pixels of the current image as normalized sRGB triples
+func (mw *MagickWand) exportSRGB(width, height uint) ([]float32, error) {
+	if mw.GetImageColorspace() == COLORSPACE_CMYK {
+		rgb, err := mw.currentImage()
+		if err != nil {
+			return nil, err
+		}
+		defer rgb.Destroy()
+		if err := rgb.TransformImageColorspace(COLORSPACE_SRGB); err != nil {
+			return nil, err
+		}
+		mw = rgb
+	}
+	pixels := make([]float32, int(width)*int(height)*3)
+	if err := mw.exportPixels(0, 0, width, height, "RGB", PIXEL_FLOAT, unsafe.Pointer(&pixels[0])); err != nil {
+		return nil, err
+	}
+	return pixels, nil
+}
+
+// lab is a CIELAB color
+type lab struct {
+	L, A, B float64
+}
+
+// Converts normalized sRGB to CIELAB with a D65 white point
+func srgbToLab(r, g, b float64) lab {
+	linear := func(c float64) float64 {
+		if c <= 0.04045 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	r, g, b = linear(r), linear(g), linear(b)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		const delta = 6.0 / 29
+		if t > delta*delta*delta {
+			return math.Cbrt(t)
+		}
+		return t/(3*delta*delta) + 4.0/29
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return lab{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// Returns the CIEDE2000 difference of two colors, following Sharma, Wu and
+// Dalal, "The CIEDE2000 Color-Difference Formula: Implementation Notes".
+func deltaE2000(c1, c2 lab) float64 {
+	const deg = math.Pi / 180
+
+	cBar := (math.Hypot(c1.A, c1.B) + math.Hypot(c2.A, c2.B)) / 2
+	cBar7 := math.Pow(cBar, 7)
+	g := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+math.Pow(25, 7))))
+
+	a1, a2 := (1+g)*c1.A, (1+g)*c2.A
+	cp1, cp2 := math.Hypot(a1, c1.B), math.Hypot(a2, c2.B)
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / deg
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	hp1, hp2 := hue(c1.B, a1), hue(c2.B, a2)
+
+	dL := c2.L - c1.L
+	dC := cp2 - cp1
+	var dh float64
+	if cp1*cp2 != 0 {
+		dh = hp2 - hp1
+		if dh > 180 {
+			dh -= 360
+		} else if dh < -180 {
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(cp1*cp2) * math.Sin(dh/2*deg)
+
+	lBar := (c1.L + c2.L) / 2
+	cpBar := (cp1 + cp2) / 2
+	hBar := hp1 + hp2
+	if cp1*cp2 != 0 {
+		switch {
+		case math.Abs(hp1-hp2) <= 180:
+			hBar /= 2
+		case hp1+hp2 < 360:
+			hBar = (hBar + 360) / 2
+		default:
+			hBar = (hBar - 360) / 2
+		}
+	}
+
+	t := 1 - 0.17*math.Cos((hBar-30)*deg) + 0.24*math.Cos(2*hBar*deg) +
+		0.32*math.Cos((3*hBar+6)*deg) - 0.20*math.Cos((4*hBar-63)*deg)
+	dTheta := 30 * math.Exp(-math.Pow((hBar-275)/25, 2))
+	cpBar7 := math.Pow(cpBar, 7)
+	rc := 2 * math.Sqrt(cpBar7/(cpBar7+math.Pow(25, 7)))
+	l50 := (lBar - 50) * (lBar - 50)
+	sl := 1 + 0.015*l50/math.Sqrt(20+l50)
+	sc := 1 + 0.045*cpBar
+	sh := 1 + 0.015*cpBar*t
+	rt := -math.Sin(2*dTheta*deg) * rc
+
+	lTerm, cTerm, hTerm := dL/sl, dC/sc, dH/sh
+	return math.Sqrt(lTerm*lTerm + cTerm*cTerm + hTerm*hTerm + rt*cTerm*hTerm)
+}
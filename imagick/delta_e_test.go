@@ -0,0 +1,134 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeltaE2000(t *testing.T) {
+	// Test pairs from Sharma, Wu and Dalal's CIEDE2000 implementation notes
+	tests := []struct {
+		a, b  lab
+		delta float64
+	}{
+		{lab{50, 2.6772, -79.7751}, lab{50, 0, -82.7485}, 2.0425},
+		{lab{50, 3.1571, -77.2803}, lab{50, 0, -82.7485}, 2.8615},
+		{lab{50, 2.8361, -74.0200}, lab{50, 0, -82.7485}, 3.4412},
+		{lab{50, -1.3802, -84.2814}, lab{50, 0, -82.7485}, 1.0000},
+		{lab{50, -1.1848, -84.8006}, lab{50, 0, -82.7485}, 1.0000},
+		{lab{50, -0.9009, -85.5211}, lab{50, 0, -82.7485}, 1.0000},
+		{lab{50, 0, 0}, lab{50, -1, 2}, 2.3669},
+		{lab{50, -1, 2}, lab{50, 0, 0}, 2.3669},
+		{lab{50, 2.4900, -0.0010}, lab{50, -2.4900, 0.0009}, 7.1792},
+		{lab{50, 2.4900, -0.0010}, lab{50, -2.4900, 0.0010}, 7.1792},
+		{lab{50, 2.4900, -0.0010}, lab{50, -2.4900, 0.0011}, 7.2195},
+		{lab{50, 2.4900, -0.0010}, lab{50, -2.4900, 0.0012}, 7.2195},
+		{lab{50, -0.0010, 2.4900}, lab{50, 0.0009, -2.4900}, 4.8045},
+		{lab{50, 2.5, 0}, lab{73, 25, -18}, 27.1492},
+		{lab{50, 2.5, 0}, lab{61, -5, 29}, 22.8977},
+		{lab{50, 2.5, 0}, lab{56, -27, -3}, 31.9030},
+		{lab{50, 2.5, 0}, lab{58, 24, 15}, 19.4535},
+		{lab{50, 2.5, 0}, lab{50, 3.1736, 0.5854}, 1.0000},
+		{lab{60.2574, -34.0099, 36.2677}, lab{60.4626, -34.1751, 39.4387}, 1.2644},
+		{lab{63.0109, -31.0961, -5.8663}, lab{62.8187, -29.7946, -4.0864}, 1.2630},
+		{lab{22.7233, 20.0904, -46.6940}, lab{23.0331, 14.9730, -42.5619}, 2.0373},
+		{lab{36.4612, 47.8580, 18.3852}, lab{36.2715, 50.5065, 21.2231}, 1.4146},
+		{lab{90.8027, -2.0831, 1.4410}, lab{91.1528, -1.6435, 0.0447}, 1.4441},
+		{lab{90.9257, -0.5406, -0.9208}, lab{88.6381, -0.8985, -0.7239}, 1.5381},
+		{lab{6.7747, -0.2908, -2.4247}, lab{5.8714, -0.0985, -2.2286}, 0.6377},
+		{lab{2.0776, 0.0795, -1.1350}, lab{0.9033, -0.0636, -0.5514}, 0.9082},
+	}
+	for i, tt := range tests {
+		if delta := deltaE2000(tt.a, tt.b); math.Abs(delta-tt.delta) > 1e-4 {
+			t.Errorf("Pair %d: expected %.4f, got %.4f", i+1, tt.delta, delta)
+		}
+	}
+}
+
+func TestSRGBToLab(t *testing.T) {
+	tests := []struct {
+		r, g, b float64
+		lab     lab
+	}{
+		{1, 1, 1, lab{100, 0, 0}},
+		{0, 0, 0, lab{0, 0, 0}},
+		{1, 0, 0, lab{53.2408, 80.0925, 67.2032}},
+	}
+	for _, tt := range tests {
+		got := srgbToLab(tt.r, tt.g, tt.b)
+		if math.Abs(got.L-tt.lab.L) > 0.01 || math.Abs(got.A-tt.lab.A) > 0.01 || math.Abs(got.B-tt.lab.B) > 0.01 {
+			t.Errorf("%v,%v,%v: expected %+v, got %+v", tt.r, tt.g, tt.b, tt.lab, got)
+		}
+	}
+}
+
+func TestMeanDeltaE(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	a := NewPixelWand()
+	defer a.Destroy()
+	a.SetColor("red")
+	b := NewPixelWand()
+	defer b.Destroy()
+	b.SetColor("red")
+	if delta := a.DeltaE(b); delta != 0 {
+		t.Fatalf("Expected no difference between equal colors, got %f", delta)
+	}
+	b.SetColor("#FE0000")
+	if delta := a.DeltaE(b); delta <= 0 || delta > 1 {
+		t.Fatalf("Expected an invisible difference, got %f", delta)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	same, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer same.Destroy()
+	if delta, err := mw.MeanDeltaE(same); err != nil || delta != 0 {
+		t.Fatalf("Expected no difference to a copy, got %f (%v)", delta, err)
+	}
+
+	if err := same.ModulateImage(100, 100, 110); err != nil {
+		t.Fatal(err)
+	}
+	if delta, err := mw.MeanDeltaE(same); err != nil || delta <= 0 {
+		t.Fatalf("Expected a difference after shifting the hue, got %f (%v)", delta, err)
+	}
+
+	small := NewMagickWand()
+	defer small.Destroy()
+	if err := small.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.MeanDeltaE(small); err == nil {
+		t.Fatal("Expected an error comparing images of different sizes")
+	}
+}
+
+func BenchmarkMeanDeltaE(b *testing.B) {
+	wand := NewMagickWand()
+	wand.ReadImage("logo:")
+	wand.ScaleImage(1024, 1024)
+	reference := wand.Clone()
+	reference.BlurImage(0, 2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := wand.MeanDeltaE(reference); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+type DirectionType int
+
+const (
+	DIRECTION_UNDEFINED     DirectionType = C.UndefinedDirection
+	DIRECTION_RIGHT_TO_LEFT DirectionType = C.RightToLeftDirection
+	DIRECTION_LEFT_TO_RIGHT DirectionType = C.LeftToRightDirection
+)
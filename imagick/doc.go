@@ -0,0 +1,19 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imagick is a Go binding to the ImageMagick MagickWand C API.
+//
+// Call Initialize before creating any wand, and Terminate when done.
+// Initialize and Terminate are reference counted, so that independent
+// packages of one process can each pair them: the environment is torn down
+// when every Initialize has been matched by a Terminate.
+//
+// Constructors called before Initialize or after the environment was
+// terminated used to crash in C. NewMagickWand, NewMagickWandFromImage,
+// NewPixelWand and NewDrawingWand now panic with an error wrapping
+// ErrNotInitialized instead. Code which may run outside of
+// Initialize/Terminate, e.g. a request handler of a server shutting down,
+// should use NewMagickWandChecked, NewMagickWandFromImageChecked,
+// NewPixelWandChecked and NewDrawingWandChecked, which return the error.
+package imagick
@@ -28,9 +28,23 @@ func newDrawingWand(cdw *C.DrawingWand) *DrawingWand {
 	return dw
 }
 
-// Returns a drawing wand required for all other methods in the API.
+// Returns a drawing wand required for all other methods in the API. Panics
+// if the environment is not initialized, see NewDrawingWandChecked().
 func NewDrawingWand() *DrawingWand {
-	return newDrawingWand(C.NewDrawingWand())
+	dw, err := NewDrawingWandChecked()
+	if err != nil {
+		panic("imagick: " + err.Error())
+	}
+	return dw
+}
+
+// Like NewDrawingWand, but returns an error wrapping ErrNotInitialized if
+// Initialize was not called or the environment was terminated.
+func NewDrawingWandChecked() (*DrawingWand, error) {
+	if err := checkInitialized("NewDrawingWand"); err != nil {
+		return nil, err
+	}
+	return newDrawingWand(C.NewDrawingWand()), nil
 }
 
 // Clears resources associated with the drawing wand.
@@ -63,15 +77,24 @@ func (dw *DrawingWand) Destroy() {
 	})
 }
 
+// Destroys the DrawingWand, see Destroy(). It implements io.Closer and may be
+// called more than once.
+func (dw *DrawingWand) Close() error {
+	dw.Destroy()
+	return nil
+}
+
 // Increase DrawingWand ref counter and set according "can`t be terminated status"
 func (dw *DrawingWand) IncreaseCount() {
 	atomic.AddInt64(&drawingWandCounter, int64(1))
+	trackObject("DrawingWand", unsafe.Pointer(dw))
 	unsetCanTerminate()
 }
 
 // Decrease DrawingWand ref counter and set according "can be terminated status"
 func (dw *DrawingWand) DecreaseCount() {
 	atomic.AddInt64(&drawingWandCounter, int64(-1))
+	untrackObject(unsafe.Pointer(dw))
 	setCanTerminate()
 }
 
@@ -159,6 +182,9 @@ func (dw *DrawingWand) Circle(ox, oy, px, py float64) {
 // mw: Image to composite is obtained from this wand.
 //
 func (dw *DrawingWand) Composite(compose CompositeOperator, x, y, width, height float64, mw *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
 	ok := C.DrawComposite(dw.dw, C.CompositeOperator(compose), C.double(x), C.double(y), C.double(width), C.double(height), mw.mw)
 	return dw.getLastErrorIfFailed(ok)
 }
@@ -419,6 +445,13 @@ func (dw *DrawingWand) GetTextDecoration() DecorationType {
 	return ret
 }
 
+// Returns the direction that will be used when annotating with text.
+func (dw *DrawingWand) GetTextDirection() DirectionType {
+	ret := DirectionType(C.DrawGetTextDirection(dw.dw))
+	runtime.KeepAlive(dw)
+	return ret
+}
+
 // Returns a string which specifies the code set used for text annotations.
 func (dw *DrawingWand) GetTextEncoding() string {
 	cstr := C.DrawGetTextEncoding(dw.dw)
@@ -436,7 +469,7 @@ func (dw *DrawingWand) GetTextKerning() float64 {
 
 // Gets the spacing between lines in text.
 func (dw *DrawingWand) GetTextInterlineSpacing() float64 {
-	ret := float64(C.DrawGetTextInterwordSpacing(dw.dw))
+	ret := float64(C.DrawGetTextInterlineSpacing(dw.dw))
 	runtime.KeepAlive(dw)
 	return ret
 }
@@ -690,10 +723,13 @@ func (dw *DrawingWand) PathEllipticArcRelative(rx, ry, xAxisRotation float64, la
 	runtime.KeepAlive(dw)
 }
 
-// Terminates the current path.
-func (dw *DrawingWand) PathFinish() {
+// Terminates the current path. Returns any exception raised by the path
+// commands since PathStart(), so a malformed path is reported here rather
+// than only when the wand is drawn.
+func (dw *DrawingWand) PathFinish() error {
 	C.DrawPathFinish(dw.dw)
 	runtime.KeepAlive(dw)
+	return dw.GetLastError()
 }
 
 // Draws a line path from the current point to the given coordinate using
@@ -810,10 +846,12 @@ func (dw *DrawingWand) Polyline(coordinates []PointInfo) {
 	runtime.KeepAlive(dw)
 }
 
-// Terminates a clip path definition.
-func (dw *DrawingWand) PopClipPath() {
+// Terminates a clip path definition. Returns any exception raised while the
+// clip path was defined.
+func (dw *DrawingWand) PopClipPath() error {
 	C.DrawPopClipPath(dw.dw)
 	runtime.KeepAlive(dw)
+	return dw.GetLastError()
 }
 
 // Terminates a definition list.
@@ -825,6 +863,7 @@ func (dw *DrawingWand) PopDefs() {
 // Terminates a pattern definition.
 func (dw *DrawingWand) PopPattern() error {
 	ok := C.DrawPopPattern(dw.dw)
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
 
@@ -862,6 +901,7 @@ func (dw *DrawingWand) PushPattern(patternId string, x, y, width, height float64
 	cstr := C.CString(patternId)
 	defer C.free(unsafe.Pointer(cstr))
 	ok := C.DrawPushPattern(dw.dw, cstr, C.double(x), C.double(y), C.double(width), C.double(height))
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
 
@@ -928,6 +968,7 @@ func (dw *DrawingWand) SetClipPath(clipMaskId string) error {
 	cstr := C.CString(clipMaskId)
 	defer C.free(unsafe.Pointer(cstr))
 	ok := C.DrawSetClipPath(dw.dw, cstr)
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
 
@@ -981,6 +1022,7 @@ func (dw *DrawingWand) SetFillPatternURL(fillUrl string) error {
 	cstr := C.CString(fillUrl)
 	defer C.free(unsafe.Pointer(cstr))
 	ok := C.DrawSetFillPatternURL(dw.dw, cstr)
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
 
@@ -1150,6 +1192,13 @@ func (dw *DrawingWand) SetTextDecoration(decoration DecorationType) {
 	runtime.KeepAlive(dw)
 }
 
+// Specifies the direction to be used when annotating with text, e.g.
+// DIRECTION_RIGHT_TO_LEFT for Arabic or Hebrew.
+func (dw *DrawingWand) SetTextDirection(direction DirectionType) {
+	C.DrawSetTextDirection(dw.dw, C.DirectionType(direction))
+	runtime.KeepAlive(dw)
+}
+
 // Specifies the code set to use for text annotations. The only character
 // encoding which may be specified at this time is "UTF-8" for representing
 // Unicode as a sequence of bytes. Specify an empty string to set text
@@ -1262,6 +1311,7 @@ func (dw *DrawingWand) PeekDrawingWand() *DrawInfo {
 // pop all drawing wands which have been pushed.
 func (dw *DrawingWand) PopDrawingWand() error {
 	ok := C.PopDrawingWand(dw.dw)
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
 
@@ -1271,5 +1321,6 @@ func (dw *DrawingWand) PopDrawingWand() error {
 // already been an equivalent Push.
 func (dw *DrawingWand) PushDrawingWand() error {
 	ok := C.PushDrawingWand(dw.dw)
+	runtime.KeepAlive(dw)
 	return dw.getLastErrorIfFailed(ok)
 }
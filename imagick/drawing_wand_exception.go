@@ -46,7 +46,10 @@ func (dw *DrawingWand) GetLastError() error {
 
 func (dw *DrawingWand) getLastErrorIfFailed(ok C.MagickBooleanType) error {
 	if C.int(ok) == 0 {
-		return dw.GetLastError()
+		if err := dw.GetLastError(); err != nil {
+			return err
+		}
+		return &DrawingWandException{ERROR_WAND, callerName(1) + ": operation failed"}
 	} else {
 		return nil
 	}
@@ -0,0 +1,255 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+// Asserts that the pixel at x,y of the current image is similar to color
+func expectPixelColor(t *testing.T, mw *MagickWand, x, y int, color string) {
+	t.Helper()
+
+	want := NewPixelWand()
+	defer want.Destroy()
+	if !want.SetColor(color) {
+		t.Fatalf("Could not set color %q", color)
+	}
+
+	got, err := mw.GetImagePixelColor(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Destroy()
+	if !got.IsSimilarNormalized(want, 0.05) {
+		t.Fatalf("Expected pixel (%d,%d) to be %s, got %s", x, y, color, got.GetColorAsString())
+	}
+}
+
+func TestDrawPath(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	red := NewPixelWand()
+	defer red.Destroy()
+	white := NewPixelWand()
+	defer white.Destroy()
+
+	red.SetColor("red")
+	white.SetColor("white")
+	if err := mw.NewImage(100, 100, white); err != nil {
+		t.Fatal(err)
+	}
+
+	dw.SetFillColor(red)
+	dw.PathStart()
+	dw.PathMoveToAbsolute(10, 10)
+	dw.PathLineToHorizontalAbsolute(90)
+	dw.PathLineToRelative(-40, 80)
+	dw.PathClose()
+	if err := dw.PathFinish(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.DrawImage(dw); err != nil {
+		t.Fatal(err)
+	}
+
+	// Centroid of (10,10), (90,10), (50,90)
+	expectPixelColor(t, mw, 50, 37, "red")
+	// Outside the triangle, next to its bottom vertex and the image corners
+	expectPixelColor(t, mw, 20, 80, "white")
+	expectPixelColor(t, mw, 98, 98, "white")
+}
+
+func TestDrawClipPath(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	blue := NewPixelWand()
+	defer blue.Destroy()
+	white := NewPixelWand()
+	defer white.Destroy()
+
+	blue.SetColor("blue")
+	white.SetColor("white")
+	if err := mw.NewImage(100, 100, white); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dw.PushDrawingWand(); err != nil {
+		t.Fatal(err)
+	}
+	dw.PushClipPath("circle")
+	dw.Circle(50, 50, 50, 80)
+	if err := dw.PopClipPath(); err != nil {
+		t.Fatal(err)
+	}
+	dw.SetClipRule(FILL_NON_ZERO)
+	dw.SetClipUnits(CLIP_USER_SPACE)
+	if err := dw.SetClipPath("circle"); err != nil {
+		t.Fatal(err)
+	}
+	dw.SetFillColor(blue)
+	dw.Rectangle(0, 0, 99, 99)
+	if err := dw.PopDrawingWand(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.DrawImage(dw); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPixelColor(t, mw, 50, 50, "blue")
+	expectPixelColor(t, mw, 50, 25, "blue")
+	expectPixelColor(t, mw, 5, 5, "white")
+	expectPixelColor(t, mw, 94, 94, "white")
+
+	if err := dw.PopDrawingWand(); err == nil {
+		t.Fatal("Expected an error popping more drawing wands than were pushed")
+	}
+}
+
+func TestDrawPattern(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	black := NewPixelWand()
+	defer black.Destroy()
+	red := NewPixelWand()
+	defer red.Destroy()
+	white := NewPixelWand()
+	defer white.Destroy()
+
+	black.SetColor("black")
+	red.SetColor("red")
+	white.SetColor("white")
+	if err := mw.NewImage(100, 100, white); err != nil {
+		t.Fatal(err)
+	}
+
+	// A 20x20 checkerboard of 10px black and red squares
+	if err := dw.PushPattern("checker", 0, 0, 20, 20); err != nil {
+		t.Fatal(err)
+	}
+	dw.SetFillColor(black)
+	dw.Rectangle(0, 0, 9, 9)
+	dw.Rectangle(10, 10, 19, 19)
+	dw.SetFillColor(red)
+	dw.Rectangle(10, 0, 19, 9)
+	dw.Rectangle(0, 10, 9, 19)
+	if err := dw.PopPattern(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dw.SetFillPatternURL("#checker"); err != nil {
+		t.Fatal(err)
+	}
+	dw.Rectangle(0, 0, 99, 99)
+	if err := mw.DrawImage(dw); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPixelColor(t, mw, 5, 5, "black")
+	expectPixelColor(t, mw, 15, 5, "red")
+	expectPixelColor(t, mw, 5, 15, "red")
+	expectPixelColor(t, mw, 15, 15, "black")
+	expectPixelColor(t, mw, 45, 65, "red")
+	expectPixelColor(t, mw, 65, 65, "black")
+
+	if err := dw.SetFillPatternURL("#nosuchpattern"); err == nil {
+		t.Fatal("Expected an error setting an undefined fill pattern")
+	}
+}
+
+func TestDrawTextSettings(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+
+	dw.SetTextKerning(1.5)
+	dw.SetTextInterlineSpacing(12)
+	dw.SetTextInterwordSpacing(7)
+	dw.SetTextDirection(DIRECTION_RIGHT_TO_LEFT)
+	dw.SetTextAlignment(ALIGN_RIGHT)
+	dw.SetTextDecoration(DECORATION_UNDERLINE)
+
+	if v := dw.GetTextKerning(); v != 1.5 {
+		t.Errorf("Expected kerning 1.5, got %v", v)
+	}
+	if v := dw.GetTextInterlineSpacing(); v != 12 {
+		t.Errorf("Expected interline spacing 12, got %v", v)
+	}
+	if v := dw.GetTextInterwordSpacing(); v != 7 {
+		t.Errorf("Expected interword spacing 7, got %v", v)
+	}
+	if v := dw.GetTextDirection(); v != DIRECTION_RIGHT_TO_LEFT {
+		t.Errorf("Expected direction %d, got %d", DIRECTION_RIGHT_TO_LEFT, v)
+	}
+	if v := dw.GetTextAlignment(); v != ALIGN_RIGHT {
+		t.Errorf("Expected alignment %d, got %d", ALIGN_RIGHT, v)
+	}
+	if v := dw.GetTextDecoration(); v != DECORATION_UNDERLINE {
+		t.Errorf("Expected decoration %d, got %d", DECORATION_UNDERLINE, v)
+	}
+}
+
+func TestDrawTextInterlineSpacing(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// Returns the height of two annotated lines, measured by trimming
+	render := func(spacing float64) uint {
+		mw := NewMagickWand()
+		defer mw.Destroy()
+		dw := NewDrawingWand()
+		defer dw.Destroy()
+
+		if err := mw.ReadImage("xc:white[200x200]"); err != nil {
+			t.Fatal(err)
+		}
+		dw.SetFontSize(24)
+		dw.SetTextInterlineSpacing(spacing)
+		if err := mw.AnnotateImage(dw, 10, 40, 0, "Ab\nCd"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.TrimImage(0); err != nil {
+			t.Fatal(err)
+		}
+		return mw.GetImageHeight()
+	}
+
+	tight, loose := render(0), render(20)
+	if loose <= tight {
+		t.Fatalf("Expected interline spacing 20 to render taller text than 0, got %d and %d", loose, tight)
+	}
+}
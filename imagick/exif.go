@@ -0,0 +1,250 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rational is an EXIF fraction such as an exposure time of 1/125
+type Rational struct {
+	Numerator   int64
+	Denominator int64
+}
+
+// Returns the value of the fraction, 0 if the denominator is 0
+func (r Rational) Float64() float64 {
+	if r.Denominator == 0 {
+		return 0
+	}
+	return float64(r.Numerator) / float64(r.Denominator)
+}
+
+func (r Rational) String() string {
+	return fmt.Sprintf("%d/%d", r.Numerator, r.Denominator)
+}
+
+// EXIFData holds the commonly used EXIF fields of an image, see ReadEXIF.
+// Fields missing from the image, or which cannot be parsed, are left at
+// their zero value.
+type EXIFData struct {
+	// The local time the picture was taken. The location is the fixed
+	// offset of exif:OffsetTimeOriginal if present, UTC otherwise.
+	DateTimeOriginal time.Time
+	Make             string
+	Model            string
+	Orientation      OrientationType
+	// Exposure time in seconds
+	ExposureTime Rational
+	FNumber      float64
+	ISO          int
+	// Focal length in millimeters
+	FocalLength float64
+	// Whether the image has GPS coordinates. Latitude and Longitude are in
+	// degrees, negative to the south and west.
+	HasGPS    bool
+	Latitude  float64
+	Longitude float64
+}
+
+// Returns the EXIF fields of the current image parsed from its "exif:*"
+// properties. Returns an error wrapping ErrNotFound if the image has no EXIF
+// data.
+func (mw *MagickWand) ReadEXIF() (*EXIFData, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	names := mw.GetImageProperties("exif:*")
+	if len(names) == 0 {
+		return nil, fmt.Errorf("ReadEXIF: %w", ErrNotFound)
+	}
+	props := make(map[string]string, len(names))
+	for _, name := range names {
+		if v, err := mw.GetImageProperty(name); err == nil {
+			props[strings.TrimPrefix(name, "exif:")] = strings.TrimRight(v, "\x00 ")
+		}
+	}
+
+	data := &EXIFData{
+		Make:  props["Make"],
+		Model: props["Model"],
+	}
+	if t, ok := parseEXIFTime(props["DateTimeOriginal"], props["OffsetTimeOriginal"]); ok {
+		data.DateTimeOriginal = t
+	}
+	if o, err := strconv.Atoi(props["Orientation"]); err == nil && o >= 1 && o <= 8 {
+		data.Orientation = OrientationType(o)
+	}
+	if r, ok := parseEXIFRational(props["ExposureTime"]); ok {
+		data.ExposureTime = r
+	}
+	if r, ok := parseEXIFRational(props["FNumber"]); ok {
+		data.FNumber = r.Float64()
+	}
+	if r, ok := parseEXIFRational(props["FocalLength"]); ok {
+		data.FocalLength = r.Float64()
+	}
+	// ImageMagick 6 names tag 0x8827 ISOSpeedRatings, EXIF 2.3 renamed it
+	for _, name := range []string{"ISOSpeedRatings", "PhotographicSensitivity"} {
+		// Multiple values are separated by commas, the first one counts
+		field := strings.TrimSpace(strings.SplitN(props[name], ",", 2)[0])
+		if iso, err := strconv.Atoi(field); err == nil {
+			data.ISO = iso
+			break
+		}
+	}
+
+	lat, latOK := parseGPSCoordinate(props["GPSLatitude"], props["GPSLatitudeRef"])
+	lon, lonOK := parseGPSCoordinate(props["GPSLongitude"], props["GPSLongitudeRef"])
+	if latOK && lonOK {
+		data.HasGPS, data.Latitude, data.Longitude = true, lat, lon
+	}
+	return data, nil
+}
+
+// Parses an EXIF date such as "2019:06:01 12:34:56", with an optional
+// offset such as "+02:00". Blank dates, which are "0000:00:00 00:00:00" or
+// spaces, are not ok.
+func parseEXIFTime(value, offset string) (time.Time, bool) {
+	value = strings.Trim(value, "\x00 ")
+	if value == "" || strings.HasPrefix(value, "0000") {
+		return time.Time{}, false
+	}
+	// Some cameras use dashes or a T in the date
+	value = strings.Replace(value, "T", " ", 1)
+	if len(value) >= 10 {
+		value = strings.Replace(value[:10], "-", ":", 2) + value[10:]
+	}
+	t, err := time.Parse("2006:01:02 15:04:05", value)
+	if err != nil {
+		if t, err = time.Parse("2006:01:02 15:04", value); err != nil {
+			return time.Time{}, false
+		}
+	}
+	if zone, err := time.Parse("-07:00", strings.TrimSpace(offset)); err == nil {
+		_, seconds := zone.Zone()
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0,
+			time.FixedZone("", seconds))
+	}
+	return t, true
+}
+
+// Parses an EXIF rational as formatted by ImageMagick, "1/125", or a plain
+// number such as "2.8". A zero denominator is not ok.
+func parseEXIFRational(value string) (Rational, bool) {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		num, err1 := strconv.ParseInt(strings.TrimSpace(value[:i]), 10, 64)
+		den, err2 := strconv.ParseInt(strings.TrimSpace(value[i+1:]), 10, 64)
+		if err1 != nil || err2 != nil || den == 0 {
+			return Rational{}, false
+		}
+		return Rational{num, den}, true
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return Rational{}, false
+	}
+	// Plain numbers are kept to 6 decimals
+	const scale = 1000000
+	return Rational{int64(f*scale + 0.5), scale}, true
+}
+
+// Parses an EXIF GPS coordinate, three rationals of degrees, minutes and
+// seconds such as "52/1, 22/1, 3456/100", into degrees. ref is "N", "S", "E"
+// or "W", south and west are negative.
+func parseGPSCoordinate(value, ref string) (float64, bool) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, false
+	}
+	var degrees float64
+	for i, field := range fields {
+		r, ok := parseEXIFRational(field)
+		if !ok {
+			return 0, false
+		}
+		degrees += r.Float64() / float64([]int{1, 60, 3600}[i])
+	}
+	switch strings.ToUpper(strings.TrimSpace(ref)) {
+	case "S", "W":
+		degrees = -degrees
+	}
+	return degrees, true
+}
+
+// Removes the profiles and properties of the current image except those
+// named in keep, e.g. to remove GPS coordinates while keeping the color
+// profile. Profiles are named as in GetImageProfiles, e.g. "icc" or "exif",
+// properties as in GetImageProperties, e.g. "exif:Orientation". Names may
+// be patterns such as "exif:*", matching ignores case. Unlike StripImage,
+// which removes everything, the listed metadata survives.
+//
+// Other EXIF properties are only written as part of the "exif" profile. If
+// "exif:Orientation" is kept but the "exif" profile is not, the profile is
+// replaced by a minimal one holding only the orientation.
+func (mw *MagickWand) StripMetadata(keep ...string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	kept := func(name string) bool {
+		name = strings.ToLower(name)
+		for _, pattern := range keep {
+			if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Read before the exif profile is removed, as the exif:* properties are
+	// parsed from it
+	properties := mw.GetImageProperties("*")
+	var orientation OrientationType
+	if v, err := mw.GetImageProperty("exif:Orientation"); err == nil {
+		if o, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && o >= 1 && o <= 8 {
+			orientation = OrientationType(o)
+		}
+	}
+
+	for _, name := range mw.GetImageProfiles("*") {
+		if !kept(name) {
+			mw.RemoveImageProfile(name)
+		}
+	}
+	for _, name := range properties {
+		if kept(name) {
+			continue
+		}
+		if err := mw.DeleteImageProperty(name); err != nil {
+			return err
+		}
+	}
+
+	if orientation != ORIENTATION_UNDEFINED && kept("exif:Orientation") && !kept("exif") {
+		if err := mw.SetImageProfile("exif", orientationEXIFProfile(orientation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a minimal little-endian EXIF profile holding only an Orientation
+// tag
+func orientationEXIFProfile(orientation OrientationType) []byte {
+	return []byte{
+		'E', 'x', 'i', 'f', 0, 0,
+		'I', 'I', 0x2a, 0, 8, 0, 0, 0, // TIFF header, IFD0 at offset 8
+		1, 0, // one entry
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0, // Orientation, SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+}
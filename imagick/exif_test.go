@@ -0,0 +1,199 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// exifEntry is a little-endian TIFF directory entry
+type exifEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    []byte
+}
+
+func exifASCII(tag uint16, s string) exifEntry {
+	return exifEntry{tag, 2, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+func exifShort(tag uint16, v uint16) exifEntry {
+	return exifEntry{tag, 3, 1, []byte{byte(v), byte(v >> 8), 0, 0}}
+}
+
+func exifLong(tag uint16, v uint32) exifEntry {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return exifEntry{tag, 4, 1, b}
+}
+
+func exifRationals(tag uint16, values ...uint32) exifEntry {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return exifEntry{tag, 5, uint32(len(values) / 2), b}
+}
+
+// Returns a directory at offset, values longer than 4 bytes follow it
+func exifIFD(offset uint32, entries ...exifEntry) []byte {
+	var dir, data bytes.Buffer
+	dataOffset := offset + 2 + 12*uint32(len(entries)) + 4
+	binary.Write(&dir, binary.LittleEndian, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&dir, binary.LittleEndian, e.tag)
+		binary.Write(&dir, binary.LittleEndian, e.typ)
+		binary.Write(&dir, binary.LittleEndian, e.count)
+		if len(e.value) <= 4 {
+			dir.Write(append(e.value, make([]byte, 4-len(e.value))...))
+			continue
+		}
+		binary.Write(&dir, binary.LittleEndian, dataOffset+uint32(data.Len()))
+		data.Write(e.value)
+		if data.Len()%2 == 1 {
+			data.WriteByte(0)
+		}
+	}
+	dir.Write([]byte{0, 0, 0, 0})
+	return append(dir.Bytes(), data.Bytes()...)
+}
+
+// Returns an EXIF profile of a photo taken in San Francisco
+func gpsEXIFProfile() []byte {
+	ifd0 := func(exifOffset, gpsOffset uint32) []byte {
+		return exifIFD(8,
+			exifASCII(0x010f, "Canon"),
+			exifASCII(0x0110, "Canon EOS 5D"),
+			exifShort(0x0112, 6),
+			exifLong(0x8769, exifOffset),
+			exifLong(0x8825, gpsOffset))
+	}
+	exifOffset := 8 + uint32(len(ifd0(0, 0)))
+	exif := exifIFD(exifOffset,
+		exifRationals(0x829a, 1, 125),
+		exifRationals(0x829d, 28, 10),
+		exifShort(0x8827, 200),
+		exifASCII(0x9003, "2019:06:01 12:34:56"),
+		exifRationals(0x920a, 50, 1))
+	gpsOffset := exifOffset + uint32(len(exif))
+	// 37°46'29.64"N 122°25'9.84"W
+	gps := exifIFD(gpsOffset,
+		exifASCII(0x0001, "N"),
+		exifRationals(0x0002, 37, 1, 46, 1, 2964, 100),
+		exifASCII(0x0003, "W"),
+		exifRationals(0x0004, 122, 1, 25, 1, 984, 100))
+
+	profile := []byte{'E', 'x', 'i', 'f', 0, 0, 'I', 'I', 0x2a, 0, 8, 0, 0, 0}
+	profile = append(profile, ifd0(exifOffset, gpsOffset)...)
+	profile = append(profile, exif...)
+	return append(profile, gps...)
+}
+
+func TestReadEXIFAndStripMetadata(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.ReadEXIF(); err == nil {
+		t.Fatal("Expected an error reading EXIF from an image without EXIF")
+	}
+	if err := mw.SetImageProfile("exif", gpsEXIFProfile()); err != nil {
+		t.Fatal(err)
+	}
+	srgb := iccProfile("RGB ")
+	if err := mw.SetImageProfile("icc", srgb); err != nil {
+		t.Fatal(err)
+	}
+	jpeg := encodeDecode(t, mw, "JPEG")
+	defer jpeg.Destroy()
+
+	exif, err := jpeg.ReadEXIF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exif.Make != "Canon" || exif.Model != "Canon EOS 5D" || exif.Orientation != ORIENTATION_RIGHT_TOP {
+		t.Errorf("Unexpected make, model or orientation: %+v", exif)
+	}
+	if want := time.Date(2019, 6, 1, 12, 34, 56, 0, time.UTC); !exif.DateTimeOriginal.Equal(want) {
+		t.Errorf("DateTimeOriginal is %v, want %v", exif.DateTimeOriginal, want)
+	}
+	if exif.ExposureTime != (Rational{1, 125}) || exif.FNumber != 2.8 || exif.ISO != 200 || exif.FocalLength != 50 {
+		t.Errorf("Unexpected exposure settings: %+v", exif)
+	}
+	if !exif.HasGPS || math.Abs(exif.Latitude-37.7749) > 1e-4 || math.Abs(exif.Longitude+122.4194) > 1e-4 {
+		t.Errorf("Expected 37.7749, -122.4194, got %v, %v (%v)", exif.Latitude, exif.Longitude, exif.HasGPS)
+	}
+
+	if err := jpeg.StripMetadata("icc", "exif:Orientation"); err != nil {
+		t.Fatal(err)
+	}
+	stripped := encodeDecode(t, jpeg, "JPEG")
+	defer stripped.Destroy()
+	if gps := stripped.GetImageProperties("exif:GPS*"); len(gps) != 0 {
+		t.Errorf("GPS properties survived StripMetadata: %v", gps)
+	}
+	if v, err := stripped.GetImageProperty("exif:Make"); err == nil {
+		t.Errorf("exif:Make survived StripMetadata: %q", v)
+	}
+	if v, err := stripped.GetImageProperty("exif:Orientation"); err != nil || v != "6" {
+		t.Errorf("Expected exif:Orientation 6 after StripMetadata, got %q (%v)", v, err)
+	}
+	if !bytes.Equal(stripped.GetImageProfile("icc"), srgb) {
+		t.Error("ICC profile did not survive StripMetadata")
+	}
+}
+
+func TestParseEXIFValues(t *testing.T) {
+	rationals := []struct {
+		value string
+		want  Rational
+		ok    bool
+	}{
+		{"1/125", Rational{1, 125}, true},
+		{" 28 / 10 ", Rational{28, 10}, true},
+		{"2.8", Rational{2800000, 1000000}, true},
+		{"1/0", Rational{}, false},
+		{"", Rational{}, false},
+	}
+	for _, r := range rationals {
+		if got, ok := parseEXIFRational(r.value); got != r.want || ok != r.ok {
+			t.Errorf("parseEXIFRational(%q) = %v, %v, want %v, %v", r.value, got, ok, r.want, r.ok)
+		}
+	}
+
+	coordinates := []struct {
+		value, ref string
+		want       float64
+	}{
+		{"52/1, 22/1, 3456/100", "N", 52.3763},
+		{"52/1,22/1,3456/100", "S", -52.3763},
+		{"122/1 25/1 984/100", "W", -122.4194},
+		{"52376/1000", "E", 52.376},
+	}
+	for _, c := range coordinates {
+		if got, ok := parseGPSCoordinate(c.value, c.ref); !ok || math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("parseGPSCoordinate(%q, %q) = %v, %v, want %v", c.value, c.ref, got, ok, c.want)
+		}
+	}
+
+	if _, ok := parseEXIFTime("0000:00:00 00:00:00", ""); ok {
+		t.Error("Expected a blank EXIF date to be rejected")
+	}
+	got, ok := parseEXIFTime("2019-06-01 12:34:56\x00", "+02:00")
+	if want := time.Date(2019, 6, 1, 10, 34, 56, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("parseEXIFTime = %v, %v, want %v", got, ok, want)
+	}
+}
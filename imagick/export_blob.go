@@ -0,0 +1,93 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+)
+
+// Returns the current image encoded in format, e.g. "PNG", without changing
+// the format of the image, so that one wand can be encoded to several
+// formats in turn. Unlike GetImageBlob, an image which cannot be encoded
+// returns an error.
+func (mw *MagickWand) ExportBlob(format string) ([]byte, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if _, err := encoderInfo("ExportBlob", format); err != nil {
+		return nil, err
+	}
+	previous := mw.GetImageFormat()
+	if err := mw.SetImageFormat(format); err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	blob, err := mw.imageBlob(false)
+	if rerr := mw.SetImageFormat(previous); err == nil && rerr != nil {
+		err = rerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	return blob, nil
+}
+
+// Returns all images of the wand encoded in format as one blob, e.g. an
+// animated "GIF", see ExportBlob. Returns an error if the wand holds several
+// images and format cannot hold a sequence, rather than encoding only the
+// first image as GetImagesBlob does.
+func (mw *MagickWand) ExportBlobAll(format string) ([]byte, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	info, err := encoderInfo("ExportBlobAll", format)
+	if err != nil {
+		return nil, err
+	}
+	n := int(mw.GetNumberImages())
+	if n > 1 && !info.Multiframe {
+		return nil, fmt.Errorf("ExportBlobAll: format %s cannot hold %d images", info.Name, n)
+	}
+
+	// The format is set on every image and restored afterwards, along with
+	// the iterator
+	index := int(mw.GetIteratorIndex())
+	previous := make([]string, n)
+	for i := range previous {
+		mw.SetIteratorIndex(i)
+		previous[i] = mw.GetImageFormat()
+	}
+	defer func() {
+		for i, f := range previous {
+			mw.SetIteratorIndex(i)
+			mw.SetImageFormat(f)
+		}
+		mw.SetIteratorIndex(index)
+	}()
+	for i := range previous {
+		mw.SetIteratorIndex(i)
+		if err := mw.SetImageFormat(format); err != nil {
+			return nil, fmt.Errorf("ExportBlobAll: %w", err)
+		}
+	}
+	mw.SetIteratorIndex(index)
+
+	blob, err := mw.imageBlob(true)
+	if err != nil {
+		return nil, fmt.Errorf("ExportBlobAll: %w", err)
+	}
+	return blob, nil
+}
+
+// Returns the information on format, or an error if it cannot be written
+func encoderInfo(op, format string) (*MagickFormatInfo, error) {
+	info, err := FormatInfo(format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !info.Encoder {
+		return nil, fmt.Errorf("%s: format %s cannot be written", op, info.Name)
+	}
+	return info, nil
+}
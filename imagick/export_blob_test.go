@@ -0,0 +1,102 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestExportBlob(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+
+	png, err := mw.ExportBlob("PNG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gif, err := mw.ExportBlob("GIF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("Expected a PNG blob, got % x", png[:8])
+	}
+	if !bytes.HasPrefix(gif, []byte("GIF8")) {
+		t.Errorf("Expected a GIF blob, got % x", gif[:8])
+	}
+	if f := mw.GetImageFormat(); f != "JPEG" {
+		t.Errorf("Expected the format to stay JPEG, got %s", f)
+	}
+
+	if _, err := mw.ExportBlob("NOSUCHFORMAT"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown format, got %v", err)
+	}
+	empty := NewMagickWand()
+	defer empty.Destroy()
+	if _, err := empty.ExportBlob("PNG"); err == nil {
+		t.Error("Expected an error encoding an empty wand")
+	}
+}
+
+func TestExportBlobAll(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for _, frame := range []string{"xc:red[16x16]", "xc:lime[16x16]", "xc:blue[16x16]"} {
+		if err := mw.ReadImage(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	formats := make([]string, 3)
+	for i := range formats {
+		mw.SetIteratorIndex(i)
+		formats[i] = mw.GetImageFormat()
+	}
+	mw.SetIteratorIndex(1)
+
+	if _, err := mw.ExportBlobAll("JPEG"); err == nil {
+		t.Error("Expected an error encoding 3 images as JPEG")
+	}
+	gif, err := mw.ExportBlobAll("GIF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := NewMagickWand()
+	defer decoded.Destroy()
+	if err := decoded.ReadImageBlob(gif); err != nil {
+		t.Fatal(err)
+	}
+	if n := decoded.GetNumberImages(); n != 3 {
+		t.Errorf("Expected 3 frames, got %d", n)
+	}
+	if i := mw.GetIteratorIndex(); i != 1 {
+		t.Errorf("Expected the iterator to stay at 1, got %d", i)
+	}
+	for i := 0; i < 3; i++ {
+		mw.SetIteratorIndex(i)
+		if f := mw.GetImageFormat(); f != formats[i] {
+			t.Errorf("Expected frame %d to keep format %s, got %s", i, formats[i], f)
+		}
+	}
+}
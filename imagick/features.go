@@ -0,0 +1,176 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <stdlib.h>
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unsafe"
+)
+
+// ErrMissingDelegate is matched by errors of operations which need a
+// delegate library ImageMagick was built without, e.g. liblqr for
+// LiquidRescaleImage, and by every *MissingDelegateError.
+var ErrMissingDelegate = errors.New("missing delegate")
+
+// MissingDelegateError is returned by the ReadImage and PingImage methods
+// when ImageMagick cannot decode the format of the image, usually because
+// it was built without the delegate library, e.g. libwebp. Servers can map
+// it to "415 Unsupported Media Type":
+//
+//	var derr *imagick.MissingDelegateError
+//	if errors.As(err, &derr) {
+//	    http.Error(w, derr.Error(), http.StatusUnsupportedMediaType)
+//	}
+type MissingDelegateError struct {
+	// The format detected from the data or named by ImageMagick, e.g.
+	// "WEBP". Empty if the data is of no format ImageMagick recognizes.
+	Format string
+	// The exception raised by ImageMagick
+	Err *MagickError
+}
+
+func (e *MissingDelegateError) Error() string {
+	if e.Format == "" {
+		return "unrecognized image format: " + e.Err.Error()
+	}
+	return fmt.Sprintf("no delegate to decode %s: %s", e.Format, e.Err.Error())
+}
+
+// Returns the exception raised by ImageMagick, so that errors.As still finds
+// the *MagickError
+func (e *MissingDelegateError) Unwrap() error {
+	return e.Err
+}
+
+// Makes errors.Is(err, ErrMissingDelegate) true
+func (e *MissingDelegateError) Is(target error) bool {
+	return target == ErrMissingDelegate
+}
+
+// Returns the value of a configure option of the linked ImageMagick, e.g.
+// "DELEGATES". Tests replace it to fake a build.
+var queryConfigureOption = func(name string) string {
+	csname := C.CString(name)
+	defer C.free(unsafe.Pointer(csname))
+	csvalue := C.MagickQueryConfigureOption(csname)
+	if csvalue == nil {
+		return ""
+	}
+	defer relinquishMemory(unsafe.Pointer(csvalue))
+	return C.GoString(csvalue)
+}
+
+// Returns true if name is one of the space separated words of option,
+// ignoring case.
+func configureOptionHas(option, name string) bool {
+	for _, f := range strings.Fields(queryConfigureOption(option)) {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the linked ImageMagick was built with a feature or
+// delegate library, as listed by "convert -version", e.g. "HDRI", "OpenMP",
+// "lqr" or "png". The comparison is case insensitive.
+func HasFeature(feature string) bool {
+	return configureOptionHas("FEATURES", feature) || configureOptionHas("DELEGATES", feature)
+}
+
+// Returns true if the linked ImageMagick was built with a delegate library,
+// as listed under "Delegates" by "convert -version", e.g. "webp", "heic",
+// "raw" or "jpeg". The comparison is case insensitive.
+func HasDelegate(name string) bool {
+	return configureOptionHas("DELEGATES", name)
+}
+
+// Returns an error wrapping ErrMissingDelegate if the linked ImageMagick was
+// built without delegate, which op needs.
+func requireDelegate(op, delegate string) error {
+	if !HasFeature(delegate) {
+		return fmt.Errorf("%s: ImageMagick was built without %s: %w", op, delegate, ErrMissingDelegate)
+	}
+	return nil
+}
+
+// Rescales the image to columns x rows with seam carving if ImageMagick was
+// built with liblqr, and falls back to ResizeImage otherwise. Use it where
+// a plain resize is an acceptable result on hosts without liblqr.
+func (mw *MagickWand) LiquidRescaleOrResize(columns, rows uint) error {
+	err := mw.LiquidRescaleImage(columns, rows, 1, 0)
+	if errors.Is(err, ErrMissingDelegate) {
+		return mw.ResizeImage(columns, rows, FILTER_LANCZOS, 1)
+	}
+	return err
+}
+
+// Matches the format ImageMagick quotes in a "no decode delegate for this
+// image format `WEBP'" exception
+var delegateFormatRe = regexp.MustCompile("`([^']*)'")
+
+// Returns err as a *MissingDelegateError if it is an exception ImageMagick
+// raises for data it cannot decode, and unchanged otherwise. format is the
+// format detected from the data, if known.
+func classifyReadError(err error, format string) error {
+	var merr *MagickError
+	if !errors.As(err, &merr) || merr.IsWarning() {
+		return err
+	}
+	description := strings.ToLower(merr.Description)
+	switch {
+	case merr.Severity == ERROR_MISSING_DELEGATE,
+		merr.Severity == FATAL_ERROR_MISSING_DELEGATE,
+		strings.Contains(description, "no decode delegate"),
+		strings.Contains(description, "delegate library support not built-in"):
+	default:
+		return err
+	}
+	if format == "" {
+		if m := delegateFormatRe.FindStringSubmatch(merr.Description); m != nil {
+			format = strings.ToUpper(m[1])
+		}
+	}
+	return &MissingDelegateError{Format: format, Err: merr}
+}
+
+// Returns the format ImageMagick detects from the magic bytes of blob, as
+// PingImageBlob does, or "" if it recognizes none. The detection does not
+// need the delegate library of the format.
+func sniffBlobFormat(blob []byte) string {
+	// The magic bytes of every format are within the first few kilobytes
+	const sniffLength = 8192
+	if len(blob) > sniffLength {
+		blob = blob[:sniffLength]
+	}
+	if len(blob) == 0 {
+		return ""
+	}
+	cblob := C.CBytes(blob)
+	defer C.free(cblob)
+
+	info := C.AcquireImageInfo()
+	defer func() {
+		// The blob is not owned by the image info
+		C.SetImageInfoBlob(info, nil, 0)
+		C.DestroyImageInfo(info)
+	}()
+	C.SetImageInfoBlob(info, cblob, C.size_t(len(blob)))
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+	if C.SetImageInfo(info, 0, exc) == C.MagickFalse {
+		return ""
+	}
+	return C.GoString(&info.magick[0])
+}
@@ -0,0 +1,161 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Replaces the FEATURES and DELEGATES configure options of the linked
+// ImageMagick until the returned function is called.
+func fakeConfigure(features, delegates string) (restore func()) {
+	saved := queryConfigureOption
+	queryConfigureOption = func(name string) string {
+		switch name {
+		case "FEATURES":
+			return features
+		case "DELEGATES":
+			return delegates
+		}
+		return saved(name)
+	}
+	return func() { queryConfigureOption = saved }
+}
+
+func TestHasFeature(t *testing.T) {
+	defer fakeConfigure("DPC HDRI OpenMP", "bzlib fontconfig lqr png zlib")()
+
+	for feature, want := range map[string]bool{
+		"lqr":    true,
+		"LQR":    true,
+		"openmp": true,
+		"HDRI":   true,
+		"jpeg":   false,
+		"lq":     false,
+	} {
+		if got := HasFeature(feature); got != want {
+			t.Errorf("HasFeature(%q): expected %v, got %v", feature, want, got)
+		}
+	}
+
+	if !HasDelegate("PNG") || HasDelegate("webp") || HasDelegate("HDRI") {
+		t.Error("Expected HasDelegate to look at the delegates only")
+	}
+}
+
+func TestLiquidRescaleImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := mw.LiquidRescaleImage(50, 46, 1, 0)
+	if HasFeature("lqr") {
+		if err != nil {
+			t.Fatal(err)
+		}
+	} else if !errors.Is(err, ErrMissingDelegate) {
+		t.Fatalf("Expected ErrMissingDelegate without liblqr, got %v", err)
+	}
+	if err := mw.LiquidRescaleOrResize(40, 40); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 40 || h != 40 {
+		t.Errorf("Expected 40x40, got %dx%d", w, h)
+	}
+
+	// Without liblqr the fallback resizes
+	defer fakeConfigure("HDRI", "png")()
+	err = mw.LiquidRescaleImage(30, 30, 1, 0)
+	if !errors.Is(err, ErrMissingDelegate) {
+		t.Fatalf("Expected ErrMissingDelegate, got %v", err)
+	}
+	if err := mw.LiquidRescaleOrResize(30, 20); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 30 || h != 20 {
+		t.Errorf("Expected the fallback to resize to 30x20, got %dx%d", w, h)
+	}
+}
+
+func TestClassifyReadError(t *testing.T) {
+	delegate := &MagickError{ERROR_MISSING_DELEGATE, "no decode delegate for this image format `WEBP' @ error/constitute.c/ReadImage/501"}
+	unknown := &MagickError{ERROR_MISSING_DELEGATE, "no decode delegate for this image format `' @ error/blob.c/BlobToImage/364"}
+	builtIn := &MagickError{ERROR_MISSING_DELEGATE, "delegate library support not built-in (LQR) @ warning/resize.c/LiquidRescaleImage/2040"}
+	corrupt := &MagickError{ERROR_CORRUPT_IMAGE, "improper image header `x.bmp' @ error/bmp.c/ReadBMPImage/612"}
+	warning := &MagickError{WARNING_CORRUPT_IMAGE, "Premature end of JPEG file `x.jpg' @ warning/jpeg.c/JPEGWarningHandler/352"}
+
+	for _, tc := range []struct {
+		sniffed string
+		in      *MagickError
+		format  string
+		missing bool
+	}{
+		{in: delegate, format: "WEBP", missing: true},
+		{in: delegate, sniffed: "HEIC", format: "HEIC", missing: true},
+		{in: unknown, format: "", missing: true},
+		{in: builtIn, format: "LQR", missing: true},
+		{in: corrupt},
+		{in: warning},
+	} {
+		err := classifyReadError(tc.in, tc.sniffed)
+		var derr *MissingDelegateError
+		if errors.As(err, &derr) != tc.missing {
+			t.Errorf("%q: expected a missing delegate %v, got %v", tc.in.Description, tc.missing, err)
+			continue
+		}
+		if !tc.missing {
+			if err != tc.in {
+				t.Errorf("%q: expected the error unchanged, got %v", tc.in.Description, err)
+			}
+			continue
+		}
+		if derr.Format != tc.format {
+			t.Errorf("%q: expected format %q, got %q", tc.in.Description, tc.format, derr.Format)
+		}
+		var merr *MagickError
+		if !errors.Is(err, ErrMissingDelegate) || !errors.As(err, &merr) {
+			t.Errorf("%q: expected errors.Is and errors.As to see through %v", tc.in.Description, err)
+		}
+	}
+
+	if err := classifyReadError(nil, ""); err != nil {
+		t.Errorf("Expected nil for no error, got %v", err)
+	}
+}
+
+func TestReadImageMissingDelegate(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	err := mw.ReadImage("FILE_NOT_A_FORMAT:image")
+	if err == nil {
+		t.Fatal("Expected reading an unknown format to fail")
+	}
+	var derr *MissingDelegateError
+	if strings.Contains(strings.ToLower(err.Error()), "delegate") && !errors.As(err, &derr) {
+		t.Fatalf("Expected a *MissingDelegateError, got %T %v", err, err)
+	}
+
+	// The format of a blob is detected from its magic bytes
+	if format := sniffBlobFormat([]byte("GIF89a\x01\x00\x01\x00")); format != "GIF" {
+		t.Errorf("Expected GIF to be detected, got %q", format)
+	}
+}
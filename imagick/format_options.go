@@ -0,0 +1,269 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WebPConfig holds the WebP encoder settings, see SetWebPWriteOptions()
+type WebPConfig struct {
+	Lossless     bool
+	Quality      int  // 1 to 100, 0 keeps the image quality
+	AlphaQuality int  // 1 to 100, 0 keeps the encoder default of 100
+	Method       int  // 1 to 6, higher is slower but smaller, 0 keeps the default of 4
+	Exact        bool // Keep the RGB values of transparent pixels
+}
+
+// Sets the options used by the next write of WebP images. Returns an error
+// if ImageMagick was built without the WebP delegate.
+func (mw *MagickWand) SetWebPWriteOptions(cfg WebPConfig) error {
+	if !CanWriteFormat("WEBP") {
+		return errors.New("SetWebPWriteOptions: ImageMagick was built without WebP support")
+	}
+
+	options := map[string]string{
+		"webp:lossless": strconv.FormatBool(cfg.Lossless),
+		"webp:exact":    strconv.FormatBool(cfg.Exact),
+	}
+	if cfg.AlphaQuality > 0 {
+		options["webp:alpha-quality"] = strconv.Itoa(cfg.AlphaQuality)
+	}
+	if cfg.Method > 0 {
+		options["webp:method"] = strconv.Itoa(cfg.Method)
+	}
+	if err := mw.setOptions(options); err != nil {
+		return err
+	}
+
+	if cfg.Quality > 0 {
+		return mw.SetImageCompressionQuality(uint(cfg.Quality))
+	}
+	return nil
+}
+
+// JPEG chroma subsampling notations and the equivalent sampling factors
+var jpegSamplingFactors = map[string]string{
+	"4:4:4": "1x1,1x1,1x1",
+	"4:2:2": "2x1,1x1,1x1",
+	"4:2:0": "2x2,1x1,1x1",
+	"4:1:1": "4x1,1x1,1x1",
+}
+
+// Sets the options used by the next write of JPEG images. A progressive JPEG
+// is written with the JPEG interlace scheme, optimizeCoding computes optimal
+// Huffman tables. samplingFactor is either a chroma subsampling notation
+// such as "4:2:0" or factors such as "2x2,1x1,1x1", an empty string keeps
+// the encoder default.
+func (mw *MagickWand) SetJPEGWriteOptions(quality uint, progressive bool, optimizeCoding bool, samplingFactor string) error {
+	if err := mw.SetImageCompressionQuality(quality); err != nil {
+		return err
+	}
+
+	interlace := INTERLACE_NO
+	if progressive {
+		interlace = INTERLACE_JPEG
+	}
+	if err := mw.SetImageInterlaceScheme(interlace); err != nil {
+		return err
+	}
+	if err := mw.SetInterlaceScheme(interlace); err != nil {
+		return err
+	}
+
+	options := map[string]string{
+		"jpeg:optimize-coding": strconv.FormatBool(optimizeCoding),
+	}
+	if samplingFactor != "" {
+		if factors, ok := jpegSamplingFactors[samplingFactor]; ok {
+			samplingFactor = factors
+		}
+		options["jpeg:sampling-factor"] = samplingFactor
+	}
+	return mw.setOptions(options)
+}
+
+// PNGWriteOptions holds the PNG encoder settings, see SetPNGWriteOptions()
+type PNGWriteOptions struct {
+	CompressionLevel int      // zlib level, 0 (none) to 9 (best)
+	FilterType       int      // 0 to 4 for None, Sub, Up, Average, Paeth, 5 for adaptive
+	ExcludeChunks    []string // Chunks to leave out, e.g. "date" and "tIME", or "all"
+	BitDepth         uint     // 8 or 16, 0 keeps the image depth
+	ColorTypeForce   string   // A PNG color type such as "2" (RGB) or "6" (RGBA)
+}
+
+// Sets the options used by the next write of PNG images. CompressionLevel
+// and FilterType are always applied, so the zero value writes uncompressed
+// images.
+func (mw *MagickWand) SetPNGWriteOptions(opts PNGWriteOptions) error {
+	options := map[string]string{
+		"png:compression-level":  strconv.Itoa(opts.CompressionLevel),
+		"png:compression-filter": strconv.Itoa(opts.FilterType),
+	}
+	if len(opts.ExcludeChunks) > 0 {
+		options["png:exclude-chunk"] = strings.Join(opts.ExcludeChunks, ",")
+	}
+	if opts.BitDepth > 0 {
+		options["png:bit-depth"] = strconv.Itoa(int(opts.BitDepth))
+	}
+	if opts.ColorTypeForce != "" {
+		options["png:color-type"] = opts.ColorTypeForce
+	}
+	if err := mw.setOptions(options); err != nil {
+		return err
+	}
+
+	if opts.BitDepth > 0 {
+		return mw.SetImageDepth(opts.BitDepth)
+	}
+	return nil
+}
+
+// TIFFWriteOptions holds the TIFF encoder settings, see WriteTIFF()
+type TIFFWriteOptions struct {
+	// Compression of every page, COMPRESSION_UNDEFINED keeps the
+	// compression set on each page
+	Compression CompressionType
+	// Compression of the first pages, overriding Compression, e.g.
+	// COMPRESSION_GROUP4 for bitonal and COMPRESSION_LZW for color pages
+	PageCompression []CompressionType
+	RowsPerStrip    uint   // 0 keeps the encoder default
+	Predictor       int    // 2 for horizontal differencing with LZW or ZIP, 0 keeps the default
+	FillOrder       string // "msb" or "lsb", empty keeps the default
+	Adjoin          bool   // Write all pages to one file
+}
+
+// Writes the images of the wand as a TIFF file, setting the compression of
+// each page before the write.
+func (mw *MagickWand) WriteTIFF(filename string, opts TIFFWriteOptions) error {
+	options := map[string]string{}
+	if opts.RowsPerStrip > 0 {
+		options["tiff:rows-per-strip"] = strconv.Itoa(int(opts.RowsPerStrip))
+	}
+	if opts.Predictor > 0 {
+		options["tiff:predictor"] = strconv.Itoa(opts.Predictor)
+	}
+	if opts.FillOrder != "" {
+		options["tiff:fill-order"] = opts.FillOrder
+	}
+	if err := mw.setOptions(options); err != nil {
+		return err
+	}
+
+	mw.ResetIterator()
+	for i := 0; mw.NextImage(); i++ {
+		if err := mw.SetImageFormat("TIFF"); err != nil {
+			return err
+		}
+		compression := opts.Compression
+		if i < len(opts.PageCompression) {
+			compression = opts.PageCompression[i]
+		}
+		if compression != COMPRESSION_UNDEFINED {
+			if err := mw.SetImageCompression(compression); err != nil {
+				return err
+			}
+		}
+	}
+	mw.ResetIterator()
+
+	return mw.WriteImages(filename, opts.Adjoin)
+}
+
+// Reads the given pages of a multi-page document such as a PDF, rasterized
+// at density DPI. Pages are zero based, a page "2", a range "0-4" or a list
+// "0,3-5". Only the selected pages are rendered. The page selection is
+// appended to filename as "file.pdf[0-4]". ImageMagick only parses the last
+// bracket group, but filename is checked as by ReadImageSafe with the
+// default options, so that a filename which itself ends in a bracket group
+// is only read if a file of exactly that name exists.
+func (mw *MagickWand) ReadImagePages(filename string, pages string, density float64) error {
+	if !sceneListRe.MatchString(strings.Replace(pages, " ", "", -1)) {
+		return fmt.Errorf("ReadImagePages: invalid page selection %q", pages)
+	}
+	safe, err := safeFilename(filename, nil)
+	if err != nil {
+		return fmt.Errorf("ReadImagePages: %s", err)
+	}
+	if density > 0 {
+		if err := mw.SetResolution(density, density); err != nil {
+			return err
+		}
+	}
+
+	before := mw.GetNumberImages()
+	if err := mw.ReadImage(safe + "[" + pages + "]"); err != nil {
+		return err
+	}
+	if mw.GetNumberImages() == before {
+		return fmt.Errorf("ReadImagePages: no pages %s in %q", pages, filename)
+	}
+	return nil
+}
+
+// Rasterizes an SVG document to width x height pixels. The density is
+// computed from the intrinsic size of the SVG so the vector data is rendered
+// at the target size rather than scaled up from 72 DPI, and a final resize
+// makes up for the rounding of density based scaling. A zero width or height
+// is computed from the aspect ratio, both zero keeps the intrinsic size.
+// A nil background renders on a transparent background.
+func (mw *MagickWand) ReadSVG(svg []byte, width, height uint, background *PixelWand) error {
+	probe := NewMagickWand()
+	defer probe.Destroy()
+	if err := probe.SetResolution(72, 72); err != nil {
+		return err
+	}
+	if err := probe.PingImageBlob(svg); err != nil {
+		return err
+	}
+	intrinsicW, intrinsicH := probe.GetImageWidth(), probe.GetImageHeight()
+	if intrinsicW == 0 || intrinsicH == 0 {
+		return errors.New("ReadSVG: SVG has no intrinsic size")
+	}
+
+	switch {
+	case width == 0 && height == 0:
+		width, height = intrinsicW, intrinsicH
+	case width == 0:
+		width = uint(math.Max(1, math.Round(float64(intrinsicW)*float64(height)/float64(intrinsicH))))
+	case height == 0:
+		height = uint(math.Max(1, math.Round(float64(intrinsicH)*float64(width)/float64(intrinsicW))))
+	}
+	scale := math.Max(float64(width)/float64(intrinsicW), float64(height)/float64(intrinsicH))
+	if err := mw.SetResolution(72*scale, 72*scale); err != nil {
+		return err
+	}
+
+	if background == nil {
+		background = NewPixelWand()
+		defer background.Destroy()
+		background.SetColor("none")
+	}
+	if err := mw.SetBackgroundColor(background); err != nil {
+		return err
+	}
+	if err := mw.ReadImageBlob(svg); err != nil {
+		return err
+	}
+
+	if mw.GetImageWidth() != width || mw.GetImageHeight() != height {
+		return mw.ResizeImage(width, height, FILTER_LANCZOS, 1)
+	}
+	return nil
+}
+
+// Sets several wand options, see SetOption()
+func (mw *MagickWand) setOptions(options map[string]string) error {
+	for key, value := range options {
+		if err := mw.SetOption(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Encodes the image as format and returns the decoded result
+func encodeDecode(t *testing.T, mw *MagickWand, format string) *MagickWand {
+	t.Helper()
+
+	if err := mw.SetImageFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	blob := mw.GetImageBlob()
+	if len(blob) == 0 {
+		t.Fatalf("Could not encode %s: %v", format, mw.GetLastError())
+	}
+
+	decoded := NewMagickWand()
+	if err := decoded.ReadImageBlob(blob); err != nil {
+		decoded.Destroy()
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// Returns the absolute error pixel count between two images
+func pixelDifference(t *testing.T, a, b *MagickWand) float64 {
+	t.Helper()
+
+	diff, distortion, err := a.CompareImages(b, METRIC_ABSOLUTE_ERROR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff.Destroy()
+	return distortion
+}
+
+func TestWebPWriteOptions(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	if !CanWriteFormat("WEBP") {
+		t.Skip("ImageMagick was built without WebP support")
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.SetWebPWriteOptions(WebPConfig{Lossless: true, Method: 6}); err != nil {
+		t.Fatal(err)
+	}
+	lossless := encodeDecode(t, mw, "WEBP")
+	defer lossless.Destroy()
+	if d := pixelDifference(t, mw, lossless); d != 0 {
+		t.Fatalf("Expected a lossless WebP to decode identically, %v pixels differ", d)
+	}
+
+	if err := mw.SetWebPWriteOptions(WebPConfig{Quality: 50, AlphaQuality: 50}); err != nil {
+		t.Fatal(err)
+	}
+	lossy := encodeDecode(t, mw, "WEBP")
+	defer lossy.Destroy()
+	if d := pixelDifference(t, mw, lossy); d == 0 {
+		t.Fatal("Expected a lossy WebP to differ from the original")
+	}
+}
+
+func TestJPEGWriteOptions(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.SetJPEGWriteOptions(85, false, false, "4:4:4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+	baseline := mw.GetImageBlob()
+
+	if err := mw.SetJPEGWriteOptions(85, true, true, "4:2:0"); err != nil {
+		t.Fatal(err)
+	}
+	web := mw.GetImageBlob()
+
+	if len(web) >= len(baseline) {
+		t.Fatalf("Expected 4:2:0 progressive to be smaller than 4:4:4 baseline, got %d and %d bytes", len(web), len(baseline))
+	}
+
+	tests := []struct {
+		blob        []byte
+		interlace   InterlaceType
+		subsampling string
+	}{
+		{baseline, INTERLACE_NO, "1x1,1x1,1x1"},
+		{web, INTERLACE_JPEG, "2x2,1x1,1x1"},
+	}
+	for _, tt := range tests {
+		decoded := NewMagickWand()
+		if err := decoded.ReadImageBlob(tt.blob); err != nil {
+			t.Fatal(err)
+		}
+		if i := decoded.GetImageInterlaceScheme(); i != tt.interlace {
+			t.Errorf("Expected interlace scheme %d, got %d", tt.interlace, i)
+		}
+		if v, err := decoded.GetImageProperty("jpeg:sampling-factor"); err != nil || v != tt.subsampling {
+			t.Errorf("Expected sampling factor %q, got %q (%v)", tt.subsampling, v, err)
+		}
+		decoded.Destroy()
+	}
+
+	if err := mw.SetSamplingFactors([]float64{2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if f := mw.GetSamplingFactors(); len(f) != 2 || f[0] != 2 || f[1] != 2 {
+		t.Fatalf("Expected sampling factors [2 2], got %v", f)
+	}
+	if err := mw.SetSamplingFactors(nil); err != nil {
+		t.Fatal(err)
+	}
+	if f := mw.GetSamplingFactors(); len(f) != 0 {
+		t.Fatalf("Expected no sampling factors, got %v", f)
+	}
+}
+
+func TestPNGWriteOptions(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("PNG"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := PNGWriteOptions{CompressionLevel: 9, FilterType: 5, ExcludeChunks: []string{"date", "tIME"}}
+	if err := mw.SetPNGWriteOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+	first := mw.GetImageBlob()
+	time.Sleep(1100 * time.Millisecond)
+	second := mw.GetImageBlob()
+	if !bytes.Equal(first, second) {
+		t.Fatal("Expected identical PNG blobs without date and time chunks")
+	}
+
+	opts.CompressionLevel = 0
+	if err := mw.SetPNGWriteOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+	if uncompressed := mw.GetImageBlob(); len(first) >= len(uncompressed) {
+		t.Fatalf("Expected level 9 to be smaller than level 0, got %d and %d bytes", len(first), len(uncompressed))
+	}
+
+	for _, depth := range []uint{8, 16} {
+		if err := mw.SetPNGWriteOptions(PNGWriteOptions{CompressionLevel: 6, BitDepth: depth}); err != nil {
+			t.Fatal(err)
+		}
+		decoded := encodeDecode(t, mw, "PNG")
+		if d := decoded.GetImageDepth(); d != depth {
+			t.Errorf("Expected depth %d after re-reading, got %d", depth, d)
+		}
+		decoded.Destroy()
+	}
+}
+
+func TestWriteTIFF(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick_tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "fax.tif")
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	// Two bitonal pages and a color page
+	for _, page := range []string{"pattern:checkerboard[200x100]", "pattern:hs_diagcross[200x100]", "logo:"} {
+		if err := mw.ReadImage(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mw.SetIteratorIndex(0)
+	if err := mw.SetImageType(IMAGE_TYPE_BILEVEL); err != nil {
+		t.Fatal(err)
+	}
+	mw.SetIteratorIndex(1)
+	if err := mw.SetImageType(IMAGE_TYPE_BILEVEL); err != nil {
+		t.Fatal(err)
+	}
+
+	compressions := []CompressionType{COMPRESSION_GROUP4, COMPRESSION_GROUP4, COMPRESSION_LZW}
+	err = mw.WriteTIFF(filename, TIFFWriteOptions{
+		PageCompression: compressions,
+		Predictor:       2,
+		Adjoin:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := NewMagickWand()
+	defer tiff.Destroy()
+	if err := tiff.ReadImage(filename); err != nil {
+		t.Fatal(err)
+	}
+	if n := tiff.GetNumberImages(); n != 3 {
+		t.Fatalf("Expected 3 pages, got %d", n)
+	}
+	tiff.ResetIterator()
+	for i := 0; tiff.NextImage(); i++ {
+		if c := tiff.GetImageCompression(); c != compressions[i] {
+			t.Errorf("Page %d: expected compression %d, got %d", i, compressions[i], c)
+		}
+	}
+}
+
+func TestReadImagePages(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick_pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "doc[v1].pdf")
+
+	doc := NewMagickWand()
+	defer doc.Destroy()
+	for _, page := range []string{"xc:red[144x72]", "xc:lime[144x72]", "xc:blue[144x72]"} {
+		if err := doc.ReadImage(page); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.SetImageResolution(72, 72); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := doc.WriteImages("PDF:"+filename, true); err != nil {
+		t.Fatal(err)
+	}
+
+	preview := NewMagickWand()
+	defer preview.Destroy()
+	if err := preview.ReadImagePages(filename, "2", 72); err != nil {
+		t.Skipf("Could not read the PDF, Ghostscript may be missing: %s", err)
+	}
+	if n := preview.GetNumberImages(); n != 1 {
+		t.Fatalf("Expected 1 page, got %d", n)
+	}
+	expectPixelColor(t, preview, 10, 10, "blue")
+	w72 := preview.GetImageWidth()
+
+	hires := NewMagickWand()
+	defer hires.Destroy()
+	if err := hires.ReadImagePages(filename, "0-1", 144); err != nil {
+		t.Fatal(err)
+	}
+	if n := hires.GetNumberImages(); n != 2 {
+		t.Fatalf("Expected 2 pages, got %d", n)
+	}
+	if w := hires.GetImageWidth(); w < 2*w72-2 || w > 2*w72+2 {
+		t.Fatalf("Expected doubling the density to double the width of %d, got %d", w72, w)
+	}
+
+	missing := NewMagickWand()
+	defer missing.Destroy()
+	if err := missing.ReadImagePages(filename, "7", 72); err == nil {
+		t.Fatal("Expected an error reading a page beyond the end of the document")
+	}
+	if err := missing.ReadImagePages(filename, "1];x", 72); err == nil {
+		t.Fatal("Expected an error for an invalid page selection")
+	}
+	for _, name := range []string{filepath.Join(dir, "doc.pdf[1]"), "@" + filename} {
+		if err := missing.ReadImagePages(name, "0", 72); err == nil {
+			t.Errorf("Expected an error for the filename %q", name)
+		}
+	}
+
+	// A file named with a bracket suffix is read as is
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	literal := filepath.Join(dir, "scan[1]")
+	if err := ioutil.WriteFile(literal, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	scan := NewMagickWand()
+	defer scan.Destroy()
+	if err := scan.ReadImagePages(literal, "0", 72); err != nil {
+		t.Fatal(err)
+	}
+	if n := scan.GetNumberImages(); n != 1 {
+		t.Fatalf("Expected 1 page of %q, got %d", literal, n)
+	}
+	expectPixelColor(t, scan, 10, 10, "red")
+}
+
+func TestReadSVG(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="40" height="30">` +
+		`<rect x="5" y="5" width="30" height="20" fill="red"/></svg>`)
+
+	for _, size := range [][2]uint{{40, 30}, {160, 120}, {67, 0}, {0, 17}} {
+		mw := NewMagickWand()
+		if err := mw.ReadSVG(svg, size[0], size[1], nil); err != nil {
+			mw.Destroy()
+			t.Fatal(err)
+		}
+		if size[0] != 0 && mw.GetImageWidth() != size[0] {
+			t.Errorf("Expected width %d, got %d", size[0], mw.GetImageWidth())
+		}
+		if size[1] != 0 && mw.GetImageHeight() != size[1] {
+			t.Errorf("Expected height %d, got %d", size[1], mw.GetImageHeight())
+		}
+		if size[0] == 160 {
+			expectPixelColor(t, mw, 80, 60, "red")
+		}
+
+		if err := mw.SetImageFormat("PNG"); err != nil {
+			mw.Destroy()
+			t.Fatal(err)
+		}
+		png := NewMagickWand()
+		if err := png.ReadImageBlob(mw.GetImageBlob()); err != nil {
+			t.Fatal(err)
+		}
+		if !png.GetImageAlphaChannel() {
+			t.Errorf("Expected the transparent background to keep the alpha channel in PNG")
+		}
+		corner, err := png.GetImagePixelColor(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alpha := corner.GetAlpha(); alpha != 0 {
+			t.Errorf("Expected a transparent corner, got alpha %f", alpha)
+		}
+		corner.Destroy()
+		png.Destroy()
+		mw.Destroy()
+	}
+
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadSVG(svg, 80, 60, white); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, mw, 0, 0, "white")
+}
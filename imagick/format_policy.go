@@ -0,0 +1,220 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <stdlib.h>
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// ErrFormatDenied is matched by every *FormatDeniedError
+var ErrFormatDenied = errors.New("format denied")
+
+// FormatDeniedError is returned by the ReadImage and PingImage methods for
+// an image of a format disabled with DisableFormats. Nothing is decoded.
+type FormatDeniedError struct {
+	// The denied format, e.g. "MVG"
+	Format string
+}
+
+func (e *FormatDeniedError) Error() string {
+	return fmt.Sprintf("reading %s images is disabled", e.Format)
+}
+
+// Makes errors.Is(err, ErrFormatDenied) true
+func (e *FormatDeniedError) Is(target error) bool {
+	return target == ErrFormatDenied
+}
+
+var (
+	deniedFormatsMu sync.RWMutex
+	deniedFormats   = map[string]bool{}
+)
+
+// An explicit format prefix as ImageMagick parses it, e.g. "mvg:" in
+// "mvg:drawing.txt" or "radial-gradient:" in "radial-gradient:red-blue"
+var formatPrefixRe = regexp.MustCompile(`^([[:alnum:]_-]+):`)
+
+// A coder name, e.g. "MVG" or "RADIAL-GRADIENT"
+var formatNameRe = regexp.MustCompile(`^[[:alnum:]_-]+$`)
+
+// Disables reading images of the formats, e.g. "MVG", "MSL", "EPHEMERAL",
+// "URL", "HTTPS" and "TEXT", which allow crafted input to read local files
+// or make requests. Matching ignores case. The formats are denied for all
+// wands of the process by ReadImage, ReadImageBlob, ReadImageFile, PingImage,
+// PingImageBlob and PingImageFile, which return a *FormatDeniedError before
+// decoding. The format is taken from an explicit prefix such as "mvg:file",
+// from the format set with SetFormat, and otherwise detected from the data
+// or the file extension, as ImageMagick does. A blob is also read in the
+// format of a prefix of the filename set with SetFilename. While any format
+// is disabled, a filename starting with @, which reads the files listed in a
+// file, and a blob or an *os.File whose format cannot be detected, e.g. a
+// pipe, are refused with an error matching ErrFormatDenied.
+//
+// Unlike a policy.xml, the denylist does not apply to files ImageMagick
+// reads by itself, e.g. images referenced by an MVG or MSL file, so keep
+// those formats disabled.
+func DisableFormats(formats ...string) error {
+	if err := validFormatNames(formats); err != nil {
+		return fmt.Errorf("DisableFormats: %s", err)
+	}
+	deniedFormatsMu.Lock()
+	defer deniedFormatsMu.Unlock()
+	for _, format := range formats {
+		deniedFormats[strings.ToUpper(format)] = true
+	}
+	return nil
+}
+
+// Enables reading images of the formats disabled with DisableFormats again
+func EnableFormats(formats ...string) error {
+	if err := validFormatNames(formats); err != nil {
+		return fmt.Errorf("EnableFormats: %s", err)
+	}
+	deniedFormatsMu.Lock()
+	defer deniedFormatsMu.Unlock()
+	for _, format := range formats {
+		delete(deniedFormats, strings.ToUpper(format))
+	}
+	return nil
+}
+
+func validFormatNames(formats []string) error {
+	for _, format := range formats {
+		if !formatNameRe.MatchString(format) {
+			return fmt.Errorf("invalid format %q", format)
+		}
+	}
+	return nil
+}
+
+// Returns a *FormatDeniedError if any of the formats is disabled, empty
+// formats are ignored
+func checkFormatsAllowed(formats ...string) error {
+	deniedFormatsMu.RLock()
+	defer deniedFormatsMu.RUnlock()
+	for _, format := range formats {
+		format = strings.ToUpper(format)
+		if deniedFormats[format] {
+			return &FormatDeniedError{Format: format}
+		}
+	}
+	return nil
+}
+
+func anyFormatDenied() bool {
+	deniedFormatsMu.RLock()
+	defer deniedFormatsMu.RUnlock()
+	return len(deniedFormats) > 0
+}
+
+// Returns an error if the wand would read blob, or filename if blob is nil,
+// as a disabled format. The caller holds mw.mu.
+func (mw *MagickWand) checkReadFormat(filename string, blob []byte) error {
+	if !anyFormatDenied() {
+		return nil
+	}
+	wandFormat := mw.wandFormat()
+	if blob != nil {
+		// ImageMagick takes a prefix of the wand filename for blobs too
+		var prefixFormat string
+		if m := formatPrefixRe.FindStringSubmatch(mw.wandFilename()); m != nil {
+			prefixFormat = m[1]
+		}
+		format := sniffBlobFormat(blob)
+		if wandFormat == "" && prefixFormat == "" && format == "" {
+			return fmt.Errorf("format of the blob cannot be detected: %w", ErrFormatDenied)
+		}
+		return checkFormatsAllowed(wandFormat, prefixFormat, format)
+	}
+
+	var format string
+	if filename != "" {
+		format = filenameFormat(filename)
+	}
+	if err := checkFormatsAllowed(wandFormat, format); err != nil {
+		return err
+	}
+	// The listed files are read in formats which are not checked
+	if strings.HasPrefix(strings.TrimPrefix(filename, formatPrefixRe.FindString(filename)), "@") {
+		return fmt.Errorf("%q reads a list of files: %w", filename, ErrFormatDenied)
+	}
+	return nil
+}
+
+// Returns an error if the wand would read file as a disabled format. The
+// format is detected from the first bytes at the current offset, which are
+// read with ReadAt so that ImageMagick still reads them. A file which cannot
+// be read ahead, such as a pipe, or whose format is neither detected nor set
+// with SetFormat is refused. The caller holds mw.mu.
+func (mw *MagickWand) checkReadFormatFile(file *os.File) error {
+	if !anyFormatDenied() {
+		return nil
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("format of %s cannot be detected: %w", file.Name(), ErrFormatDenied)
+	}
+	// sniffBlobFormat looks at no more than the first 8 KiB
+	head := make([]byte, 8192)
+	n, err := file.ReadAt(head, offset)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("format of %s cannot be detected: %w", file.Name(), ErrFormatDenied)
+	}
+
+	wandFormat, format := mw.wandFormat(), sniffBlobFormat(head[:n])
+	if wandFormat == "" && format == "" {
+		return fmt.Errorf("format of %s cannot be detected: %w", file.Name(), ErrFormatDenied)
+	}
+	return checkFormatsAllowed(wandFormat, format)
+}
+
+// Returns the format set with SetFormat. The caller holds mw.mu.
+func (mw *MagickWand) wandFormat() string {
+	cstr := C.MagickGetFormat(mw.mw)
+	defer relinquishMemory(unsafe.Pointer(cstr))
+	return C.GoString(cstr)
+}
+
+// Returns the filename set with SetFilename. The caller holds mw.mu.
+func (mw *MagickWand) wandFilename() string {
+	cstr := C.MagickGetFilename(mw.mw)
+	defer relinquishMemory(unsafe.Pointer(cstr))
+	return C.GoString(cstr)
+}
+
+// Returns the format ImageMagick reads filename as: the explicit prefix if
+// there is one, otherwise the format detected from the magic bytes of the
+// file or its extension. Prefixed filenames are not accessed, so that
+// nothing is fetched for "https:" and the like.
+func filenameFormat(filename string) string {
+	if m := formatPrefixRe.FindStringSubmatch(filename); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	csfilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(csfilename))
+
+	info := C.AcquireImageInfo()
+	defer C.DestroyImageInfo(info)
+	C.CopyMagickString(&info.filename[0], csfilename, C.MaxTextExtent)
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+	if C.SetImageInfo(info, 0, exc) == C.MagickFalse {
+		return ""
+	}
+	return C.GoString(&info.magick[0])
+}
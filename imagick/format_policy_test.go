@@ -0,0 +1,156 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDisableFormats(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secret := filepath.Join(dir, "secret.txt")
+	mvg := []byte("push graphic-context\nviewbox 0 0 10 10\nfill red\nrectangle 0,0 9,9\npop graphic-context\n")
+	drawing := filepath.Join(dir, "drawing.mvg")
+	for name, data := range map[string][]byte{secret: []byte("secret"), drawing: mvg} {
+		if err := ioutil.WriteFile(name, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	png := NewMagickWand()
+	defer png.Destroy()
+	if err := png.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.SetImageFormat("PNG"); err != nil {
+		t.Fatal(err)
+	}
+	pngBlob := png.GetImageBlob()
+
+	defer EnableFormats("MVG", "TEXT")
+	if err := DisableFormats("mvg", "TEXT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisableFormats("not a format"); err == nil {
+		t.Error("Expected an error for an invalid format name")
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	denied := []struct {
+		name string
+		read func() error
+	}{
+		{"MVG blob", func() error { return mw.ReadImageBlob(mvg) }},
+		{"MVG ping", func() error { return mw.PingImageBlob(mvg) }},
+		{"mvg: prefix", func() error { return mw.ReadImage("mvg:" + secret) }},
+		{".mvg extension", func() error { return mw.ReadImage(drawing) }},
+		{"text:@file", func() error { return mw.ReadImage("text:@" + secret) }},
+	}
+	for _, d := range denied {
+		err := d.read()
+		var ferr *FormatDeniedError
+		if !errors.Is(err, ErrFormatDenied) || !errors.As(err, &ferr) {
+			t.Errorf("%s: expected ErrFormatDenied, got %v", d.name, err)
+		}
+	}
+	if n := mw.GetNumberImages(); n != 0 {
+		t.Errorf("Expected nothing to be read, got %d images", n)
+	}
+
+	if err := mw.ReadImageBlob(pngBlob); err != nil {
+		t.Fatalf("Expected PNG to still be read: %s", err)
+	}
+	if err := EnableFormats("MVG"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImageBlob(mvg); errors.Is(err, ErrFormatDenied) {
+		t.Errorf("Expected MVG to be read after EnableFormats, got %v", err)
+	}
+
+	if err := DisableFormats("MVG"); err != nil {
+		t.Fatal(err)
+	}
+	list := filepath.Join(dir, "list.txt")
+	disguised := filepath.Join(dir, "drawing.png")
+	pngFile := filepath.Join(dir, "logo.png")
+	for name, data := range map[string][]byte{list: []byte(drawing + "\n"), disguised: mvg, pngFile: pngBlob} {
+		if err := ioutil.WriteFile(name, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.ReadImage("@" + list); !errors.Is(err, ErrFormatDenied) {
+		t.Errorf("Expected a list of files to be refused, got %v", err)
+	}
+	if err := mw.PingImage("@" + list); !errors.Is(err, ErrFormatDenied) {
+		t.Errorf("Expected a list of files to be refused by PingImage, got %v", err)
+	}
+
+	mw.Clear()
+	readFile := func(name string, read func(*os.File) error) error {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		return read(file)
+	}
+	var ferr *FormatDeniedError
+	if err := readFile(disguised, mw.ReadImageFile); !errors.As(err, &ferr) || ferr.Format != "MVG" {
+		t.Errorf("Expected ReadImageFile to detect MVG from the data, got %v", err)
+	}
+	if err := readFile(disguised, mw.PingImageFile); !errors.As(err, &ferr) || ferr.Format != "MVG" {
+		t.Errorf("Expected PingImageFile to detect MVG from the data, got %v", err)
+	}
+	if err := readFile(secret, mw.ReadImageFile); !errors.Is(err, ErrFormatDenied) {
+		t.Errorf("Expected a file of unknown format to be refused, got %v", err)
+	}
+	if err := readFile(pngFile, mw.ReadImageFile); err != nil {
+		t.Errorf("Expected a PNG file to be read from the start, got %v", err)
+	}
+
+	// A blob is read in the format of the prefix of the wand filename
+	blob := NewMagickWand()
+	defer blob.Destroy()
+	if err := blob.SetFilename("mvg:x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := blob.ReadImageBlob(pngBlob); !errors.As(err, &ferr) || ferr.Format != "MVG" {
+		t.Errorf("Expected ReadImageBlob to take MVG from the filename, got %v", err)
+	}
+	if err := blob.PingImageBlob(pngBlob); !errors.As(err, &ferr) || ferr.Format != "MVG" {
+		t.Errorf("Expected PingImageBlob to take MVG from the filename, got %v", err)
+	}
+	if err := mw.ReadImageBlob([]byte("secret")); !errors.Is(err, ErrFormatDenied) {
+		t.Errorf("Expected a blob of unknown format to be refused, got %v", err)
+	}
+
+	// Coder names may contain a dash
+	defer EnableFormats("RADIAL-GRADIENT")
+	if err := DisableFormats("RADIAL-GRADIENT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("radial-gradient:red-blue"); !errors.As(err, &ferr) || ferr.Format != "RADIAL-GRADIENT" {
+		t.Errorf("Expected RADIAL-GRADIENT to be denied, got %v", err)
+	}
+
+	if format := filenameFormat("https://example.com/image.png"); format != "HTTPS" {
+		t.Errorf("Expected the HTTPS prefix, got %q", format)
+	}
+}
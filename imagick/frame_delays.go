@@ -0,0 +1,134 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Returns the delay of every image of the wand in ticks, see
+// GetImageDelay. The iterator position is kept.
+func (mw *MagickWand) GetFrameDelays() []uint {
+	delays := make([]uint, mw.GetNumberImages())
+	mw.eachFrame(func(i int) error {
+		delays[i] = mw.GetImageDelay()
+		return nil
+	})
+	return delays
+}
+
+// Sets the delay of every image of the wand in ticks, delays must hold one
+// delay per image. The iterator position is kept.
+func (mw *MagickWand) SetFrameDelays(delays []uint) error {
+	if n := mw.GetNumberImages(); uint(len(delays)) != n {
+		return fmt.Errorf("SetFrameDelays: got %d delays for %d images", len(delays), n)
+	}
+	return mw.eachFrame(func(i int) error {
+		return mw.SetImageDelay(delays[i])
+	})
+}
+
+// Returns how long one loop of the animation plays, the sum of the frame
+// delays, each in ticks of its image, see GetImageTicksPerSecond. The
+// iterator position is kept.
+func (mw *MagickWand) TotalAnimationDuration() time.Duration {
+	var total time.Duration
+	mw.eachFrame(func(int) error {
+		ticks := mw.GetImageTicksPerSecond()
+		if ticks == 0 {
+			ticks = 100
+		}
+		total += time.Duration(mw.GetImageDelay()) * time.Second / time.Duration(ticks)
+		return nil
+	})
+	return total
+}
+
+// Raises frame delays below min to min, e.g. 2 for GIFs, as browsers play
+// shorter delays as 10 hundredths of a second. To keep the duration of the
+// animation, the time added is taken from the longer frames in proportion
+// to how far they are above min. If they cannot make up for it the
+// animation gets longer. The iterator position is kept.
+func (mw *MagickWand) NormalizeFrameDelays(min uint) error {
+	if mw.GetNumberImages() == 0 {
+		return errors.New("NormalizeFrameDelays: wand has no images")
+	}
+	return mw.SetFrameDelays(normalizeDelays(mw.GetFrameDelays(), min))
+}
+
+// Calls fn with the index of each image of the wand as the current image,
+// and restores the iterator position afterwards
+func (mw *MagickWand) eachFrame(fn func(i int) error) error {
+	n := int(mw.GetNumberImages())
+	if n == 0 {
+		return nil
+	}
+	index := int(mw.GetIteratorIndex())
+	defer mw.SetIteratorIndex(index)
+	for i := 0; i < n; i++ {
+		mw.SetIteratorIndex(i)
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns delays with those below min raised to min and the others reduced
+// in proportion to their headroom above min, by as much as was added
+func normalizeDelays(delays []uint, min uint) []uint {
+	normalized := make([]uint, len(delays))
+	var added, headroom uint
+	for i, d := range delays {
+		normalized[i] = d
+		if d < min {
+			normalized[i] = min
+			added += min - d
+		} else {
+			headroom += d - min
+		}
+	}
+	if added == 0 || headroom == 0 {
+		return normalized
+	}
+	if added > headroom {
+		added = headroom
+	}
+
+	// Largest remainder rounding, so that exactly added is taken
+	type cut struct {
+		index    int
+		fraction float64
+	}
+	var cuts []cut
+	var taken uint
+	for i, d := range delays {
+		if d <= min {
+			continue
+		}
+		share := float64(added) * float64(d-min) / float64(headroom)
+		whole := uint(math.Floor(share))
+		normalized[i] -= whole
+		taken += whole
+		cuts = append(cuts, cut{i, share - float64(whole)})
+	}
+	sort.SliceStable(cuts, func(a, b int) bool {
+		return cuts[a].fraction > cuts[b].fraction
+	})
+	for _, c := range cuts {
+		if taken == added {
+			break
+		}
+		if normalized[c.index] > min {
+			normalized[c.index]--
+			taken++
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,74 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeFrameDelays(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for i := 0; i < 3; i++ {
+		if err := mw.ReadImage("xc:red[8x8]"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.SetFrameDelays([]uint{1, 1}); err == nil {
+		t.Error("Expected an error for fewer delays than images")
+	}
+	if err := mw.SetFrameDelays([]uint{1, 1, 50}); err != nil {
+		t.Fatal(err)
+	}
+	mw.SetIteratorIndex(1)
+
+	before := mw.TotalAnimationDuration()
+	if before != 520*time.Millisecond {
+		t.Errorf("Expected a duration of 520ms, got %s", before)
+	}
+	if err := mw.NormalizeFrameDelays(2); err != nil {
+		t.Fatal(err)
+	}
+	delays := mw.GetFrameDelays()
+	for i, d := range delays {
+		if d < 2 {
+			t.Errorf("Frame %d has a delay of %d, below the minimum", i, d)
+		}
+	}
+	after := mw.TotalAnimationDuration()
+	if diff := after - before; diff > before/20 || diff < -before/20 {
+		t.Errorf("Expected the duration to stay within 5%% of %s, got %s", before, after)
+	}
+	if i := mw.GetIteratorIndex(); i != 1 {
+		t.Errorf("Expected the iterator to stay at 1, got %d", i)
+	}
+}
+
+func TestNormalizeDelays(t *testing.T) {
+	cases := []struct {
+		delays []uint
+		min    uint
+		want   []uint
+	}{
+		{[]uint{1, 1, 50}, 2, []uint{2, 2, 48}},
+		{[]uint{0, 0, 0}, 2, []uint{2, 2, 2}},
+		{[]uint{1, 10, 20}, 2, []uint{2, 10, 19}},
+		{[]uint{0, 3}, 2, []uint{2, 2}},
+		{[]uint{5, 10}, 2, []uint{5, 10}},
+	}
+	for _, c := range cases {
+		if got := normalizeDelays(c.delays, c.min); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("normalizeDelays(%v, %d) = %v, want %v", c.delays, c.min, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+// Removes frames of an animation which look the same as the frame before
+// them, adding their delay to that frame, so that runs of identical frames
+// such as in screen recordings play as one long frame. The sequence is
+// coalesced for the comparison and optimized again afterwards, so the
+// animation plays as before whatever the dispose methods. Frames are
+// compared by GetImageSignature, see DeduplicateFramesFuzzy for frames
+// which differ slightly. Returns the number of frames removed. A single
+// image is left as it is.
+func (mw *MagickWand) DeduplicateFrames() (removed int, err error) {
+	return mw.DeduplicateFramesFuzzy(0)
+}
+
+// Like DeduplicateFrames, but frames whose root mean squared difference
+// from the frame before them, between 0 and 1, is at most fuzz count as
+// duplicates. Fuzz 0 compares signatures as DeduplicateFrames does.
+func (mw *MagickWand) DeduplicateFramesFuzzy(fuzz float64) (removed int, err error) {
+	if mw.GetNumberImages() <= 1 {
+		return 0, nil
+	}
+	coalesced, err := mw.coalesced()
+	if err != nil {
+		return 0, err
+	}
+	defer coalesced.Destroy()
+
+	// The frame duplicates are merged into, a copy for fuzzy comparison
+	var survivor *MagickWand
+	defer func() {
+		if survivor != nil {
+			survivor.Destroy()
+		}
+	}()
+	var survivorSignature string
+	survivorIndex := 0
+
+	for i := 0; coalesced.SetIteratorIndex(i); {
+		duplicate := false
+		signature := coalesced.GetImageSignature()
+		if i > 0 {
+			if fuzz <= 0 {
+				duplicate = signature == survivorSignature
+			} else {
+				distortion, err := coalesced.GetImageDistortion(survivor, METRIC_ROOT_MEAN_SQUARED_ERROR)
+				if err != nil {
+					return 0, err
+				}
+				duplicate = distortion <= fuzz
+			}
+		}
+
+		if !duplicate {
+			if fuzz > 0 {
+				if survivor != nil {
+					survivor.Destroy()
+				}
+				if survivor, err = coalesced.currentImage(); err != nil {
+					return 0, err
+				}
+			}
+			survivorSignature, survivorIndex = signature, i
+			i++
+			continue
+		}
+
+		delay := coalesced.GetImageDelay()
+		if err := coalesced.RemoveImage(); err != nil {
+			return 0, err
+		}
+		removed++
+		coalesced.SetIteratorIndex(survivorIndex)
+		if err := coalesced.SetImageDelay(coalesced.GetImageDelay() + delay); err != nil {
+			return 0, err
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := mw.replaceOptimized(coalesced); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
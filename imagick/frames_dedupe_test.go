@@ -0,0 +1,73 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDeduplicateFrames(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// Frames 3 to 6 are identical, frame i has a delay of i+1
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for i := 0; i < 10; i++ {
+		frame := fmt.Sprintf("xc:rgb(%d,0,0)[32x24]", i*20)
+		if i >= 3 && i <= 6 {
+			frame = "xc:gray50[32x24]"
+		}
+		if err := mw.ReadImage(frame); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageDelay(uint(i + 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := mw.DeduplicateFrames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 || mw.GetNumberImages() != 7 {
+		t.Fatalf("Expected 3 frames removed and 7 left, got %d and %d", removed, mw.GetNumberImages())
+	}
+	wantDelays := []uint{1, 2, 3, 4 + 5 + 6 + 7, 8, 9, 10}
+	for i, want := range wantDelays {
+		mw.SetIteratorIndex(i)
+		if delay := mw.GetImageDelay(); delay != want {
+			t.Errorf("Frame %d has a delay of %d, expected %d", i, delay, want)
+		}
+	}
+
+	// Nearly identical frames only merge with fuzz
+	near := NewMagickWand()
+	defer near.Destroy()
+	for _, frame := range []string{"xc:rgb(100,100,100)[16x16]", "xc:rgb(101,100,100)[16x16]"} {
+		if err := near.ReadImage(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if removed, err := near.DeduplicateFrames(); err != nil || removed != 0 {
+		t.Errorf("Expected no frames removed without fuzz, got %d (%v)", removed, err)
+	}
+	if removed, err := near.DeduplicateFramesFuzzy(0.01); err != nil || removed != 1 || near.GetNumberImages() != 1 {
+		t.Errorf("Expected 1 frame removed with fuzz, got %d (%v)", removed, err)
+	}
+
+	single := NewMagickWand()
+	defer single.Destroy()
+	if err := single.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := single.DeduplicateFrames(); err != nil || removed != 0 {
+		t.Errorf("Expected a single image to be left alone, got %d (%v)", removed, err)
+	}
+}
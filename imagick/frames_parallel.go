@@ -0,0 +1,108 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Applies fn to every image of the wand in parallel, e.g. to sharpen the
+// frames of an animated GIF on several cores. Each frame is copied into a
+// wand of its own which fn may modify, at most workers frames are processed
+// at once, and workers below 1 use GOMAXPROCS. The processed frames replace
+// the images of the wand in their original order, with their original
+// delay, dispose method and iterations.
+//
+// If fn fails no further frames are started, the wand is left unchanged and
+// the first error is returned. fn must not keep or destroy the frame.
+func (mw *MagickWand) MapFramesParallel(workers int, fn func(frame *MagickWand) error) error {
+	count := int(mw.GetNumberImages())
+	if count == 0 {
+		return errors.New("MapFramesParallel: wand has no images")
+	}
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	type settings struct {
+		delay, iterations uint
+		dispose           DisposeType
+	}
+	frames := make([]*MagickWand, count)
+	defer func() {
+		for _, frame := range frames {
+			if frame != nil {
+				frame.Destroy()
+			}
+		}
+	}()
+	frameSettings := make([]settings, count)
+	for i := range frames {
+		mw.SetIteratorIndex(i)
+		frame, err := mw.currentImage()
+		if err != nil {
+			return err
+		}
+		frames[i] = frame
+		frameSettings[i] = settings{mw.GetImageDelay(), mw.GetImageIterations(), mw.GetImageDispose()}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	failed := make(chan struct{})
+	jobs := make(chan int)
+	for w := 0; w < workers && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(frames[i]); err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for i := range frames {
+		select {
+		case jobs <- i:
+		case <-failed:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+
+	result := NewMagickWand()
+	defer result.Destroy()
+	for i, frame := range frames {
+		if err := result.AddImage(frame); err != nil {
+			return err
+		}
+		result.SetIteratorIndex(i)
+		s := frameSettings[i]
+		if err := result.SetImageDelay(s.delay); err != nil {
+			return err
+		}
+		if err := result.SetImageIterations(s.iterations); err != nil {
+			return err
+		}
+		if err := result.SetImageDispose(s.dispose); err != nil {
+			return err
+		}
+	}
+	return mw.replaceImages(result)
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Returns a sequence of count gradient frames whose delay is their index
+func gradientSequence(t *testing.T, count int) *MagickWand {
+	mw := NewMagickWand()
+	for i := 0; i < count; i++ {
+		if err := mw.ReadImage(fmt.Sprintf("gradient:rgb(%d,0,0)-blue[32x24]", i*12)); err != nil {
+			mw.Destroy()
+			t.Fatal(err)
+		}
+		if err := mw.SetImageDelay(uint(i)); err != nil {
+			mw.Destroy()
+			t.Fatal(err)
+		}
+	}
+	return mw
+}
+
+func TestMapFramesParallel(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const count = 20
+	blur := func(frame *MagickWand) error {
+		if err := frame.BlurImage(0, 2); err != nil {
+			return err
+		}
+		// The original delay must be restored
+		return frame.SetImageDelay(100)
+	}
+
+	serial := gradientSequence(t, count)
+	defer serial.Destroy()
+	for i := 0; i < count; i++ {
+		serial.SetIteratorIndex(i)
+		if err := serial.BlurImage(0, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parallel := gradientSequence(t, count)
+	defer parallel.Destroy()
+	if err := parallel.MapFramesParallel(4, blur); err != nil {
+		t.Fatal(err)
+	}
+	if n := parallel.GetNumberImages(); n != count {
+		t.Fatalf("Expected %d frames, got %d", count, n)
+	}
+	for i := 0; i < count; i++ {
+		serial.SetIteratorIndex(i)
+		parallel.SetIteratorIndex(i)
+		if got, want := parallel.GetImageSignature(), serial.GetImageSignature(); got != want {
+			t.Errorf("Frame %d: signature %s, want %s", i, got, want)
+		}
+		if delay := parallel.GetImageDelay(); delay != uint(i) {
+			t.Errorf("Frame %d: delay %d, want %d", i, delay, i)
+		}
+	}
+
+	errFrame7 := errors.New("frame 7 failed")
+	failing := gradientSequence(t, count)
+	defer failing.Destroy()
+	err := failing.MapFramesParallel(4, func(frame *MagickWand) error {
+		if frame.GetImageDelay() == 7 {
+			return errFrame7
+		}
+		return frame.BlurImage(0, 2)
+	})
+	if err != errFrame7 {
+		t.Fatalf("Expected the frame 7 error, got %v", err)
+	}
+	if n := failing.GetNumberImages(); n != count {
+		t.Fatalf("Expected the %d frames to be left unchanged, got %d", count, n)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <stdlib.h>
+#include <magick/MagickCore.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Geometry is an ImageMagick geometry string resolved against an image size
+type Geometry struct {
+	Width  uint
+	Height uint
+	X      int
+	Y      int
+
+	Percent     bool // '%' sizes are a percentage of the current size
+	OnlyShrink  bool // '>' only shrink images larger than the geometry
+	OnlyEnlarge bool // '<' only enlarge images smaller than the geometry
+	Fill        bool // '^' the size covers the geometry rather than fitting inside it
+	Force       bool // '!' the aspect ratio is ignored
+}
+
+// Parses a geometry string such as "800x600>", "50%", "200x200^",
+// "640x480!" or "x300" and resolves it against the current width and height
+// the way "convert -resize" does. Width and Height of the result are the
+// final size, which is the current size when a '>' or '<' condition does
+// not apply.
+func ParseGeometry(geometry string, curWidth, curHeight uint) (Geometry, error) {
+	csgeometry := C.CString(geometry)
+	defer C.free(unsafe.Pointer(csgeometry))
+
+	var (
+		x, y          C.ssize_t
+		width, height = C.size_t(curWidth), C.size_t(curHeight)
+	)
+	flags := int(C.ParseMetaGeometry(csgeometry, &x, &y, &width, &height))
+	if flags == C.NoValue {
+		return Geometry{}, fmt.Errorf("invalid geometry %q", geometry)
+	}
+
+	return Geometry{
+		Width:       uint(width),
+		Height:      uint(height),
+		X:           int(x),
+		Y:           int(y),
+		Percent:     flags&PERCENTVALUE != 0,
+		OnlyShrink:  flags&GREATERVALUE != 0,
+		OnlyEnlarge: flags&LESSVALUE != 0,
+		Fill:        flags&MINIMUMVALUE != 0,
+		Force:       flags&ASPECTVALUE != 0,
+	}, nil
+}
+
+// Resizes the image to a geometry string, see ParseGeometry
+func (mw *MagickWand) ResizeImageGeometry(geometry string, filter FilterType, blur float64) error {
+	g, err := ParseGeometry(geometry, mw.GetImageWidth(), mw.GetImageHeight())
+	if err != nil {
+		return err
+	}
+	if g.Width == mw.GetImageWidth() && g.Height == mw.GetImageHeight() {
+		return nil
+	}
+	return mw.ResizeImage(g.Width, g.Height, filter, blur)
+}
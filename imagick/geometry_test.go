@@ -0,0 +1,77 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestParseGeometry(t *testing.T) {
+	Initialize()
+	defer Terminate()
+
+	tests := []struct {
+		geometry      string
+		curW, curH    uint
+		width, height uint
+		x, y          int
+		check         func(Geometry) bool
+	}{
+		{"800x600>", 1000, 500, 800, 400, 0, 0, func(g Geometry) bool { return g.OnlyShrink }},
+		{"800x600>", 400, 300, 400, 300, 0, 0, func(g Geometry) bool { return g.OnlyShrink }},
+		{"100x100<", 50, 40, 100, 80, 0, 0, func(g Geometry) bool { return g.OnlyEnlarge }},
+		{"50%", 800, 600, 400, 300, 0, 0, func(g Geometry) bool { return g.Percent }},
+		{"200x200^", 800, 600, 267, 200, 0, 0, func(g Geometry) bool { return g.Fill }},
+		{"640x480!", 800, 600, 640, 480, 0, 0, func(g Geometry) bool { return g.Force }},
+		{"x300", 800, 600, 400, 300, 0, 0, func(g Geometry) bool { return !g.Force && !g.Fill }},
+		{"300x300+10-20", 600, 400, 300, 200, 10, -20, func(g Geometry) bool { return !g.Percent }},
+	}
+
+	for _, tt := range tests {
+		g, err := ParseGeometry(tt.geometry, tt.curW, tt.curH)
+		if err != nil {
+			t.Errorf("%q: %s", tt.geometry, err)
+			continue
+		}
+		if g.Width != tt.width || g.Height != tt.height || g.X != tt.x || g.Y != tt.y {
+			t.Errorf("%q on %dx%d: expected %dx%d%+d%+d, got %dx%d%+d%+d", tt.geometry, tt.curW, tt.curH,
+				tt.width, tt.height, tt.x, tt.y, g.Width, g.Height, g.X, g.Y)
+		}
+		if !tt.check(g) {
+			t.Errorf("%q: unexpected flags %+v", tt.geometry, g)
+		}
+	}
+
+	if _, err := ParseGeometry("not a geometry", 100, 100); err == nil {
+		t.Fatal("Expected an error for an invalid geometry")
+	}
+}
+
+func TestResizeImageGeometry(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage("xc:white[800x600]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ResizeImageGeometry("200x200^", FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 267 || h != 200 {
+		t.Fatalf("Expected 200x200^ to cover the box with 267x200, got %dx%d", w, h)
+	}
+	if err := mw.ResizeImageGeometry("1000x1000>", FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 267 || h != 200 {
+		t.Fatalf("Expected 1000x1000> not to enlarge, got %dx%d", w, h)
+	}
+}
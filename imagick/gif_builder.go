@@ -0,0 +1,134 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import "errors"
+
+// GIFBuilder assembles an animated GIF frame by frame. Frames are copied
+// into the builder, so the source wands may be reused or destroyed after
+// AppendFrame. Call Destroy when done.
+type GIFBuilder struct {
+	mw            *MagickWand
+	loops         uint
+	width, height uint
+}
+
+// Returns a new builder for an animation which loops forever.
+func NewGIFBuilder() *GIFBuilder {
+	return &GIFBuilder{mw: NewMagickWand()}
+}
+
+// Destroys the builder and its frames.
+func (b *GIFBuilder) Destroy() {
+	b.mw.Destroy()
+}
+
+// Sets the number of times the animation is played, 0 loops forever.
+func (b *GIFBuilder) SetLoopCount(n uint) {
+	b.loops = n
+}
+
+// Appends a copy of the current image of frame, shown for delayCS
+// hundredths of a second. The first frame sets the canvas size, later frames
+// of a different size are placed onto a transparent canvas of that size at
+// their page offset.
+func (b *GIFBuilder) AppendFrame(frame *MagickWand, delayCS uint, dispose DisposeType) error {
+	img, err := frame.currentImage()
+	if err != nil {
+		return err
+	}
+	defer img.Destroy()
+
+	width, height := img.GetImageWidth(), img.GetImageHeight()
+	if b.width == 0 {
+		b.width, b.height = width, height
+	} else if width != b.width || height != b.height {
+		if err := b.extendFrame(img); err != nil {
+			return err
+		}
+	}
+
+	b.mw.SetLastIterator()
+	if err := b.mw.AddImage(img); err != nil {
+		return err
+	}
+	b.mw.SetLastIterator()
+	if err := b.mw.SetImageDelay(delayCS); err != nil {
+		return err
+	}
+	return b.mw.SetImageDispose(dispose)
+}
+
+// Places img at its page offset onto a transparent canvas of the size of
+// the first frame.
+func (b *GIFBuilder) extendFrame(img *MagickWand) error {
+	_, _, x, y, err := img.GetImagePage()
+	if err != nil {
+		return err
+	}
+	if err := img.ResetImagePage(""); err != nil {
+		return err
+	}
+	if err := img.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		return err
+	}
+	transparent := NewPixelWand()
+	defer transparent.Destroy()
+	transparent.SetColor("none")
+	if err := img.SetImageBackgroundColor(transparent); err != nil {
+		return err
+	}
+	return img.ExtentImage(b.width, b.height, -x, -y)
+}
+
+// Returns a new wand holding the optimized animation. The frames are
+// optimized with OptimizeImageLayers, which may pick other disposal methods
+// than the ones given to AppendFrame, and remapped to a shared palette so
+// that colors do not shift between frames.
+func (b *GIFBuilder) Build() (*MagickWand, error) {
+	if b.mw.GetNumberImages() == 0 {
+		return nil, errors.New("Build: no frames")
+	}
+
+	optimized, err := b.mw.OptimizeImageLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	palette, err := optimized.sharedPalette(256)
+	if err != nil {
+		optimized.Destroy()
+		return nil, err
+	}
+	defer palette.Destroy()
+
+	optimized.ResetIterator()
+	for optimized.NextImage() {
+		if err := optimized.RemapImage(palette, DITHER_METHOD_NO); err != nil {
+			optimized.Destroy()
+			return nil, err
+		}
+		if err := optimized.SetImageIterations(b.loops); err != nil {
+			optimized.Destroy()
+			return nil, err
+		}
+		if err := optimized.SetImageFormat("GIF"); err != nil {
+			optimized.Destroy()
+			return nil, err
+		}
+	}
+	optimized.ResetIterator()
+	return optimized, nil
+}
+
+// Builds the animation and writes it to filename.
+func (b *GIFBuilder) WriteGIF(filename string) error {
+	mw, err := b.Build()
+	if err != nil {
+		return err
+	}
+	defer mw.Destroy()
+	return mw.WriteImages("GIF:"+filename, true)
+}
@@ -0,0 +1,108 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGIFBuilder(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick_gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bounce.gif")
+
+	square := NewMagickWand()
+	defer square.Destroy()
+	if err := square.ReadImage("xc:red[10x10]"); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewGIFBuilder()
+	defer b.Destroy()
+	b.SetLoopCount(3)
+
+	// The square falls to the bottom, then bounces up as a frame which only
+	// covers the square at its page offset.
+	for i, y := range []int{0, 30} {
+		frame := NewMagickWand()
+		if err := frame.ReadImage("xc:white[40x40]"); err != nil {
+			t.Fatal(err)
+		}
+		if err := frame.CompositeImage(square, COMPOSITE_OP_OVER, 15, y); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.AppendFrame(frame, uint(10*(i+1)), DISPOSE_NONE); err != nil {
+			t.Fatal(err)
+		}
+		frame.Destroy()
+	}
+	if err := square.SetImagePage(40, 40, 15, 15); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AppendFrame(square, 30, DISPOSE_NONE); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.WriteGIF(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage(filename); err != nil {
+		t.Fatal(err)
+	}
+	if n := mw.GetNumberImages(); n != 3 {
+		t.Fatalf("Expected 3 frames, got %d", n)
+	}
+	for i := 0; mw.SetIteratorIndex(i); i++ {
+		if delay := mw.GetImageDelay(); delay != uint(10*(i+1)) {
+			t.Errorf("Frame %d: expected delay %d, got %d", i, 10*(i+1), delay)
+		}
+		if iterations := mw.GetImageIterations(); iterations != 3 {
+			t.Errorf("Frame %d: expected 3 iterations, got %d", i, iterations)
+		}
+	}
+
+	mw.ResetIterator()
+	coalesced, err := mw.CoalesceImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coalesced.Destroy()
+	coalesced.SetLastIterator()
+	if w, h := coalesced.GetImageWidth(), coalesced.GetImageHeight(); w != 40 || h != 40 {
+		t.Fatalf("Expected the last frame on a 40x40 canvas, got %dx%d", w, h)
+	}
+	expectPixelColor(t, coalesced, 20, 20, "red")
+	expectPixelColor(t, coalesced, 20, 35, "red")
+	expectPixelColor(t, coalesced, 5, 5, "white")
+}
+
+func TestGIFBuilderEmpty(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	b := NewGIFBuilder()
+	defer b.Destroy()
+	if _, err := b.Build(); err == nil {
+		t.Fatal("Expected an error building an animation without frames")
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"runtime"
+	"unsafe"
+)
+
+// Exports the current image as a Go image.Image. Grayscale images are
+// returned as *image.Gray, images with a depth above 8 bits as
+// *image.NRGBA64, and everything else as *image.NRGBA.
+func (mw *MagickWand) ToImage() (image.Image, error) {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return nil, errors.New("ToImage: wand has no image")
+	}
+	rect := image.Rect(0, 0, int(width), int(height))
+
+	if mw.GetImageColorspace() == COLORSPACE_CMYK {
+		rgb, err := mw.GetImage()
+		if err != nil {
+			return nil, err
+		}
+		defer rgb.Destroy()
+		if err := rgb.TransformImageColorspace(COLORSPACE_SRGB); err != nil {
+			return nil, err
+		}
+		mw = rgb
+	}
+
+	if mw.GetImageDepth() > 8 {
+		pixels := make([]uint16, int(width)*int(height)*4)
+		if err := mw.exportPixels(0, 0, width, height, "RGBA", PIXEL_SHORT, unsafe.Pointer(&pixels[0])); err != nil {
+			return nil, err
+		}
+		img := image.NewNRGBA64(rect)
+		for i, v := range pixels {
+			img.Pix[2*i] = uint8(v >> 8)
+			img.Pix[2*i+1] = uint8(v)
+		}
+		return img, nil
+	}
+
+	switch mw.GetImageType() {
+	case IMAGE_TYPE_GRAYSCALE, IMAGE_TYPE_BILEVEL:
+		img := image.NewGray(rect)
+		if err := mw.exportPixels(0, 0, width, height, "I", PIXEL_CHAR, unsafe.Pointer(&img.Pix[0])); err != nil {
+			return nil, err
+		}
+		return img, nil
+	}
+
+	img := image.NewNRGBA(rect)
+	if err := mw.exportPixels(0, 0, width, height, "RGBA", PIXEL_CHAR, unsafe.Pointer(&img.Pix[0])); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Exports the current image into dst, whose bounds must have the size of the
+// image, so that one *image.RGBA can be reused for many frames. image.RGBA
+// holds alpha-premultiplied colors, so the straight alpha colors of
+// ImageMagick are premultiplied in Go if the image has an alpha channel.
+func (mw *MagickWand) ExportToRGBA(dst *image.RGBA) error {
+	src, release, err := mw.exportSource("ExportToRGBA", dst.Rect)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := src.exportRows(dst.Pix, dst.Stride, dst.Rect, "RGBA"); err != nil {
+		return err
+	}
+	if !src.GetImageAlphaChannel() {
+		return nil
+	}
+	width := 4 * dst.Rect.Dx()
+	for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+		row := dst.Pix[dst.PixOffset(dst.Rect.Min.X, y):][:width]
+		for i := 0; i < len(row); i += 4 {
+			a := row[i+3]
+			row[i], row[i+1], row[i+2] = premultiply(row[i], a), premultiply(row[i+1], a), premultiply(row[i+2], a)
+		}
+	}
+	return nil
+}
+
+// Exports the current image into dst, whose bounds must have the size of the
+// image. The colors are not premultiplied, as image.NRGBA expects.
+func (mw *MagickWand) ExportToNRGBA(dst *image.NRGBA) error {
+	src, release, err := mw.exportSource("ExportToNRGBA", dst.Rect)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return src.exportRows(dst.Pix, dst.Stride, dst.Rect, "RGBA")
+}
+
+// Exports the intensity of the current image into dst, whose bounds must
+// have the size of the image. Alpha is ignored.
+func (mw *MagickWand) ExportToGray(dst *image.Gray) error {
+	src, release, err := mw.exportSource("ExportToGray", dst.Rect)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return src.exportRows(dst.Pix, dst.Stride, dst.Rect, "I")
+}
+
+// Checks that the current image has the size of bounds and returns a wand
+// with its pixels in RGB, converting CMYK images like ToImage does. release
+// frees the conversion.
+func (mw *MagickWand) exportSource(op string, bounds image.Rectangle) (src *MagickWand, release func(), err error) {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return nil, nil, fmt.Errorf("%s: wand has no image", op)
+	}
+	if bounds.Dx() != int(width) || bounds.Dy() != int(height) {
+		return nil, nil, fmt.Errorf("%s: destination is %dx%d, image is %dx%d", op, bounds.Dx(), bounds.Dy(), width, height)
+	}
+	if mw.GetImageColorspace() != COLORSPACE_CMYK {
+		return mw, func() {}, nil
+	}
+	rgb, err := mw.currentImage()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rgb.TransformImageColorspace(COLORSPACE_SRGB); err != nil {
+		rgb.Destroy()
+		return nil, nil, err
+	}
+	return rgb, rgb.Destroy, nil
+}
+
+// Exports the current image as 8-bit pmap values into the pixels of a Go
+// image covering bounds, with one call if the rows are contiguous and one
+// call per row otherwise.
+func (mw *MagickWand) exportRows(pix []byte, stride int, bounds image.Rectangle, pmap string) error {
+	width, height := bounds.Dx(), bounds.Dy()
+	rowLen := width * len(pmap)
+	if len(pix) < stride*(height-1)+rowLen {
+		return fmt.Errorf("exportRows: %d bytes of pixels are too short for %dx%d with stride %d", len(pix), width, height, stride)
+	}
+	if stride == rowLen {
+		return mw.exportPixels(0, 0, uint(width), uint(height), pmap, PIXEL_CHAR, unsafe.Pointer(&pix[0]))
+	}
+	for y := 0; y < height; y++ {
+		if err := mw.exportPixels(0, y, uint(width), 1, pmap, PIXEL_CHAR, unsafe.Pointer(&pix[y*stride])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exports pixels into memory owned by the caller, which must be large
+// enough to hold cols*rows*len(pmap) values of the given storage type.
+func (mw *MagickWand) exportPixels(x, y int, cols, rows uint, pmap string, stype StorageType, ptr unsafe.Pointer) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cspmap := C.CString(pmap)
+	defer C.free(unsafe.Pointer(cspmap))
+	ok := C.MagickExportImagePixels(mw.mw, C.ssize_t(x), C.ssize_t(y), C.size_t(cols), C.size_t(rows), cspmap, C.StorageType(stype), ptr)
+	runtime.KeepAlive(mw)
+	return mw.getLastErrorIfFailed(ok)
+}
+
+// Returns a new wand holding a copy of a Go image.Image. NRGBA, RGBA, Gray,
+// Gray16, YCbCr and CMYK images are imported directly, other image types
+// are converted to NRGBA first. Premultiplied RGBA colors are converted to
+// straight alpha.
+//
+// This is the image.Image counterpart of NewMagickWandFromImage, which
+// takes an ImageMagick *Image.
+func NewMagickWandFromGoImage(img image.Image) (*MagickWand, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("NewMagickWandFromGoImage: image is empty")
+	}
+
+	var (
+		pmap   string
+		stype  StorageType
+		pixels interface{}
+	)
+
+	switch src := img.(type) {
+	case *image.NRGBA:
+		pmap, stype = "RGBA", PIXEL_CHAR
+		pixels = packRows(src.Pix, src.PixOffset(bounds.Min.X, bounds.Min.Y), src.Stride, width*4, height)
+
+	case *image.RGBA:
+		pmap, stype = "RGBA", PIXEL_CHAR
+		buf := make([]byte, 0, width*height*4)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			row := src.Pix[src.PixOffset(bounds.Min.X, y):]
+			for x := 0; x < width; x++ {
+				r, g, b, a := row[4*x], row[4*x+1], row[4*x+2], row[4*x+3]
+				buf = append(buf, unpremultiply(r, a), unpremultiply(g, a), unpremultiply(b, a), a)
+			}
+		}
+		pixels = buf
+
+	case *image.Gray:
+		pmap, stype = "I", PIXEL_CHAR
+		pixels = packRows(src.Pix, src.PixOffset(bounds.Min.X, bounds.Min.Y), src.Stride, width, height)
+
+	case *image.Gray16:
+		pmap, stype = "I", PIXEL_SHORT
+		buf := make([]int16, 0, width*height)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			row := src.Pix[src.PixOffset(bounds.Min.X, y):]
+			for x := 0; x < width; x++ {
+				buf = append(buf, int16(uint16(row[2*x])<<8|uint16(row[2*x+1])))
+			}
+		}
+		pixels = buf
+
+	case *image.YCbCr:
+		pmap, stype = "RGB", PIXEL_CHAR
+		buf := make([]byte, 0, width*height*3)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				yi, ci := src.YOffset(x, y), src.COffset(x, y)
+				r, g, b := color.YCbCrToRGB(src.Y[yi], src.Cb[ci], src.Cr[ci])
+				buf = append(buf, r, g, b)
+			}
+		}
+		pixels = buf
+
+	case *image.CMYK:
+		pmap, stype = "CMYK", PIXEL_CHAR
+		pixels = packRows(src.Pix, src.PixOffset(bounds.Min.X, bounds.Min.Y), src.Stride, width*4, height)
+
+	default:
+		nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+		pmap, stype = "RGBA", PIXEL_CHAR
+		pixels = nrgba.Pix
+	}
+
+	depth := uint(8)
+	if stype == PIXEL_SHORT {
+		depth = 16
+	}
+
+	mw := NewMagickWand()
+	if err := mw.ConstituteImage(uint(width), uint(height), pmap, stype, pixels); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	if err := mw.SetImageDepth(depth); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Returns rows of rowLen bytes starting at offset as one contiguous slice,
+// avoiding a copy when the rows are already contiguous.
+func packRows(pix []byte, offset, stride, rowLen, rows int) []byte {
+	if stride == rowLen {
+		return pix[offset : offset+rowLen*rows]
+	}
+	buf := make([]byte, 0, rowLen*rows)
+	for y := 0; y < rows; y++ {
+		start := offset + y*stride
+		buf = append(buf, pix[start:start+rowLen]...)
+	}
+	return buf
+}
+
+// Converts a straight alpha 8-bit color value to premultiplied alpha
+func premultiply(c, a uint8) uint8 {
+	return uint8((uint32(c)*uint32(a) + 0x7f) / 0xff)
+}
+
+// Converts a premultiplied 8-bit color value to straight alpha
+func unpremultiply(c, a uint8) uint8 {
+	if a == 0 {
+		return 0
+	}
+	return uint8((uint32(c)*0xff + uint32(a)/2) / uint32(a))
+}
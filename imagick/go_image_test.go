@@ -0,0 +1,207 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(buf)
+	return buf
+}
+
+func TestGoImageRoundTrip(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const w, h = 37, 23
+	rect := image.Rect(0, 0, w, h)
+
+	rgba := image.NewRGBA(rect)
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		// Keep premultiplied colors valid: c <= a
+		a := uint8(i * 7)
+		rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], rgba.Pix[i+3] = a/2, a/3, a, a
+	}
+	ycbcr := image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+	copy(ycbcr.Y, randomBytes(len(ycbcr.Y)))
+	copy(ycbcr.Cb, randomBytes(len(ycbcr.Cb)+1))
+	copy(ycbcr.Cr, randomBytes(len(ycbcr.Cr)+2))
+
+	tests := []struct {
+		name string
+		img  image.Image
+		tol  uint32
+	}{
+		{"NRGBA", &image.NRGBA{Pix: randomBytes(w * h * 4), Stride: w * 4, Rect: rect}, 0},
+		{"RGBA", rgba, 0x101},
+		{"Gray", &image.Gray{Pix: randomBytes(w * h), Stride: w, Rect: rect}, 0},
+		{"Gray16", &image.Gray16{Pix: randomBytes(w * h * 2), Stride: w * 2, Rect: rect}, 0},
+		{"YCbCr", ycbcr, 0x101},
+		{"CMYK", &image.CMYK{Pix: randomBytes(w * h * 4), Stride: w * 4, Rect: rect}, 2 * 0x101},
+		{"SubImage", (&image.NRGBA{Pix: randomBytes(w * h * 4), Stride: w * 4, Rect: rect}).SubImage(image.Rect(3, 4, 20, 21)), 0},
+	}
+
+	for _, tt := range tests {
+		mw, err := NewMagickWandFromGoImage(tt.img)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		out, err := mw.ToImage()
+		mw.Destroy()
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+
+		b := tt.img.Bounds()
+		if out.Bounds().Dx() != b.Dx() || out.Bounds().Dy() != b.Dy() {
+			t.Errorf("%s: expected size %v, got %v", tt.name, b.Size(), out.Bounds().Size())
+			continue
+		}
+
+	compare:
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				want := color.NRGBA64Model.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA64)
+				got := color.NRGBA64Model.Convert(out.At(x, y)).(color.NRGBA64)
+				if !within(want.R, got.R, tt.tol) || !within(want.G, got.G, tt.tol) ||
+					!within(want.B, got.B, tt.tol) || !within(want.A, got.A, tt.tol) {
+					t.Errorf("%s: pixel (%d,%d) expected %v, got %v", tt.name, x, y, want, got)
+					break compare
+				}
+			}
+		}
+	}
+}
+
+func TestExportToGoImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const width, height = 7, 5
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(width, height, "RGBA", PIXEL_CHAR, randomBytes(width*height*4)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A padded stride forces the row by row export
+	rect := image.Rect(0, 0, width, height)
+	padded := image.Rect(0, 0, width+3, height)
+	rgba := image.NewRGBA(padded).SubImage(rect).(*image.RGBA)
+	if err := mw.ExportToRGBA(rgba); err != nil {
+		t.Fatal(err)
+	}
+	nrgba := image.NewNRGBA(rect)
+	if err := mw.ExportToNRGBA(nrgba); err != nil {
+		t.Fatal(err)
+	}
+
+	to8 := func(v float64) uint8 { return uint8(v*255 + 0.5) }
+	for _, p := range []image.Point{{0, 0}, {6, 0}, {3, 2}, {0, 4}, {6, 4}} {
+		pw, err := mw.GetImagePixelColor(p.X, p.Y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := color.NRGBA{to8(pw.GetRed()), to8(pw.GetGreen()), to8(pw.GetBlue()), to8(pw.GetAlpha())}
+		pw.Destroy()
+
+		if got := nrgba.NRGBAAt(p.X, p.Y); got != want {
+			t.Errorf("NRGBA at %v: got %v, want %v", p, got, want)
+		}
+		wantRGBA := color.RGBAModel.Convert(want).(color.RGBA)
+		got := rgba.RGBAAt(p.X, p.Y)
+		for i, pair := range [][2]uint8{{got.R, wantRGBA.R}, {got.G, wantRGBA.G}, {got.B, wantRGBA.B}, {got.A, wantRGBA.A}} {
+			if !within(uint16(pair[0]), uint16(pair[1]), 1) {
+				t.Errorf("RGBA at %v, component %d: got %v, want %v", p, i, got, wantRGBA)
+				break
+			}
+		}
+	}
+
+	gray := image.NewGray(padded).SubImage(rect).(*image.Gray)
+	if err := mw.ExportToGray(gray); err != nil {
+		t.Fatal(err)
+	}
+	val, err := mw.ExportImagePixels(0, 0, width, height, "I", PIXEL_CHAR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intensity := val.([]byte)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if got, want := gray.GrayAt(x, y).Y, intensity[y*width+x]; got != want {
+				t.Fatalf("Gray at (%d,%d): got %d, want %d", x, y, got, want)
+			}
+		}
+	}
+
+	if err := mw.ExportToNRGBA(image.NewNRGBA(image.Rect(0, 0, width, height+1))); err == nil {
+		t.Fatal("Expected an error exporting into bounds of a different size")
+	}
+}
+
+func within(a, b uint16, tol uint32) bool {
+	if a > b {
+		a, b = b, a
+	}
+	return uint32(b-a) <= tol
+}
+
+func BenchmarkToImage(b *testing.B) {
+	wand := NewMagickWand()
+	wand.ReadImage("logo:")
+	wand.ScaleImage(1024, 1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := wand.ToImage(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExportToRGBA(b *testing.B) {
+	wand := NewMagickWand()
+	wand.ReadImage("logo:")
+	wand.ScaleImage(1024, 1024)
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 1024))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := wand.ExportToRGBA(img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToImageManual(b *testing.B) {
+	wand := NewMagickWand()
+	wand.ReadImage("logo:")
+	wand.ScaleImage(1024, 1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		val, err := wand.ExportImagePixels(0, 0, 1024, 1024, "RGBA", PIXEL_CHAR)
+		if err != nil {
+			b.Fatal(err)
+		}
+		img := image.NewNRGBA(image.Rect(0, 0, 1024, 1024))
+		copy(img.Pix, val.([]byte))
+	}
+}
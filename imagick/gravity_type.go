@@ -9,6 +9,11 @@ package imagick
 */
 import "C"
 
+import (
+	"errors"
+	"fmt"
+)
+
 type GravityType int
 
 const (
@@ -25,3 +30,67 @@ const (
 	GRAVITY_SOUTH_EAST GravityType = C.SouthEastGravity
 	GRAVITY_STATIC     GravityType = C.StaticGravity
 )
+
+// Returns the offset of an inner box of width x height placed within an
+// outer box of outerWidth x outerHeight according to gravity. Offsets are
+// negative if the inner box is larger. Centering halves both sizes before
+// subtracting, the same rounding ImageMagick's GravityAdjustGeometry uses.
+func gravityOffset(outerWidth, outerHeight, width, height uint, gravity GravityType) (x, y int) {
+	switch gravity {
+	case GRAVITY_NORTH, GRAVITY_CENTER, GRAVITY_SOUTH:
+		x = int(outerWidth/2) - int(width/2)
+	case GRAVITY_NORTH_EAST, GRAVITY_EAST, GRAVITY_SOUTH_EAST:
+		x = int(outerWidth) - int(width)
+	}
+	switch gravity {
+	case GRAVITY_WEST, GRAVITY_CENTER, GRAVITY_EAST:
+		y = int(outerHeight/2) - int(height/2)
+	case GRAVITY_SOUTH_WEST, GRAVITY_SOUTH, GRAVITY_SOUTH_EAST:
+		y = int(outerHeight) - int(height)
+	}
+	return
+}
+
+// Returns the offset of an inner box placed by gravity and then moved by a
+// margin of offsetX, offsetY towards the center, the way ImageMagick applies
+// "-gravity SouthEast -geometry +10+10": the offset moves east and south
+// anchored boxes left and up.
+func gravityOffsetMargin(outerWidth, outerHeight, width, height uint, gravity GravityType, offsetX, offsetY int) (x, y int) {
+	x, y = gravityOffset(outerWidth, outerHeight, width, height, gravity)
+	switch gravity {
+	case GRAVITY_NORTH_EAST, GRAVITY_EAST, GRAVITY_SOUTH_EAST:
+		x -= offsetX
+	default:
+		x += offsetX
+	}
+	switch gravity {
+	case GRAVITY_SOUTH_WEST, GRAVITY_SOUTH, GRAVITY_SOUTH_EAST:
+		y -= offsetY
+	default:
+		y += offsetY
+	}
+	return
+}
+
+// Checks the size of a band added or removed at the side of an image given
+// by gravity: north and south bands have a height only, east and west bands
+// a width only.
+func checkBandGravity(width, height uint, gravity GravityType) error {
+	switch gravity {
+	case GRAVITY_NORTH, GRAVITY_SOUTH:
+		if width != 0 {
+			return fmt.Errorf("a north or south band has a height only, got a width of %d", width)
+		}
+	case GRAVITY_EAST, GRAVITY_WEST:
+		if height != 0 {
+			return fmt.Errorf("an east or west band has a width only, got a height of %d", height)
+		}
+	case GRAVITY_NORTH_WEST, GRAVITY_NORTH_EAST, GRAVITY_CENTER, GRAVITY_SOUTH_WEST, GRAVITY_SOUTH_EAST:
+	default:
+		return fmt.Errorf("unsupported gravity %d", gravity)
+	}
+	if width == 0 && height == 0 {
+		return errors.New("the band is empty")
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Returns a new wand holding the identity Hald CLUT of the given order, an
+// image of order^3 x order^3 pixels. Color corrections applied to it can be
+// replayed on other images with HaldClutImage. order must be between 2 and
+// 16.
+func NewHaldImage(order uint) (*MagickWand, error) {
+	if order < 2 || order > 16 {
+		return nil, fmt.Errorf("NewHaldImage: order must be between 2 and 16, got %d", order)
+	}
+	mw := NewMagickWand()
+	if err := mw.ReadImage(fmt.Sprintf("hald:%d", order)); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Parses an Adobe .cube 3D LUT and returns it as a Hald CLUT for use with
+// HaldClutImage. LUT sizes which are not a square, e.g. 17 or 33, are
+// resampled with trilinear interpolation to the next larger Hald order.
+// 1D LUTs are not supported.
+func LoadCubeLUT(r io.Reader) (*MagickWand, error) {
+	lut, err := parseCubeLUT(r)
+	if err != nil {
+		return nil, err
+	}
+
+	order := uint(math.Ceil(math.Sqrt(float64(lut.size))))
+	if order < 2 {
+		order = 2
+	}
+	if order > 16 {
+		order = 16
+	}
+	nodes := int(order * order)
+	side := order * order * order
+
+	pixels := make([]float32, 0, nodes*nodes*nodes*3)
+	for b := 0; b < nodes; b++ {
+		for g := 0; g < nodes; g++ {
+			for r := 0; r < nodes; r++ {
+				rgb := lut.lookup(
+					float64(r)/float64(nodes-1),
+					float64(g)/float64(nodes-1),
+					float64(b)/float64(nodes-1))
+				for _, v := range rgb {
+					pixels = append(pixels, float32(math.Max(0, math.Min(1, v))))
+				}
+			}
+		}
+	}
+
+	mw := NewMagickWand()
+	if err := mw.ConstituteImage(side, side, "RGB", PIXEL_FLOAT, pixels); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// cubeLUT is a parsed .cube 3D LUT, red changing fastest
+type cubeLUT struct {
+	size                 int
+	domainMin, domainMax [3]float64
+	table                [][3]float64
+}
+
+func parseCubeLUT(r io.Reader) (*cubeLUT, error) {
+	lut := &cubeLUT{domainMax: [3]float64{1, 1, 1}}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("LoadCubeLUT: line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "TITLE":
+			continue
+		case "LUT_1D_SIZE":
+			return nil, errorf("1D LUTs are not supported")
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, errorf("expected LUT_3D_SIZE N")
+			}
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < 2 || size > 256 {
+				return nil, errorf("LUT_3D_SIZE must be between 2 and 256, got %s", fields[1])
+			}
+			lut.size = size
+			lut.table = make([][3]float64, 0, size*size*size)
+			continue
+		case "DOMAIN_MIN", "DOMAIN_MAX":
+			triple, err := parseTriple(fields[1:])
+			if err != nil {
+				return nil, errorf("%s: %s", fields[0], err)
+			}
+			if fields[0] == "DOMAIN_MIN" {
+				lut.domainMin = triple
+			} else {
+				lut.domainMax = triple
+			}
+			continue
+		}
+
+		if fields[0][0] >= 'A' && fields[0][0] <= 'Z' {
+			// Unknown keywords, e.g. LUT_3D_INPUT_RANGE, are skipped
+			continue
+		}
+		if lut.size == 0 {
+			return nil, errorf("table data before LUT_3D_SIZE")
+		}
+		if len(lut.table) == cap(lut.table) {
+			return nil, errorf("more than %d table entries", cap(lut.table))
+		}
+		triple, err := parseTriple(fields)
+		if err != nil {
+			return nil, errorf("%s", err)
+		}
+		lut.table = append(lut.table, triple)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("LoadCubeLUT: %s", err)
+	}
+
+	if lut.size == 0 {
+		return nil, errorf("missing LUT_3D_SIZE")
+	}
+	if len(lut.table) != cap(lut.table) {
+		return nil, errorf("expected %d table entries, got %d", cap(lut.table), len(lut.table))
+	}
+	for i := range lut.domainMin {
+		if lut.domainMax[i] <= lut.domainMin[i] {
+			return nil, errorf("DOMAIN_MAX must be above DOMAIN_MIN")
+		}
+	}
+	return lut, nil
+}
+
+func parseTriple(fields []string) ([3]float64, error) {
+	var triple [3]float64
+	if len(fields) != 3 {
+		return triple, fmt.Errorf("expected 3 values, got %d", len(fields))
+	}
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return triple, fmt.Errorf("invalid value %q", field)
+		}
+		triple[i] = v
+	}
+	return triple, nil
+}
+
+// Returns the output color for an input color, interpolating trilinearly
+// between the table entries.
+func (lut *cubeLUT) lookup(r, g, b float64) [3]float64 {
+	var index [3]int
+	var frac [3]float64
+	for i, v := range [3]float64{r, g, b} {
+		pos := (v - lut.domainMin[i]) / (lut.domainMax[i] - lut.domainMin[i]) * float64(lut.size-1)
+		pos = math.Max(0, math.Min(float64(lut.size-1), pos))
+		index[i] = int(pos)
+		if index[i] == lut.size-1 {
+			index[i]--
+		}
+		frac[i] = pos - float64(index[i])
+	}
+
+	var out [3]float64
+	for corner := 0; corner < 8; corner++ {
+		weight := 1.0
+		var at [3]int
+		for i := range at {
+			at[i] = index[i]
+			if corner&(1<<uint(i)) != 0 {
+				at[i]++
+				weight *= frac[i]
+			} else {
+				weight *= 1 - frac[i]
+			}
+		}
+		entry := lut.table[(at[2]*lut.size+at[1])*lut.size+at[0]]
+		for i := range out {
+			out[i] += weight * entry[i]
+		}
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"strings"
+	"testing"
+)
+
+const invertCube = `# Inverts all channels
+TITLE "Invert"
+LUT_3D_SIZE 2
+DOMAIN_MIN 0 0 0
+DOMAIN_MAX 1 1 1
+1 1 1
+0 1 1
+1 0 1
+0 0 1
+1 1 0
+0 1 0
+1 0 0
+0 0 0
+`
+
+func TestNewHaldImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	hald, err := NewHaldImage(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hald.Destroy()
+	if w, h := hald.GetImageWidth(), hald.GetImageHeight(); w != 512 || h != 512 {
+		t.Fatalf("Expected 512x512, got %dx%d", w, h)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	original, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer original.Destroy()
+	if err := mw.HaldClutImage(hald); err != nil {
+		t.Fatal(err)
+	}
+	diff, distortion, err := original.CompareImages(mw, METRIC_ROOT_MEAN_SQUARED_ERROR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff.Destroy()
+	if distortion > 0.01 {
+		t.Fatalf("Expected the identity CLUT to keep the image, got an error of %f", distortion)
+	}
+
+	if _, err := NewHaldImage(1); err == nil {
+		t.Fatal("Expected an error for order 1")
+	}
+}
+
+func TestLoadCubeLUT(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	clut, err := LoadCubeLUT(strings.NewReader(invertCube))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clut.Destroy()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.SetSize(4, 64); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("gradient:"); err != nil {
+		t.Fatal(err)
+	}
+	original, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer original.Destroy()
+	if err := mw.HaldClutImage(clut); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < 64; y += 7 {
+		before, err := original.GetImagePixelColor(1, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		after, err := mw.GetImagePixelColor(1, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := after.GetRed() - (1 - before.GetRed()); diff < -0.01 || diff > 0.01 {
+			t.Errorf("Row %d: expected %f, got %f", y, 1-before.GetRed(), after.GetRed())
+		}
+		before.Destroy()
+		after.Destroy()
+	}
+}
+
+func TestLoadCubeLUTErrors(t *testing.T) {
+	tests := []struct {
+		cube string
+		line string
+	}{
+		{"LUT_3D_SIZE 1\n", "line 1:"},
+		{"LUT_3D_SIZE 2\n0 0 0\n1 1\n", "line 3:"},
+		{"0 0 0\n", "line 1:"},
+		{"# comment\nLUT_3D_SIZE 2\n0 0 0\n", "line 3:"},
+		{"LUT_3D_SIZE 2\n" + strings.Repeat("0 0 0\n", 9), "line 10:"},
+		{"LUT_1D_SIZE 16\n", "line 1:"},
+		{"LUT_3D_SIZE 2\nDOMAIN_MIN 0 0 x\n", "line 2:"},
+	}
+	for _, tt := range tests {
+		_, err := parseCubeLUT(strings.NewReader(tt.cube))
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.cube)
+		} else if !strings.Contains(err.Error(), tt.line) {
+			t.Errorf("%q: expected the error to point at %s got %q", tt.cube, tt.line, err)
+		}
+	}
+
+	lut, err := parseCubeLUT(strings.NewReader(invertCube))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := lut.lookup(0.25, 0.5, 1); out != [3]float64{0.75, 0.5, 0} {
+		t.Fatalf("Expected the inverted color, got %v", out)
+	}
+}
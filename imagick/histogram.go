@@ -0,0 +1,128 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Number of colors DominantColors quantizes to at least
+const dominantColorsQuantize = 16
+
+// HistogramOptions tunes HistogramMapWithOptions and
+// DominantColorsWithOptions, the zero value selects the defaults
+type HistogramOptions struct {
+	// Count fully transparent pixels, which are left out by default
+	IncludeTransparent bool
+}
+
+// Returns the colors of the current image as hex strings, "#RRGGBB" or
+// "#RRGGBBAA" for translucent colors, mapped to their pixel counts. If the
+// image has more than maxColors colors, the histogram of a copy quantized
+// to maxColors is returned, maxColors 0 disables this. Fully transparent
+// pixels are left out, see HistogramMapWithOptions.
+func (mw *MagickWand) HistogramMap(maxColors uint) (map[string]uint64, error) {
+	return mw.HistogramMapWithOptions(maxColors, nil)
+}
+
+// Like HistogramMap, with options. opts may be nil.
+func (mw *MagickWand) HistogramMapWithOptions(maxColors uint, opts *HistogramOptions) (map[string]uint64, error) {
+	if opts == nil {
+		opts = &HistogramOptions{}
+	}
+	if mw.GetImageWidth() == 0 {
+		return nil, errors.New("HistogramMap: wand has no image")
+	}
+	source := mw
+	if maxColors > 0 && mw.GetImageColors() > maxColors {
+		quantized, err := mw.currentImage()
+		if err != nil {
+			return nil, err
+		}
+		defer quantized.Destroy()
+		if err := quantized.QuantizeImage(maxColors, COLORSPACE_SRGB, 0, false, false); err != nil {
+			return nil, err
+		}
+		source = quantized
+	}
+
+	_, pws := source.GetImageHistogram()
+	if pws == nil {
+		if err := source.GetLastError(); err != nil {
+			return nil, err
+		}
+	}
+	histogram := make(map[string]uint64, len(pws))
+	for _, pw := range pws {
+		alpha := pw.GetAlpha()
+		if alpha > 0 || opts.IncludeTransparent {
+			// Colors which differ below 8 bits share an entry
+			histogram[hexColor(pw.GetRed(), pw.GetGreen(), pw.GetBlue(), alpha)] += uint64(pw.GetColorCount())
+		}
+		pw.Destroy()
+	}
+	return histogram, nil
+}
+
+// Returns up to n colors covering most of the current image, most frequent
+// first. The image is quantized to n colors, but at least 16, so that
+// similar shades count as one color. Fully transparent pixels are left out,
+// see DominantColorsWithOptions. The color count of each pixel wand is its
+// number of pixels, see GetColorCount. The caller destroys the pixel wands.
+func (mw *MagickWand) DominantColors(n uint) ([]*PixelWand, error) {
+	return mw.DominantColorsWithOptions(n, nil)
+}
+
+// Like DominantColors, with options. opts may be nil.
+func (mw *MagickWand) DominantColorsWithOptions(n uint, opts *HistogramOptions) ([]*PixelWand, error) {
+	if n == 0 {
+		return nil, errors.New("DominantColors: n must not be 0")
+	}
+	histogram, err := mw.HistogramMapWithOptions(maxUint(n, dominantColorsQuantize), opts)
+	if err != nil {
+		return nil, err
+	}
+	colors := make([]string, 0, len(histogram))
+	for c := range histogram {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if histogram[colors[i]] != histogram[colors[j]] {
+			return histogram[colors[i]] > histogram[colors[j]]
+		}
+		return colors[i] < colors[j]
+	})
+	if uint(len(colors)) > n {
+		colors = colors[:n]
+	}
+
+	pws := make([]*PixelWand, len(colors))
+	for i, c := range colors {
+		pws[i] = NewPixelWand()
+		if !pws[i].SetColor(c) {
+			for _, pw := range pws[:i+1] {
+				pw.Destroy()
+			}
+			return nil, fmt.Errorf("DominantColors: invalid color %s", c)
+		}
+		pws[i].SetColorCount(uint(histogram[c]))
+	}
+	return pws, nil
+}
+
+// Returns "#RRGGBB", or "#RRGGBBAA" if alpha is below 1, of color
+// components between 0 and 1
+func hexColor(r, g, b, alpha float64) string {
+	byte8 := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	if a := byte8(alpha); a < 255 {
+		return fmt.Sprintf("#%02X%02X%02X%02X", byte8(r), byte8(g), byte8(b), a)
+	}
+	return fmt.Sprintf("#%02X%02X%02X", byte8(r), byte8(g), byte8(b))
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistogramMap(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[100x100]"); err != nil {
+		t.Fatal(err)
+	}
+	blue := NewMagickWand()
+	defer blue.Destroy()
+	if err := blue.ReadImage("xc:blue[50x100]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.CompositeImage(blue, COMPOSITE_OP_COPY, 50, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	histogram, err := mw.HistogramMap(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]uint64{"#FF0000": 5000, "#0000FF": 5000}; !reflect.DeepEqual(histogram, want) {
+		t.Errorf("Expected %v, got %v", want, histogram)
+	}
+
+	// Transparent pixels only count if asked for
+	if err := mw.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		t.Fatal(err)
+	}
+	clear := NewMagickWand()
+	defer clear.Destroy()
+	if err := clear.ReadImage("xc:none[50x100]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.CompositeImage(clear, COMPOSITE_OP_COPY, 50, 0); err != nil {
+		t.Fatal(err)
+	}
+	if histogram, err = mw.HistogramMap(256); err != nil || len(histogram) != 1 || histogram["#FF0000"] != 5000 {
+		t.Errorf("Expected only the red pixels, got %v (%v)", histogram, err)
+	}
+	if histogram, err = mw.HistogramMapWithOptions(256, &HistogramOptions{IncludeTransparent: true}); err != nil || len(histogram) != 2 {
+		t.Errorf("Expected the transparent pixels too, got %v (%v)", histogram, err)
+	}
+
+	rose := NewMagickWand()
+	defer rose.Destroy()
+	if err := rose.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if histogram, err = rose.HistogramMap(8); err != nil || len(histogram) > 8 {
+		t.Errorf("Expected at most 8 colors, got %d (%v)", len(histogram), err)
+	}
+	colors, err := rose.DominantColors(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colors) != 1 {
+		t.Fatalf("Expected 1 color, got %d", len(colors))
+	}
+	if c := colors[0]; c.GetRed() <= c.GetGreen() || c.GetRed() <= c.GetBlue() || c.GetColorCount() == 0 {
+		t.Errorf("Expected a reddish color, got %s", c.GetColorAsString())
+	}
+	colors[0].Destroy()
+}
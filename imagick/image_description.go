@@ -0,0 +1,77 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+)
+
+// ImageDescription holds the attributes of an image, a typed alternative to
+// parsing the text returned by IdentifyImage()
+type ImageDescription struct {
+	Format       string
+	Width        uint
+	Height       uint
+	Depth        uint
+	Colorspace   ColorspaceType
+	Type         ImageType
+	Compression  CompressionType
+	Quality      uint
+	XResolution  float64
+	YResolution  float64
+	Units        ResolutionType
+	Delay        uint
+	Iterations   uint
+	AlphaActive  bool
+	NumberColors uint
+	Signature    string
+	Page         Geometry // Virtual canvas size and offset, no flags are set
+	Frames       uint     // Number of images in the wand
+	Properties   map[string]string
+}
+
+// Returns the attributes of the current image
+func (mw *MagickWand) Describe() (*ImageDescription, error) {
+	if mw.GetNumberImages() == 0 {
+		return nil, errors.New("Describe: wand has no images")
+	}
+
+	xres, yres, err := mw.GetImageResolution()
+	if err != nil {
+		return nil, err
+	}
+	pw, ph, px, py, err := mw.GetImagePage()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ImageDescription{
+		Format:       mw.GetImageFormat(),
+		Width:        mw.GetImageWidth(),
+		Height:       mw.GetImageHeight(),
+		Depth:        mw.GetImageDepth(),
+		Colorspace:   mw.GetImageColorspace(),
+		Type:         mw.GetImageType(),
+		Compression:  mw.GetImageCompression(),
+		Quality:      mw.GetImageCompressionQuality(),
+		XResolution:  xres,
+		YResolution:  yres,
+		Units:        mw.GetImageUnits(),
+		Delay:        mw.GetImageDelay(),
+		Iterations:   mw.GetImageIterations(),
+		AlphaActive:  mw.GetImageAlphaChannel(),
+		NumberColors: mw.GetImageColors(),
+		Signature:    mw.GetImageSignature(),
+		Page:         Geometry{Width: pw, Height: ph, X: px, Y: py},
+		Frames:       mw.GetNumberImages(),
+		Properties:   map[string]string{},
+	}
+	for _, name := range mw.GetImageProperties("*") {
+		if value, err := mw.GetImageProperty(name); err == nil {
+			d.Properties[name] = value
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	logo := NewMagickWand()
+	defer logo.Destroy()
+	if err := logo.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logo.SetImageFormat("PNG"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logo.SetImageProperty("comment", "described"); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImageBlob(logo.GetImageBlob()); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := mw.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Format != "PNG" {
+		t.Errorf("Expected format PNG, got %q", d.Format)
+	}
+	if d.Width != 640 || d.Height != 480 {
+		t.Errorf("Expected 640x480, got %dx%d", d.Width, d.Height)
+	}
+	if d.Colorspace != COLORSPACE_SRGB {
+		t.Errorf("Expected colorspace sRGB, got %d", d.Colorspace)
+	}
+	if d.Depth != 8 {
+		t.Errorf("Expected depth 8, got %d", d.Depth)
+	}
+	if d.Frames != 1 {
+		t.Errorf("Expected 1 frame, got %d", d.Frames)
+	}
+	if d.Signature != mw.GetImageSignature() || d.Signature == "" {
+		t.Errorf("Expected signature %q, got %q", mw.GetImageSignature(), d.Signature)
+	}
+	if d.NumberColors == 0 {
+		t.Error("Expected a number of colors")
+	}
+	if d.Properties["comment"] != "described" {
+		t.Errorf("Expected the comment property, got %v", d.Properties)
+	}
+
+	if text := mw.IdentifyImage(); !strings.Contains(text, "640x480") {
+		t.Errorf("Expected IdentifyImage to mention the geometry, got %q", text)
+	}
+
+	empty := NewMagickWand()
+	defer empty.Destroy()
+	if _, err := empty.Describe(); err == nil {
+		t.Fatal("Expected an error describing an empty wand")
+	}
+}
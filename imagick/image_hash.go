@@ -0,0 +1,157 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+
+// GetImageChannelMoments was added in ImageMagick 6.8.8
+#if MagickLibVersion < 0x688
+#define HAVE_CHANNEL_MOMENTS 0
+typedef struct _ChannelMoments {
+	double I[32];
+	PointInfo centroid, ellipse_axis;
+	double ellipse_angle, ellipse_eccentricity, ellipse_intensity;
+} ChannelMoments;
+static ChannelMoments *GetImageChannelMoments(const Image *image,
+	ExceptionInfo *exception) {
+	return NULL;
+}
+#else
+#define HAVE_CHANNEL_MOMENTS 1
+#endif
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"runtime"
+	"strconv"
+	"unsafe"
+)
+
+// ChannelMoments holds the image moments of one channel, see
+// GetImageChannelMoments()
+type ChannelMoments struct {
+	Centroid            PointInfo
+	EllipseAxis         PointInfo
+	EllipseAngle        float64
+	EllipseEccentricity float64
+	EllipseIntensity    float64
+	// The Hu invariants I1 to I8, which do not change when the image is
+	// translated, scaled or rotated
+	Hu [8]float64
+}
+
+// Returns the moments of the red, green and blue channels of the current
+// image, and of the alpha and black channels if present, keyed by channel.
+// CHANNELS_COMPOSITE holds the moments of all channels combined. Returns an
+// error if ImageMagick is older than 6.8.8.
+func (mw *MagickWand) GetImageChannelMoments() (map[ChannelType]ChannelMoments, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if C.HAVE_CHANNEL_MOMENTS == 0 {
+		return nil, errors.New("GetImageChannelMoments: requires ImageMagick 6.8.8 or later")
+	}
+	if mw.GetNumberImages() == 0 {
+		return nil, errors.New("GetImageChannelMoments: wand has no image")
+	}
+
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+	mw.mu.Lock()
+	cmoments := C.GetImageChannelMoments(C.GetImageFromMagickWand(mw.mw), exc)
+	mw.mu.Unlock()
+	runtime.KeepAlive(mw)
+	if cmoments == nil {
+		if err := checkExceptionInfo(exc); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("GetImageChannelMoments: operation failed")
+	}
+	defer relinquishMemory(unsafe.Pointer(cmoments))
+
+	// The C array is indexed by channel, up to and including CompositeChannels
+	all := (*[C.CompositeChannels + 1]C.ChannelMoments)(unsafe.Pointer(cmoments))
+
+	channels := []ChannelType{CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE, CHANNELS_COMPOSITE}
+	if mw.GetImageAlphaChannel() {
+		channels = append(channels, CHANNEL_OPACITY)
+	}
+	if mw.GetImageColorspace() == COLORSPACE_CMYK {
+		channels = append(channels, CHANNEL_BLACK)
+	}
+
+	moments := make(map[ChannelType]ChannelMoments, len(channels))
+	for _, channel := range channels {
+		cm := &all[channel]
+		m := ChannelMoments{
+			Centroid:            PointInfo{float64(cm.centroid.x), float64(cm.centroid.y)},
+			EllipseAxis:         PointInfo{float64(cm.ellipse_axis.x), float64(cm.ellipse_axis.y)},
+			EllipseAngle:        float64(cm.ellipse_angle),
+			EllipseEccentricity: float64(cm.ellipse_eccentricity),
+			EllipseIntensity:    float64(cm.ellipse_intensity),
+		}
+		for i := range m.Hu {
+			m.Hu[i] = float64(cm.I[i])
+		}
+		moments[channel] = m
+	}
+	return moments, nil
+}
+
+// Returns a 64 bit difference hash (dHash) of the current image as 16 hex
+// digits. The hash survives scaling, recompression and small color changes,
+// compare hashes with HammingDistance: a distance up to about 10 means the
+// images are likely the same.
+//
+// The hash is computed in Go from a 9x8 grayscale thumbnail, so it is the
+// same for every ImageMagick version. Each bit tells whether a pixel is
+// brighter than its right neighbour.
+func (mw *MagickWand) PerceptualHash() (string, error) {
+	thumb, err := mw.currentImage()
+	if err != nil {
+		return "", err
+	}
+	defer thumb.Destroy()
+	if err := thumb.ResizeImage(9, 8, FILTER_BOX, 1); err != nil {
+		return "", err
+	}
+	var pixels [9 * 8]byte
+	if err := thumb.exportPixels(0, 0, 9, 8, "I", PIXEL_CHAR, unsafe.Pointer(&pixels[0])); err != nil {
+		return "", err
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// Returns the number of differing bits of two hashes returned by
+// PerceptualHash.
+func HammingDistance(hash1, hash2 string) (int, error) {
+	if len(hash1) != 16 || len(hash2) != 16 {
+		return 0, errors.New("HammingDistance: hashes must be 16 hex digits")
+	}
+	h1, err := strconv.ParseUint(hash1, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("HammingDistance: %s", err)
+	}
+	h2, err := strconv.ParseUint(hash2, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("HammingDistance: %s", err)
+	}
+	return bits.OnesCount64(h1 ^ h2), nil
+}
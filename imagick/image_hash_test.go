@@ -0,0 +1,102 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestPerceptualHash(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	hash := func(mw *MagickWand) string {
+		h, err := mw.PerceptualHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	logo := NewMagickWand()
+	defer logo.Destroy()
+	if err := logo.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logo.SetImageCompressionQuality(40); err != nil {
+		t.Fatal(err)
+	}
+	recompressed := encodeDecode(t, logo, "JPEG")
+	defer recompressed.Destroy()
+	if err := recompressed.ResizeImage(320, 240, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	rose := NewMagickWand()
+	defer rose.Destroy()
+	if err := rose.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+
+	if d, err := HammingDistance(hash(logo), hash(recompressed)); err != nil || d > 6 {
+		t.Errorf("Expected a small distance to the recompressed copy, got %d (%v)", d, err)
+	}
+	if d, err := HammingDistance(hash(logo), hash(rose)); err != nil || d < 16 {
+		t.Errorf("Expected a large distance between different images, got %d (%v)", d, err)
+	}
+
+	if _, err := HammingDistance("0123", "0123456789abcdef"); err == nil {
+		t.Error("Expected an error for a short hash")
+	}
+	if _, err := HammingDistance("0123456789abcdeg", "0123456789abcdef"); err == nil {
+		t.Error("Expected an error for a hash which is not hex")
+	}
+	if d, _ := HammingDistance("00000000000000ff", "000000000000000f"); d != 4 {
+		t.Errorf("Expected a distance of 4, got %d", d)
+	}
+}
+
+func TestGetImageChannelMoments(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	moments, err := mw.GetImageChannelMoments()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, channel := range []ChannelType{CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE, CHANNELS_COMPOSITE} {
+		m, ok := moments[channel]
+		if !ok {
+			t.Fatalf("Expected moments for channel %d", channel)
+		}
+		if m.Centroid.X <= 0 || m.Centroid.X >= 70 || m.Centroid.Y <= 0 || m.Centroid.Y >= 46 {
+			t.Errorf("Channel %d: expected the centroid within the image, got %+v", channel, m.Centroid)
+		}
+	}
+
+	// Hu invariants do not change when the image is flipped
+	if err := mw.FlopImage(); err != nil {
+		t.Fatal(err)
+	}
+	flopped, err := mw.GetImageChannelMoments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := moments[CHANNEL_RED].Hu[0], flopped[CHANNEL_RED].Hu[0]
+	if d := a - b; d < -1e-6 || d > 1e-6 {
+		t.Errorf("Expected I1 to be kept by a flop, got %g and %g", a, b)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// MaskType selects one of the masks of an image, see SetImageMask
+type MaskType int
+
+const (
+	// Limits which pixels operations read. ImageMagick 6 has no read masks,
+	// the clip mask is used instead, which also limits the pixels written.
+	MASK_READ MaskType = iota
+	// Limits which pixels operations write, grayscale values blend the
+	// result with the original pixels
+	MASK_WRITE
+)
+
+// Sets a mask of the current image limiting which pixels subsequent
+// operations such as BlurImage change. The mask is the current image of
+// mask, a grayscale image of the same size. As in ImageMagick 6, pixels
+// where the mask is black are changed and pixels where it is white are
+// protected. A nil mask removes the mask.
+//
+// ImageMagick 6 only has write masks, the clip mask, see SetImageClipMask,
+// serves as the MASK_READ mask. Unlike a write mask it is not blended:
+// pixels where the mask is lighter than mid gray are protected.
+func (mw *MagickWand) SetImageMask(maskType MaskType, mask *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if maskType != MASK_READ && maskType != MASK_WRITE {
+		return fmt.Errorf("SetImageMask: invalid mask type %d", maskType)
+	}
+	if mask != nil {
+		if err := mask.valid(); err != nil {
+			return err
+		}
+		if mask.GetImageWidth() != mw.GetImageWidth() || mask.GetImageHeight() != mw.GetImageHeight() {
+			return errors.New("SetImageMask: mask and image sizes differ")
+		}
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	image := C.GetImageFromMagickWand(mw.mw)
+	if image == nil {
+		return errors.New("SetImageMask: wand has no image")
+	}
+	var cmask *C.Image
+	if mask != nil {
+		cmask = C.GetImageFromMagickWand(mask.mw)
+	}
+	var ok C.MagickBooleanType
+	if maskType == MASK_READ {
+		ok = C.SetImageClipMask(image, cmask)
+	} else {
+		ok = C.SetImageMask(image, cmask)
+	}
+	runtime.KeepAlive(mw)
+	runtime.KeepAlive(mask)
+	return mw.getLastErrorIfFailed(ok)
+}
+
+// Returns a new wand holding the mask of the current image, see
+// SetImageMask. Returns an error wrapping ErrNotFound if the image has no
+// such mask.
+func (mw *MagickWand) GetImageMask(maskType MaskType) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if maskType != MASK_READ && maskType != MASK_WRITE {
+		return nil, fmt.Errorf("GetImageMask: invalid mask type %d", maskType)
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	image := C.GetImageFromMagickWand(mw.mw)
+	if image == nil {
+		return nil, errors.New("GetImageMask: wand has no image")
+	}
+
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+	var cmask *C.Image
+	if maskType == MASK_READ {
+		cmask = C.GetImageClipMask(image, exc)
+	} else {
+		cmask = C.GetImageMask(image, exc)
+	}
+	runtime.KeepAlive(mw)
+	if cmask == nil {
+		return nil, fmt.Errorf("GetImageMask: %w", ErrNotFound)
+	}
+	defer C.DestroyImage(cmask)
+	cmw := C.NewMagickWandFromImage(cmask)
+	if cmw == nil {
+		return nil, errors.New("GetImageMask: could not create wand")
+	}
+	return newMagickWand(cmw), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetImageMask(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// Black on the left, so only the left half may change
+	mask := NewMagickWand()
+	defer mask.Destroy()
+	if err := mask.ReadImage("xc:white[200x100]"); err != nil {
+		t.Fatal(err)
+	}
+	black := NewMagickWand()
+	defer black.Destroy()
+	if err := black.ReadImage("xc:black[100x100]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mask.CompositeImage(black, COMPOSITE_OP_COPY, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, maskType := range []MaskType{MASK_READ, MASK_WRITE} {
+		mw := NewMagickWand()
+		if err := mw.ReadImage("pattern:checkerboard[200x100]"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mw.GetImageMask(maskType); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound without a mask, got %v", err)
+		}
+		original, err := mw.ExportImagePixels(0, 0, 200, 100, "RGB", PIXEL_SHORT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageMask(maskType, mask); err != nil {
+			t.Fatal(err)
+		}
+		got, err := mw.GetImageMask(maskType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetImageWidth() != 200 || got.GetImageHeight() != 100 {
+			t.Errorf("Expected a 200x100 mask, got %dx%d", got.GetImageWidth(), got.GetImageHeight())
+		}
+		got.Destroy()
+
+		if err := mw.BlurImage(0, 3); err != nil {
+			t.Fatal(err)
+		}
+		blurred, err := mw.ExportImagePixels(0, 0, 200, 100, "RGB", PIXEL_SHORT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, after := original.([]int16), blurred.([]int16)
+		changed := false
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 200; x++ {
+				for c := 0; c < 3; c++ {
+					i := (y*200+x)*3 + c
+					if x >= 100 && before[i] != after[i] {
+						t.Fatalf("Mask type %d: protected pixel %d,%d changed", maskType, x, y)
+					}
+					if x < 100 && before[i] != after[i] {
+						changed = true
+					}
+				}
+			}
+		}
+		if !changed {
+			t.Errorf("Mask type %d: expected pixels inside the mask to change", maskType)
+		}
+
+		if err := mw.SetImageMask(maskType, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mw.GetImageMask(maskType); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected the mask to be removed, got %v", err)
+		}
+		mw.Destroy()
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:white[10x10]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageMask(MASK_WRITE, mask); err == nil {
+		t.Error("Expected an error for a mask of a different size")
+	}
+}
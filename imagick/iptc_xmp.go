@@ -0,0 +1,248 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keys of common IPTC IIM datasets, as used by GetIPTC and SetIPTC. Other
+// datasets are keyed the same way, "record:dataset" in decimal.
+const (
+	IPTC_OBJECT_NAME    = "2:5"
+	IPTC_KEYWORDS       = "2:25"
+	IPTC_BYLINE         = "2:80"
+	IPTC_CITY           = "2:90"
+	IPTC_COUNTRY        = "2:101"
+	IPTC_HEADLINE       = "2:105"
+	IPTC_CREDIT         = "2:110"
+	IPTC_SOURCE         = "2:115"
+	IPTC_COPYRIGHT      = "2:116"
+	IPTC_CAPTION        = "2:120"
+	IPTC_CAPTION_WRITER = "2:122"
+)
+
+const (
+	iptcCodedCharacter   = "1:90"
+	iptcRecordVersion    = "2:0"
+	iptcTagMarker        = 0x1c
+	iptcResourceID       = 0x0404
+	photoshopSignature   = "Photoshop 3.0\x00"
+	photoshopResourceTag = "8BIM"
+)
+
+// The 1:90 value declaring UTF-8 text
+var iptcUTF8 = []byte{0x1b, '%', 'G'}
+
+// Returns the IPTC IIM datasets of the current image keyed by
+// "record:dataset", e.g. IPTC_KEYWORDS, with one value per dataset so that
+// repeated datasets such as keywords keep their order. Text is returned as
+// UTF-8, Latin-1 text is converted. The binary character set (1:90) and
+// record version (2:0) datasets are left out. Returns an error wrapping
+// ErrNotFound if the image has no IPTC profile.
+func (mw *MagickWand) GetIPTC() (map[string][]string, error) {
+	profile := mw.GetImageProfile("iptc")
+	if len(profile) == 0 {
+		profile = mw.GetImageProfile("8bim")
+	}
+	if len(profile) == 0 {
+		return nil, fmt.Errorf("GetIPTC: %w", ErrNotFound)
+	}
+	datasets, err := parseIPTC(profile)
+	if err != nil {
+		return nil, fmt.Errorf("GetIPTC: %s", err)
+	}
+	return datasets, nil
+}
+
+// Replaces the IPTC profile of the current image with the datasets, see
+// GetIPTC. Each value becomes one dataset, so every keyword is a separate
+// dataset. Text is written as UTF-8, declared by dataset 1:90.
+func (mw *MagickWand) SetIPTC(datasets map[string][]string) error {
+	profile, err := serializeIPTC(datasets)
+	if err != nil {
+		return fmt.Errorf("SetIPTC: %s", err)
+	}
+	return mw.SetImageProfile("iptc", profile)
+}
+
+// Returns the XMP packet of the current image, an XML document. Returns an
+// error wrapping ErrNotFound if the image has no XMP profile.
+func (mw *MagickWand) GetXMP() (string, error) {
+	profile := mw.GetImageProfile("xmp")
+	if len(profile) == 0 {
+		return "", fmt.Errorf("GetXMP: %w", ErrNotFound)
+	}
+	return string(profile), nil
+}
+
+// Replaces the XMP profile of the current image with packet, which must be
+// well-formed XML. It is stored as is.
+func (mw *MagickWand) SetXMP(packet string) error {
+	decoder := xml.NewDecoder(strings.NewReader(packet))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("SetXMP: %s", err)
+		}
+	}
+	return mw.SetImageProfile("xmp", []byte(packet))
+}
+
+// Parses IPTC IIM datasets. The datasets may be wrapped in Photoshop image
+// resources, as in JPEG APP13 segments.
+func parseIPTC(data []byte) (map[string][]string, error) {
+	data = bytes.TrimPrefix(data, []byte(photoshopSignature))
+	if bytes.HasPrefix(data, []byte(photoshopResourceTag)) {
+		var err error
+		if data, err = photoshopResource(data, iptcResourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	type dataset struct {
+		key   string
+		value []byte
+	}
+	var raw []dataset
+	utf8Text := false
+	for len(data) > 0 {
+		if data[0] != iptcTagMarker {
+			// Padding after the last dataset
+			if len(bytes.Trim(data, "\x00")) == 0 {
+				break
+			}
+			return nil, fmt.Errorf("invalid IPTC tag marker 0x%02x", data[0])
+		}
+		if len(data) < 5 {
+			return nil, errors.New("truncated IPTC dataset header")
+		}
+		key := fmt.Sprintf("%d:%d", data[1], data[2])
+		length := int(binary.BigEndian.Uint16(data[3:5]))
+		data = data[5:]
+		if length&0x8000 != 0 {
+			// Extended dataset, the length is in the next length&0x7fff bytes
+			n := length & 0x7fff
+			if n > 4 || len(data) < n {
+				return nil, errors.New("invalid IPTC extended length")
+			}
+			length = 0
+			for _, b := range data[:n] {
+				length = length<<8 | int(b)
+			}
+			data = data[n:]
+		}
+		if length > len(data) {
+			return nil, fmt.Errorf("IPTC dataset %s is truncated", key)
+		}
+		switch key {
+		case iptcCodedCharacter:
+			utf8Text = bytes.Equal(data[:length], iptcUTF8)
+		case iptcRecordVersion:
+		default:
+			raw = append(raw, dataset{key, data[:length]})
+		}
+		data = data[length:]
+	}
+
+	datasets := make(map[string][]string)
+	for _, ds := range raw {
+		value := string(ds.value)
+		if !utf8Text && !utf8.ValidString(value) {
+			value = latin1ToUTF8(ds.value)
+		}
+		datasets[ds.key] = append(datasets[ds.key], value)
+	}
+	return datasets, nil
+}
+
+// Returns the data of the Photoshop image resource with the given id
+func photoshopResource(data []byte, id uint16) ([]byte, error) {
+	for len(data) >= 12 && bytes.HasPrefix(data, []byte(photoshopResourceTag)) {
+		resource := binary.BigEndian.Uint16(data[4:6])
+		// Pascal string name, padded to an even size
+		nameLen := int(data[6]) + 1
+		nameLen += nameLen % 2
+		if len(data) < 6+nameLen+4 {
+			break
+		}
+		size := int(binary.BigEndian.Uint32(data[6+nameLen:]))
+		start := 6 + nameLen + 4
+		if size > len(data)-start {
+			return nil, errors.New("truncated Photoshop image resource")
+		}
+		if resource == id {
+			return data[start : start+size], nil
+		}
+		data = data[start+size+size%2:]
+	}
+	return nil, errors.New("no IPTC data in Photoshop image resources")
+}
+
+// Serializes datasets sorted by record and dataset, with the record version
+// 2:0 and the UTF-8 character set declaration 1:90 replacing any given.
+func serializeIPTC(datasets map[string][]string) ([]byte, error) {
+	type dataset struct {
+		record, number int
+		values         []string
+	}
+	all := []dataset{{1, 90, []string{string(iptcUTF8)}}, {2, 0, []string{"\x00\x04"}}}
+	for name, values := range datasets {
+		var record, number int
+		parts := strings.Split(name, ":")
+		if len(parts) == 2 {
+			r, err1 := strconv.Atoi(parts[0])
+			n, err2 := strconv.Atoi(parts[1])
+			if err1 == nil && err2 == nil && r >= 1 && r <= 255 && n >= 0 && n <= 255 {
+				record, number = r, n
+			}
+		}
+		if record == 0 {
+			return nil, fmt.Errorf("invalid IPTC key %q, expected record:dataset", name)
+		}
+		if record == 1 && number == 90 || record == 2 && number == 0 {
+			continue
+		}
+		all = append(all, dataset{record, number, values})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].record != all[j].record {
+			return all[i].record < all[j].record
+		}
+		return all[i].number < all[j].number
+	})
+
+	var buf bytes.Buffer
+	for _, ds := range all {
+		for _, value := range ds.values {
+			if len(value) > 0x7fff {
+				return nil, fmt.Errorf("IPTC dataset %d:%d is longer than %d bytes", ds.record, ds.number, 0x7fff)
+			}
+			buf.Write([]byte{iptcTagMarker, byte(ds.record), byte(ds.number), byte(len(value) >> 8), byte(len(value))})
+			buf.WriteString(value)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func latin1ToUTF8(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
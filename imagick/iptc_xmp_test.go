@@ -0,0 +1,132 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Returns an IPTC dataset
+func iptcDataset(record, dataset byte, value string) []byte {
+	return append([]byte{0x1c, record, dataset, byte(len(value) >> 8), byte(len(value))}, value...)
+}
+
+// Returns the values of one dataset, parsed independently of parseIPTC
+func iptcValues(t *testing.T, profile []byte, record, dataset byte) []string {
+	t.Helper()
+	var values []string
+	for len(profile) > 0 {
+		if len(profile) < 5 || profile[0] != 0x1c {
+			t.Fatalf("Invalid IPTC profile at % x", profile)
+		}
+		length := int(binary.BigEndian.Uint16(profile[3:5]))
+		if profile[1] == record && profile[2] == dataset {
+			values = append(values, string(profile[5:5+length]))
+		}
+		profile = profile[5+length:]
+	}
+	return values
+}
+
+func TestIPTC(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	var blob []byte
+	blob = append(blob, iptcDataset(2, 0, "\x00\x04")...)
+	blob = append(blob, iptcDataset(2, 5, "Title")...)
+	blob = append(blob, iptcDataset(2, 25, "alpha")...)
+	blob = append(blob, iptcDataset(2, 25, "beta")...)
+	blob = append(blob, iptcDataset(2, 120, "Caf\xe9")...) // Latin-1
+
+	// The same datasets wrapped in a Photoshop image resource
+	wrapped := append([]byte("Photoshop 3.0\x008BIM\x04\x04\x00\x00"), 0, 0, 0, byte(len(blob)))
+	wrapped = append(wrapped, blob...)
+	for _, profile := range [][]byte{blob, wrapped} {
+		datasets, err := parseIPTC(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string][]string{
+			IPTC_OBJECT_NAME: {"Title"},
+			IPTC_KEYWORDS:    {"alpha", "beta"},
+			IPTC_CAPTION:     {"Café"},
+		}
+		if !reflect.DeepEqual(datasets, want) {
+			t.Fatalf("Expected %q, got %q", want, datasets)
+		}
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.GetIPTC(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound without an IPTC profile, got %v", err)
+	}
+	if err := mw.SetImageProfile("iptc", blob); err != nil {
+		t.Fatal(err)
+	}
+	datasets, err := mw.GetIPTC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasets[IPTC_KEYWORDS] = append(datasets[IPTC_KEYWORDS], "gamma")
+	if err := mw.SetIPTC(datasets); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := mw.GetImageProfile("iptc")
+	if got := iptcValues(t, profile, 2, 25); !reflect.DeepEqual(got, []string{"alpha", "beta", "gamma"}) {
+		t.Errorf("Expected keywords alpha, beta and gamma, got %q", got)
+	}
+	if got := iptcValues(t, profile, 2, 120); !reflect.DeepEqual(got, []string{"Café"}) {
+		t.Errorf("Expected the caption as UTF-8, got %q", got)
+	}
+	if got := iptcValues(t, profile, 1, 90); !reflect.DeepEqual(got, []string{"\x1b%G"}) {
+		t.Errorf("Expected the UTF-8 character set declaration, got %q", got)
+	}
+
+	if err := mw.SetIPTC(map[string][]string{"keywords": {"x"}}); err == nil {
+		t.Error("Expected an error for a key which is not record:dataset")
+	}
+}
+
+func TestXMP(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.GetXMP(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound without an XMP profile, got %v", err)
+	}
+
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/" xmpRights:Marked="True"/>` +
+		`</rdf:RDF></x:xmpmeta>`
+	if err := mw.SetXMP(packet); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mw.GetXMP(); err != nil || got != packet {
+		t.Fatalf("Expected the XMP packet back, got %q (%v)", got, err)
+	}
+	if err := mw.SetXMP("<x:xmpmeta>"); err == nil {
+		t.Fatal("Expected an error for malformed XML")
+	}
+}
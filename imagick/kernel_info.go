@@ -20,7 +20,13 @@ extern void ScaleKernelInfo(KernelInfo *,const double,const GeometryFlags);
 import "C"
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -32,6 +38,7 @@ type KernelInfo struct {
 func newKernelInfo(cki *C.KernelInfo) *KernelInfo {
 	ki := &KernelInfo{info: cki}
 	runtime.SetFinalizer(ki, Destroy)
+	ki.IncreaseCount()
 	return ki
 }
 
@@ -42,9 +49,25 @@ func (ki *KernelInfo) Destroy() {
 		C.DestroyKernelInfo(ki.info)
 		runtime.SetFinalizer(ki, nil)
 		ki.info = nil
+
+		ki.DecreaseCount()
 	}
 }
 
+// Increase KernelInfo ref counter and set according "can`t be terminated status"
+func (ki *KernelInfo) IncreaseCount() {
+	atomic.AddInt64(&kernelInfoCounter, int64(1))
+	trackObject("KernelInfo", unsafe.Pointer(ki))
+	unsetCanTerminate()
+}
+
+// Decrease KernelInfo ref counter and set according "can be terminated status"
+func (ki *KernelInfo) DecreaseCount() {
+	atomic.AddInt64(&kernelInfoCounter, int64(-1))
+	untrackObject(unsafe.Pointer(ki))
+	setCanTerminate()
+}
+
 // Convert the current KernelInfo to an 2d-array of values. The values are either
 // a float64 if the element is used, or NaN if the element is not used by the kernel
 func (ki *KernelInfo) ToArray() [][]float64 {
@@ -72,7 +95,7 @@ into a Morphology/Convolution Kernel. This allows users to specify a kernel from
 of pre-defined kernels, or to fully specify their own kernel for a specific Convolution
 or Morphology Operation.
 
-KernelInfo will be nil if an error occured.
+An error is returned if the string can not be parsed.
 
 Ref: http://www.imagemagick.org/api/morphology.php#AcquireKernelBuiltIn
 
@@ -121,26 +144,64 @@ Note that 'name' kernels will start with an alphabetic character while the new k
 has a ':' character in its specification string. If neither is the case, it is assumed an old style
 of a simple list of numbers generating a odd-sized square kernel has been given.
 */
-func NewKernelInfo(kernel string) *KernelInfo {
+func NewKernelInfo(kernel string) (*KernelInfo, error) {
 	ckernel := C.CString(kernel)
 	defer C.free(unsafe.Pointer(ckernel))
 
 	kernel_info := C.AcquireKernelInfo(ckernel)
 	if kernel_info == nil {
-		return nil
+		return nil, fmt.Errorf("invalid kernel %q", kernel)
+	}
+
+	return newKernelInfo(kernel_info), nil
+}
+
+// Create a kernel from a 2d-array of values, the counterpart of ToArray.
+// All rows must have the same length. originX and originY select the
+// element of the kernel which is applied to the pixel being affected.
+// NaN values are not part of the kernel, which allows shaping it.
+//
+// Example, a 3x3 sharpen kernel:
+//
+//	kernel_info, err := NewKernelInfoFromMatrix([][]float64{
+//	    {0, -1, 0},
+//	    {-1, 5, -1},
+//	    {0, -1, 0},
+//	}, 1, 1)
+func NewKernelInfoFromMatrix(values [][]float64, originX, originY int) (*KernelInfo, error) {
+	if len(values) == 0 || len(values[0]) == 0 {
+		return nil, errors.New("empty kernel matrix")
+	}
+	width, height := len(values[0]), len(values)
+	if originX < 0 || originX >= width || originY < 0 || originY >= height {
+		return nil, fmt.Errorf("kernel origin %d,%d is outside the %dx%d matrix", originX, originY, width, height)
+	}
+
+	nums := make([]string, 0, width*height)
+	for y, row := range values {
+		if len(row) != width {
+			return nil, fmt.Errorf("kernel matrix row %d has %d values, expected %d", y, len(row), width)
+		}
+		for _, v := range row {
+			if math.IsNaN(v) {
+				nums = append(nums, "nan")
+			} else {
+				nums = append(nums, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
 	}
 
-	return newKernelInfo(kernel_info)
+	return NewKernelInfo(fmt.Sprintf("%dx%d+%d+%d: %s", width, height, originX, originY, strings.Join(nums, " ")))
 }
 
 // Create a kernel from a builtin in kernel.
 //
-// KernelInfo will be nil if an error occured.
+// An error is returned if the kernel can not be created.
 //
 // See http://www.imagemagick.org/Usage/morphology/#kernel
 // for examples. Currently the 'rotation' symbols are not supported. Example:
-// kernel_info := NewKernelInfoBuiltIn(KERNEL_RING, "2,1")
-func NewKernelInfoBuiltIn(typ KernelInfoType, kernel string) *KernelInfo {
+// kernel_info, err := NewKernelInfoBuiltIn(KERNEL_RING, "2,1")
+func NewKernelInfoBuiltIn(typ KernelInfoType, kernel string) (*KernelInfo, error) {
 	var ginfo C.GeometryInfo
 
 	ckernel := C.CString(kernel)
@@ -153,10 +214,10 @@ func NewKernelInfoBuiltIn(typ KernelInfoType, kernel string) *KernelInfo {
 
 	kernel_info := C.AcquireKernelBuiltIn(C.KernelInfoType(typ), &ginfo)
 	if kernel_info == nil {
-		return nil
+		return nil, fmt.Errorf("invalid builtin kernel %d with arguments %q", typ, kernel)
 	}
 
-	return newKernelInfo(kernel_info)
+	return newKernelInfo(kernel_info), nil
 }
 
 // ScaleKernelInfo() scales the given kernel list by the given amount, with or without
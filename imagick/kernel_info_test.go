@@ -29,9 +29,9 @@ func TestKernelInfo(t *testing.T) {
 	var kernel_info *KernelInfo
 
 	for i, tt := range table {
-		kernel_info = NewKernelInfo(tt.kernel)
-		if kernel_info == nil {
-			t.Fatalf("NewKernelInfo failed to init (test #%d)", i)
+		kernel_info, err = NewKernelInfo(tt.kernel)
+		if err != nil {
+			t.Fatalf("NewKernelInfo failed to init (test #%d): %s", i, err)
 		}
 
 		kernel_values := kernel_info.ToArray()
@@ -62,9 +62,9 @@ func TestKernelInfoBuiltIn(t *testing.T) {
 	mw.ReadImage("magick:logo")
 	mw.SetFormat("png")
 
-	kernel_info := NewKernelInfoBuiltIn(KERNEL_RING, "2,1")
-	if kernel_info == nil {
-		t.Fatal("NewKernelInfoBuiltIn failed to init")
+	kernel_info, err := NewKernelInfoBuiltIn(KERNEL_RING, "2,1")
+	if err != nil {
+		t.Fatalf("NewKernelInfoBuiltIn failed to init: %s", err)
 	}
 
 	kernel_info.Scale(1.0, KERNEL_NORMALIZE_VALUE)
@@ -88,3 +88,47 @@ func TestKernelInfoBuiltIn(t *testing.T) {
 		t.Fatalf("Convolve failed: %s", err2.Error())
 	}
 }
+
+func TestKernelInfoFromMatrix(t *testing.T) {
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	kernel_info, err := NewKernelInfoFromMatrix([][]float64{
+		{0, -1, 0},
+		{-1, 5, -1},
+		{0, -1, 0},
+	}, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kernel_info.Destroy()
+
+	values := kernel_info.ToArray()
+	if len(values) != 3 || len(values[1]) != 3 || values[1][1] != 5 || values[0][1] != -1 {
+		t.Fatalf("Unexpected kernel values %v", values)
+	}
+
+	before := mw.GetImageSignature()
+	if err := mw.FilterImage(kernel_info); err != nil {
+		t.Fatal(err)
+	}
+	if mw.GetImageSignature() == before {
+		t.Fatal("Expected the sharpen kernel to change the image signature")
+	}
+
+	if _, err := NewKernelInfoFromMatrix([][]float64{{1, 2}, {3}}, 0, 0); err == nil {
+		t.Fatal("Expected an error for a ragged kernel matrix")
+	}
+	if _, err := NewKernelInfoFromMatrix([][]float64{{1}}, 1, 0); err == nil {
+		t.Fatal("Expected an error for an origin outside the kernel matrix")
+	}
+}
+
+func TestKernelInfoInvalid(t *testing.T) {
+	if _, err := NewKernelInfo("3x3: this is not a kernel"); err == nil {
+		t.Fatal("Expected an error parsing an invalid kernel string")
+	}
+}
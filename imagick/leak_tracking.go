@@ -0,0 +1,115 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	leakTracking int32
+
+	// Creation stacks of the live objects, keyed by address so that the
+	// registry does not keep them from being finalized
+	liveObjectsMu sync.Mutex
+	liveObjects   = map[uintptr]liveObject{}
+)
+
+type liveObject struct {
+	kind  string
+	stack []uintptr
+}
+
+// Enables or disables recording the stack which created each MagickWand,
+// PixelWand, DrawingWand, PixelIterator, KernelInfo and Blob, for
+// DumpLiveObjects(). Only objects created while tracking is enabled are
+// recorded. Tracking slows down object creation, enable it to find leaks.
+func EnableLeakTracking(enable bool) {
+	liveObjectsMu.Lock()
+	defer liveObjectsMu.Unlock()
+	if enable {
+		atomic.StoreInt32(&leakTracking, 1)
+	} else {
+		atomic.StoreInt32(&leakTracking, 0)
+		liveObjects = map[uintptr]liveObject{}
+	}
+}
+
+// Writes the number of live ImageMagick objects of each kind to w, followed
+// by the stacks which created them if leak tracking is enabled, see
+// EnableLeakTracking(). Objects are live until they are destroyed or
+// finalized by the garbage collector.
+func DumpLiveObjects(w io.Writer) error {
+	for _, counter := range []struct {
+		kind  string
+		count *int64
+	}{
+		{"MagickWand", &magickWandCounter},
+		{"PixelWand", &pixelWandCounter},
+		{"DrawingWand", &drawingWandCounter},
+		{"PixelIterator", &pixelIteratorCounter},
+		{"KernelInfo", &kernelInfoCounter},
+		{"Blob", &blobCounter},
+	} {
+		if _, err := fmt.Fprintf(w, "%s: %d live\n", counter.kind, atomic.LoadInt64(counter.count)); err != nil {
+			return err
+		}
+	}
+
+	liveObjectsMu.Lock()
+	objects := make([]liveObject, 0, len(liveObjects))
+	for _, obj := range liveObjects {
+		objects = append(objects, obj)
+	}
+	liveObjectsMu.Unlock()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].kind < objects[j].kind })
+
+	for _, obj := range objects {
+		if _, err := fmt.Fprintf(w, "\n%s created at:\n", obj.kind); err != nil {
+			return err
+		}
+		frames := runtime.CallersFrames(obj.stack)
+		for {
+			frame, more := frames.Next()
+			if _, err := fmt.Fprintf(w, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line); err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Records the creation stack of obj if leak tracking is enabled
+func trackObject(kind string, obj unsafe.Pointer) {
+	if atomic.LoadInt32(&leakTracking) == 0 {
+		return
+	}
+	// Skip runtime.Callers, trackObject and IncreaseCount
+	stack := make([]uintptr, 32)
+	stack = stack[:runtime.Callers(3, stack)]
+
+	liveObjectsMu.Lock()
+	liveObjects[uintptr(obj)] = liveObject{kind, stack}
+	liveObjectsMu.Unlock()
+}
+
+// Forgets the creation stack of obj
+func untrackObject(obj unsafe.Pointer) {
+	if atomic.LoadInt32(&leakTracking) == 0 {
+		return
+	}
+	liveObjectsMu.Lock()
+	delete(liveObjects, uintptr(obj))
+	liveObjectsMu.Unlock()
+}
@@ -0,0 +1,83 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ io.Closer = (*MagickWand)(nil)
+	_ io.Closer = (*PixelWand)(nil)
+	_ io.Closer = (*DrawingWand)(nil)
+	_ io.Closer = (*PixelIterator)(nil)
+)
+
+func trackedObjects() int {
+	liveObjectsMu.Lock()
+	defer liveObjectsMu.Unlock()
+	return len(liveObjects)
+}
+
+func TestLeakTracking(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	EnableLeakTracking(true)
+	defer EnableLeakTracking(false)
+
+	kept := NewMagickWand()
+	pw := NewPixelWand()
+	closed := NewMagickWand()
+	dw := NewDrawingWand()
+	func() {
+		// Unreachable at once, finalized by the garbage collector
+		NewMagickWand()
+	}()
+
+	if err := closed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := closed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dw.Close()
+
+	for i := 0; i < 50 && trackedObjects() > 2; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	if err := DumpLiveObjects(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dump := buf.String()
+	if n := strings.Count(dump, " created at:"); n != 2 {
+		t.Fatalf("Expected the 2 unclosed objects, got %d:\n%s", n, dump)
+	}
+	for _, kind := range []string{"\nMagickWand created at:", "\nPixelWand created at:"} {
+		if !strings.Contains(dump, kind) {
+			t.Errorf("Expected %q in:\n%s", kind, dump)
+		}
+	}
+	if n := strings.Count(dump, "TestLeakTracking"); n != 2 {
+		t.Errorf("Expected both stacks to point at the test, got %d:\n%s", n, dump)
+	}
+
+	kept.Close()
+	pw.Close()
+	if n := trackedObjects(); n != 0 {
+		t.Errorf("Expected no tracked objects after closing, got %d", n)
+	}
+}
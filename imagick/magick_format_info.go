@@ -0,0 +1,71 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <stdlib.h>
+#include <magick/MagickCore.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// MagickFormatInfo describes an image format registered with ImageMagick
+type MagickFormatInfo struct {
+	Name        string
+	Description string
+	Module      string
+	Adjoin      bool // Several images can be written to one file
+	Blob        bool // Images can be read and written in memory
+	Decoder     bool // The format can be read
+	Encoder     bool // The format can be written
+	Multiframe  bool // The format can hold an image sequence, same as Adjoin
+}
+
+// Returns information about a format, e.g. "PNG". The Decoder and Encoder
+// flags reflect the delegate libraries ImageMagick was built with. Returns
+// an error wrapping ErrNotFound for an unknown format.
+func FormatInfo(format string) (*MagickFormatInfo, error) {
+	if format == "" || format == "*" {
+		return nil, fmt.Errorf("format %q: %w", format, ErrNotFound)
+	}
+	csformat := C.CString(format)
+	defer C.free(unsafe.Pointer(csformat))
+
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+
+	info := C.GetMagickInfo(csformat, exc)
+	if info == nil {
+		return nil, fmt.Errorf("format %q: %w", format, ErrNotFound)
+	}
+
+	adjoin := C.GetMagickAdjoin(info) != 0
+	return &MagickFormatInfo{
+		Name:        C.GoString(info.name),
+		Description: C.GoString(info.description),
+		Module:      C.GoString(info.module),
+		Adjoin:      adjoin,
+		Blob:        C.GetMagickBlobSupport(info) != 0,
+		Decoder:     info.decoder != nil,
+		Encoder:     info.encoder != nil,
+		Multiframe:  adjoin,
+	}, nil
+}
+
+// Returns true if ImageMagick can read images of the given format
+func CanReadFormat(format string) bool {
+	info, err := FormatInfo(format)
+	return err == nil && info.Decoder
+}
+
+// Returns true if ImageMagick can write images of the given format
+func CanWriteFormat(format string) bool {
+	info, err := FormatInfo(format)
+	return err == nil && info.Encoder
+}
@@ -0,0 +1,45 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatInfo(t *testing.T) {
+	Initialize()
+	defer Terminate()
+
+	if !CanReadFormat("PNG") || !CanWriteFormat("png") {
+		t.Fatal("Expected PNG to be readable and writable")
+	}
+	if CanReadFormat("NOSUCHFORMAT") || CanWriteFormat("NOSUCHFORMAT") {
+		t.Fatal("Expected an unknown format to be neither readable nor writable")
+	}
+
+	if _, err := FormatInfo("NOSUCHFORMAT"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for an unknown format, got %v", err)
+	}
+
+	gif, err := FormatInfo("GIF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gif.Name != "GIF" || gif.Description == "" || gif.Module == "" {
+		t.Errorf("Unexpected GIF format info %+v", gif)
+	}
+	if !gif.Multiframe || !gif.Blob {
+		t.Errorf("Expected GIF to be multiframe with blob support, got %+v", gif)
+	}
+
+	jpeg, err := FormatInfo("JPEG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jpeg.Multiframe {
+		t.Errorf("Expected JPEG not to be multiframe, got %+v", jpeg)
+	}
+}
@@ -8,7 +8,13 @@ package imagick
 #include <wand/MagickWand.h>
 */
 import "C"
-import "unsafe"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// The MagickLibVersion of the headers the package was compiled against
+const compiledLibVersion = uint(C.MagickLibVersion)
 
 // Returns the ImageMagick API copyright as a string constant.
 func GetCopyright() string {
@@ -16,6 +22,12 @@ func GetCopyright() string {
 	return C.GoString(cstr)
 }
 
+// Returns the optional features ImageMagick was built with, e.g.
+// "DPC HDRI OpenMP", as listed by "convert -version". See HasFeature().
+func GetFeatures() string {
+	return queryConfigureOption("FEATURES")
+}
+
 // Returns the ImageMagick home URL.
 func GetHomeURL() string {
 	cstr := C.MagickGetHomeURL()
@@ -55,9 +67,12 @@ func GetResource(rtype ResourceType) int64 {
 
 }
 
-// Returns the specified resource limit in megabytes.
-func GetResourceLimit(rtype ResourceType) int64 {
-	return int64(C.MagickGetResourceLimit(C.ResourceType(rtype)))
+// Returns the specified resource limit. Memory, map, disk and area limits
+// are in bytes (pixels for area), width and height in pixels, time in
+// seconds, file and thread in counts. An unlimited resource returns
+// math.MaxUint64.
+func GetResourceLimit(rtype ResourceType) uint64 {
+	return uint64(C.MagickGetResourceLimit(C.ResourceType(rtype)))
 }
 
 // Returns the ImageMagick API version as a string constant and as a number.
@@ -69,8 +84,35 @@ func GetVersion() (version string, nversion uint) {
 	return
 }
 
-// Specify resource limit at package level.
-func SetResourceLimit(rtype ResourceType, limit uint64) bool {
+// Specify resource limit at package level, for all wands of the process.
+// See GetResourceLimit() for the units of each resource. Limits set before
+// ReadImage() make oversized images fail with a resource limit error
+// instead of exhausting memory.
+func SetResourceLimit(rtype ResourceType, limit uint64) error {
 	ok := C.MagickSetResourceLimit(C.ResourceType(rtype), C.MagickSizeType(limit))
-	return C.int(ok) == 1
+	if C.int(ok) == 0 {
+		return fmt.Errorf("unable to set limit of resource %d to %d", rtype, limit)
+	}
+	return nil
+}
+
+// Returns an error if the ImageMagick library linked at runtime is not the
+// version of the headers the package was compiled against, e.g. after the
+// shared library was upgraded without rebuilding. Call it at startup.
+func CheckVersionCompatibility() error {
+	_, linked := GetVersion()
+	return checkLibVersion(compiledLibVersion, linked)
+}
+
+func checkLibVersion(compiled, linked uint) error {
+	if compiled == linked {
+		return nil
+	}
+	return fmt.Errorf("ImageMagick version mismatch: compiled against %s, linked with %s",
+		formatLibVersion(compiled), formatLibVersion(linked))
+}
+
+// Formats a MagickLibVersion number such as 0x69A as "6.9.10"
+func formatLibVersion(v uint) string {
+	return fmt.Sprintf("%d.%d.%d", v>>8, v>>4&0xf, v&0xf)
 }
@@ -0,0 +1,44 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestGetVersion(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	version, nversion := GetVersion()
+	if version == "" || nversion == 0 {
+		t.Fatalf("Expected a version, got %q (%#x)", version, nversion)
+	}
+	if GetReleaseDate() == "" || GetCopyright() == "" || GetHomeURL() == "" {
+		t.Error("Expected a release date, copyright and home URL")
+	}
+	t.Logf("%s, features: %s", version, GetFeatures())
+
+	if err := CheckVersionCompatibility(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckLibVersion(t *testing.T) {
+	if err := checkLibVersion(0x69A, 0x69A); err != nil {
+		t.Errorf("Expected equal versions to pass, got %v", err)
+	}
+	err := checkLibVersion(0x69A, 0x699)
+	if err == nil {
+		t.Fatal("Expected differing versions to fail")
+	}
+	want := "ImageMagick version mismatch: compiled against 6.9.10, linked with 6.9.9"
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
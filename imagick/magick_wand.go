@@ -10,20 +10,49 @@ package imagick
 import "C"
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"unsafe"
 )
 
-// This struct represents the MagickWand C API of ImageMagick
+var (
+	// ErrWandDestroyed is returned by the methods of a MagickWand after
+	// Destroy was called
+	ErrWandDestroyed = errors.New("wand was destroyed")
+
+	// ErrWandInvalid is returned by the methods of a MagickWand which does
+	// not wrap a valid C wand, e.g. one ImageMagick failed to create
+	ErrWandInvalid = errors.New("invalid wand")
+)
+
+// This struct represents the MagickWand C API of ImageMagick.
+//
+// Each method call is synchronized internally: the C operation and the
+// retrieval of the exception it raised happen under a per-wand lock, so the
+// error returned by a method is always the one raised by that method, even
+// when other goroutines use the wand. Sequences of calls are not atomic
+// though, a wand which is modified from several goroutines still needs
+// locking by the caller.
 type MagickWand struct {
-	mw   *C.MagickWand
-	init sync.Once
+	mw        *C.MagickWand
+	mu        sync.Mutex
+	init      sync.Once
+	destroyed bool
+
+	// Registry id of the Go progress monitor, see SetProgressMonitor()
+	progressMonitor uintptr
 }
 
 func newMagickWand(cmw *C.MagickWand) *MagickWand {
+	if cmw == nil {
+		// Methods of the wand return ErrWandInvalid, it needs no Destroy
+		return &MagickWand{}
+	}
 	mw := &MagickWand{mw: cmw}
 	runtime.SetFinalizer(mw, Destroy)
 	mw.IncreaseCount()
@@ -31,34 +60,172 @@ func newMagickWand(cmw *C.MagickWand) *MagickWand {
 	return mw
 }
 
+// Returns a new wand for cmw, the result of an operation on mw. If the
+// operation returned NULL, the exception it raised is returned instead, or
+// ErrWandInvalid if it raised none. A warning raised by a successful
+// operation is cleared. The caller holds mw.mu.
+func (mw *MagickWand) resultWand(cmw *C.MagickWand) (*MagickWand, error) {
+	err := mw.lastError()
+	if cmw != nil {
+		return newMagickWand(cmw), nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%s: %w", callerName(1), ErrWandInvalid)
+	}
+	return nil, err
+}
+
 // Returns a wand required for all other methods in the API. A fatal exception is thrown if there is not enough memory to allocate the wand.
+// Panics if the environment is not initialized, see NewMagickWandChecked().
 func NewMagickWand() *MagickWand {
-	return newMagickWand(C.NewMagickWand())
+	mw, err := NewMagickWandChecked()
+	if err != nil {
+		panic("imagick: " + err.Error())
+	}
+	return mw
 }
 
-// Returns a wand with an image
+// Like NewMagickWand, but returns an error wrapping ErrNotInitialized if
+// Initialize was not called or the environment was terminated.
+func NewMagickWandChecked() (*MagickWand, error) {
+	if err := checkInitialized("NewMagickWand"); err != nil {
+		return nil, err
+	}
+	return newMagickWand(C.NewMagickWand()), nil
+}
+
+// Returns a wand with an image. Panics if the environment is not
+// initialized, see NewMagickWandFromImageChecked().
 func NewMagickWandFromImage(img *Image) *MagickWand {
+	mw, err := NewMagickWandFromImageChecked(img)
+	if err != nil {
+		panic("imagick: " + err.Error())
+	}
+	return mw
+}
+
+// Like NewMagickWandFromImage, but returns an error wrapping
+// ErrNotInitialized if Initialize was not called or the environment was
+// terminated.
+func NewMagickWandFromImageChecked(img *Image) (*MagickWand, error) {
+	if err := checkInitialized("NewMagickWandFromImage"); err != nil {
+		return nil, err
+	}
 	ret := newMagickWand(C.NewMagickWandFromImage(img.img))
 	runtime.KeepAlive(img)
-	return ret
+	return ret, nil
 }
 
 // Clear resources associated with the wand, leaving the wand blank, and ready to be used for a new set of images.
 func (mw *MagickWand) Clear() {
+	if mw.valid() != nil {
+		return
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	C.ClearMagickWand(mw.mw)
 	runtime.KeepAlive(mw)
 }
 
+// Returns the wand to the state of a new wand: the images, options, format,
+// size, iterator and progress monitor are cleared. Used by WandPool before
+// a wand is reused.
+func (mw *MagickWand) Reset() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	C.ClearMagickWand(mw.mw)
+	runtime.KeepAlive(mw)
+	mw.releaseProgressMonitor()
+	return nil
+}
+
 // Makes an exact copy of the MagickWand object
 func (mw *MagickWand) Clone() *MagickWand {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := newMagickWand(C.CloneMagickWand(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Returns a new wand with copies of the images selected by scenes, in the
+// order given. Scenes uses the ImageMagick syntax, a comma separated list of
+// indexes and ranges such as "0-3,7,9-12". Ranges may be descending.
+func (mw *MagickWand) CloneImages(scenes string) (*MagickWand, error) {
+	n := int(mw.GetNumberImages())
+	if n == 0 {
+		return nil, errors.New("CloneImages: wand has no images")
+	}
+	indexes, err := parseScenes(scenes, n)
+	if err != nil {
+		return nil, err
+	}
+
+	current := int(mw.GetIteratorIndex())
+	defer mw.SetIteratorIndex(current)
+
+	clone := NewMagickWand()
+	for _, i := range indexes {
+		mw.SetIteratorIndex(i)
+		frame, err := mw.GetImage()
+		if err == nil {
+			err = clone.AddImage(frame)
+			frame.Destroy()
+		}
+		if err != nil {
+			clone.Destroy()
+			return nil, err
+		}
+	}
+	clone.ResetIterator()
+	return clone, nil
+}
+
+// Parses a scene specification such as "0-3,7,9-12" into image indexes,
+// which must be below n.
+func parseScenes(scenes string, n int) ([]int, error) {
+	var indexes []int
+	for _, part := range strings.Split(scenes, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scene %q in %q", part, scenes)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("invalid scene range %q in %q", part, scenes)
+			}
+		}
+		for _, i := range []int{first, last} {
+			if i < 0 || i >= n {
+				return nil, fmt.Errorf("scene %d out of range, valid scenes are 0-%d", i, n-1)
+			}
+		}
+		step := 1
+		if last < first {
+			step = -1
+		}
+		for i := first; ; i += step {
+			indexes = append(indexes, i)
+			if i == last {
+				break
+			}
+		}
+	}
+	return indexes, nil
+}
+
 // Deallocates memory associated with an MagickWand
 func (mw *MagickWand) Destroy() {
-	if mw.mw == nil {
+	if mw == nil || mw.mw == nil {
 		return
 	}
 
@@ -67,11 +234,20 @@ func (mw *MagickWand) Destroy() {
 		relinquishMemory(unsafe.Pointer(mw.mw))
 		runtime.SetFinalizer(mw, nil)
 		mw.mw = nil
+		mw.destroyed = true
+		mw.releaseProgressMonitor()
 
 		mw.DecreaseCount()
 	})
 }
 
+// Destroys the MagickWand, see Destroy(). It implements io.Closer and may be
+// called more than once.
+func (mw *MagickWand) Close() error {
+	mw.Destroy()
+	return nil
+}
+
 // Returns true if the wand is a verified magick wand
 func (mw *MagickWand) IsVerified() bool {
 	if mw.mw != nil {
@@ -81,20 +257,45 @@ func (mw *MagickWand) IsVerified() bool {
 	return false
 }
 
+// Returns ErrWandDestroyed or ErrWandInvalid if the wand cannot be passed to
+// ImageMagick, which would crash the process.
+func (mw *MagickWand) valid() error {
+	if mw == nil {
+		return ErrWandInvalid
+	}
+	if mw.mw == nil {
+		if mw.destroyed {
+			return ErrWandDestroyed
+		}
+		return ErrWandInvalid
+	}
+	if !mw.IsVerified() {
+		return ErrWandInvalid
+	}
+	return nil
+}
+
 // Increase MagickWand ref counter and set according "can`t be terminated status"
 func (mw *MagickWand) IncreaseCount() {
 	atomic.AddInt64(&magickWandCounter, int64(1))
+	trackObject("MagickWand", unsafe.Pointer(mw))
 	unsetCanTerminate()
 }
 
 // Decrease MagickWand ref counter and set according "can be terminated status"
 func (mw *MagickWand) DecreaseCount() {
 	atomic.AddInt64(&magickWandCounter, int64(-1))
+	untrackObject(unsafe.Pointer(mw))
 	setCanTerminate()
 }
 
 // Returns the position of the iterator in the image list
 func (mw *MagickWand) GetIteratorIndex() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetIteratorIndex(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -124,28 +325,47 @@ func (mw *MagickWand) QueryConfigureOptions(pattern string) (options []string) {
 	return
 }
 
-// Returns a FontMetrics struct
-func (mw *MagickWand) QueryFontMetrics(dw *DrawingWand, textLine string) *FontMetrics {
+// Returns a FontMetrics struct describing the text rendered with the
+// drawing wand font settings. The wand must hold an image, whose resolution
+// is used to measure the text.
+func (mw *MagickWand) QueryFontMetrics(dw *DrawingWand, textLine string) (*FontMetrics, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstext := C.CString(textLine)
 	defer C.free(unsafe.Pointer(cstext))
 	cdoubles := C.MagickQueryFontMetrics(mw.mw, dw.dw, cstext)
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(dw)
+	if cdoubles == nil {
+		return nil, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
 	defer relinquishMemory(unsafe.Pointer(cdoubles))
 	doubles := sizedDoubleArrayToFloat64Slice(cdoubles, 13)
-	return NewFontMetricsFromArray(doubles)
+	return NewFontMetricsFromArray(doubles), nil
 }
 
-// Returns a FontMetrics struct related to the multiline text
-func (mw *MagickWand) QueryMultilineFontMetrics(dw *DrawingWand, textParagraph string) *FontMetrics {
+// Returns a FontMetrics struct related to the multiline text, lines are
+// separated by \n
+func (mw *MagickWand) QueryMultilineFontMetrics(dw *DrawingWand, textParagraph string) (*FontMetrics, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstext := C.CString(textParagraph)
 	defer C.free(unsafe.Pointer(cstext))
 	cdoubles := C.MagickQueryMultilineFontMetrics(mw.mw, dw.dw, cstext)
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(dw)
+	if cdoubles == nil {
+		return nil, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
 	defer relinquishMemory(unsafe.Pointer(cdoubles))
 	doubles := sizedDoubleArrayToFloat64Slice(cdoubles, 13)
-	return NewFontMetricsFromArray(doubles)
+	return NewFontMetricsFromArray(doubles), nil
 }
 
 // Returns any font that match the specified pattern (e.g. "*" for all)
@@ -176,6 +396,11 @@ func (mw *MagickWand) QueryFormats(pattern string) (formats []string) {
 // Afterward you can use NextImage() to iterate over all the images in a wand container, starting with the first image.
 // Using this before AddImages() or ReadImages() will cause new images to be inserted between the first and second image.
 func (mw *MagickWand) ResetIterator() {
+	if mw.valid() != nil {
+		return
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	C.MagickResetIterator(mw.mw)
 	runtime.KeepAlive(mw)
 }
@@ -186,6 +411,11 @@ func (mw *MagickWand) ResetIterator() {
 // current image to the second image in the list (if present).
 // This operation is similar to ResetIterator() but differs in how AddImage(), ReadImage(), and NextImage() behaves afterward.
 func (mw *MagickWand) SetFirstIterator() {
+	if mw.valid() != nil {
+		return
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	C.MagickSetFirstIterator(mw.mw)
 	runtime.KeepAlive(mw)
 }
@@ -199,16 +429,54 @@ func (mw *MagickWand) SetFirstIterator() {
 // regardless of if a zero (first image in list) or negative index (from end) is used.
 // Jumping to index 0 is similar to ResetIterator() but differs in how NextImage() behaves afterward.
 func (mw *MagickWand) SetIteratorIndex(index int) bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.int(C.MagickSetIteratorIndex(mw.mw, C.ssize_t(index)))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Returns an iterator over the images in the wand, yielding the index of each
+// frame together with the wand itself, positioned at that frame. Operations
+// on the yielded wand, e.g. ResizeImage, change that frame of the wand.
+// With Go 1.23 or later it can be used with range:
+//
+//	for i, frame := range mw.Frames() {
+//	    frame.ResizeImage(...)
+//	}
+//
+// When the loop ends, including an early break, the current image is the
+// last frame yielded. The number of frames is read once when the iteration
+// starts; it stops early if frames are removed inside the loop.
+func (mw *MagickWand) Frames() func(yield func(int, *MagickWand) bool) {
+	return func(yield func(int, *MagickWand) bool) {
+		n := int(mw.GetNumberImages())
+		for i := 0; i < n; i++ {
+			if !mw.SetIteratorIndex(i) || !yield(i, mw) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the number of images in the wand, same as GetNumberImages()
+func (mw *MagickWand) NFrames() uint {
+	return mw.GetNumberImages()
+}
+
 // SetLastIterator() sets the wand iterator to the last image.
 // The last image is actually the current image, and the next use of PreviousImage() will not change this allowing this function
 // to be used to iterate over the images in the reverse direction. In this sense it is more like ResetIterator() than SetFirstIterator().
 // Typically this function is used before AddImage(), ReadImage() functions to ensure new images are appended to the very end of wand's image list.
 func (mw *MagickWand) SetLastIterator() {
+	if mw.valid() != nil {
+		return
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	C.MagickSetLastIterator(mw.mw)
 	runtime.KeepAlive(mw)
 }
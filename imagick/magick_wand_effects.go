@@ -0,0 +1,269 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+)
+
+// Places the current image onto its drop shadow. The canvas is enlarged so
+// that the blurred shadow is not clipped: with a bleed of
+// b = floor(2*sigma+0.5) it grows by max(0, b-offsetX) on the left and
+// max(0, b+offsetX) on the right, and likewise vertically. Areas covered by
+// neither the image nor the shadow are transparent.
+//
+// color: the shadow color.
+//
+// opacity: the shadow opacity in percent, 0 to 100.
+//
+// sigma: the standard deviation of the shadow blur, in pixels.
+func (mw *MagickWand) AddDropShadow(color *PixelWand, opacity, sigma float64, offsetX, offsetY int) error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("AddDropShadow: wand has no image")
+	}
+
+	image, err := mw.currentImage()
+	if err != nil {
+		return err
+	}
+	defer image.Destroy()
+	if err := image.ResetImagePage(""); err != nil {
+		return err
+	}
+
+	shadow, err := image.currentImage()
+	if err != nil {
+		return err
+	}
+	defer shadow.Destroy()
+	if err := shadow.SetImageBackgroundColor(color); err != nil {
+		return err
+	}
+	if err := shadow.ShadowImage(opacity, sigma, offsetX, offsetY); err != nil {
+		return err
+	}
+	// The page offset places the shadow relative to the image
+	_, _, shadowX, shadowY, err := shadow.GetImagePage()
+	if err != nil {
+		return err
+	}
+	shadowW, shadowH := shadow.GetImageWidth(), shadow.GetImageHeight()
+
+	left, top := minInt(0, shadowX), minInt(0, shadowY)
+	right := maxInt(int(width), shadowX+int(shadowW))
+	bottom := maxInt(int(height), shadowY+int(shadowH))
+
+	transparent := NewPixelWand()
+	defer transparent.Destroy()
+	transparent.SetColor("none")
+	canvas := NewMagickWand()
+	defer canvas.Destroy()
+	if err := canvas.NewImage(uint(right-left), uint(bottom-top), transparent); err != nil {
+		return err
+	}
+	if err := canvas.CompositeImage(shadow, COMPOSITE_OP_OVER, shadowX-left, shadowY-top); err != nil {
+		return err
+	}
+	if err := canvas.CompositeImage(image, COMPOSITE_OP_OVER, -left, -top); err != nil {
+		return err
+	}
+	if err := mw.SetImage(canvas); err != nil {
+		return err
+	}
+	return mw.ResetImagePage("")
+}
+
+// WatermarkOptions describes where and how a watermark is placed, see
+// Watermark()
+type WatermarkOptions struct {
+	// Anchor of a single mark, OffsetX and OffsetY move it away from the edge
+	Gravity          GravityType
+	OffsetX, OffsetY int
+	// Opacity of the mark from 0 to 1, the zero value keeps it opaque
+	Opacity float64
+	// Repeats the mark over the whole image, OffsetX and OffsetY shift the
+	// tiles and Gravity is ignored
+	Tile bool
+	// Rotation of the tiled mark in degrees
+	Angle float64
+}
+
+// Draws the current image of mark over the current image. The mark is
+// faded by multiplying its alpha with the opacity, so transparent parts of
+// the mark stay transparent. The mark wand is not modified.
+func (mw *MagickWand) Watermark(mark *MagickWand, opts WatermarkOptions) error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("Watermark: wand has no image")
+	}
+	if opts.Opacity < 0 || opts.Opacity > 1 {
+		return errors.New("Watermark: opacity must be between 0 and 1")
+	}
+
+	stamp, err := mark.currentImage()
+	if err != nil {
+		return err
+	}
+	defer stamp.Destroy()
+	if err := stamp.ResetImagePage(""); err != nil {
+		return err
+	}
+	if err := stamp.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		return err
+	}
+	if opts.Tile && opts.Angle != 0 {
+		transparent := NewPixelWand()
+		defer transparent.Destroy()
+		transparent.SetColor("none")
+		if err := stamp.RotateImage(transparent, opts.Angle); err != nil {
+			return err
+		}
+		if err := stamp.ResetImagePage(""); err != nil {
+			return err
+		}
+	}
+	stampW, stampH := stamp.GetImageWidth(), stamp.GetImageHeight()
+
+	if opts.Opacity > 0 && opts.Opacity < 1 {
+		fade := NewPixelWand()
+		defer fade.Destroy()
+		fade.SetColor("black")
+		fade.SetAlpha(opts.Opacity)
+		mask := NewMagickWand()
+		defer mask.Destroy()
+		if err := mask.NewImage(stampW, stampH, fade); err != nil {
+			return err
+		}
+		if err := stamp.CompositeImage(mask, COMPOSITE_OP_DST_IN, 0, 0); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Tile {
+		x, y := gravityOffsetMargin(width, height, stampW, stampH, opts.Gravity, opts.OffsetX, opts.OffsetY)
+		return mw.CompositeImage(stamp, COMPOSITE_OP_OVER, x, y)
+	}
+
+	startX, startY := opts.OffsetX%int(stampW), opts.OffsetY%int(stampH)
+	if startX > 0 {
+		startX -= int(stampW)
+	}
+	if startY > 0 {
+		startY -= int(stampH)
+	}
+	for y := startY; y < int(height); y += int(stampH) {
+		for x := startX; x < int(width); x += int(stampW) {
+			if err := mw.CompositeImage(stamp, COMPOSITE_OP_OVER, x, y); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GradientStop is a color at an offset from 0 (shadows) to 1 (highlights) of
+// a gradient map, see GradientMap()
+type GradientStop struct {
+	Offset float64
+	Color  *PixelWand
+}
+
+// Maps the shadows of the current image to shadow and the highlights to
+// highlight, see GradientMap.
+func (mw *MagickWand) Duotone(shadow, highlight *PixelWand) error {
+	return mw.GradientMap([]GradientStop{{0, shadow}, {1, highlight}})
+}
+
+// Converts the current image to grayscale and then replaces each gray level
+// with the color of the gradient at that level. Levels before the first or
+// after the last stop take the color of that stop. The alpha channel of the
+// image is kept, the alpha of the stop colors is ignored.
+func (mw *MagickWand) GradientMap(stops []GradientStop) error {
+	if len(stops) == 0 {
+		return errors.New("GradientMap: no gradient stops")
+	}
+	sorted := make([]GradientStop, len(stops))
+	copy(sorted, stops)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	colors := make([][3]float64, len(sorted))
+	for i, stop := range sorted {
+		if stop.Color == nil {
+			return errors.New("GradientMap: gradient stop without color")
+		}
+		colors[i] = [3]float64{stop.Color.GetRed(), stop.Color.GetGreen(), stop.Color.GetBlue()}
+	}
+
+	pixels := make([]float32, 0, 256*3)
+	for level := 0; level < 256; level++ {
+		offset := float64(level) / 255
+		i := sort.Search(len(sorted), func(i int) bool { return sorted[i].Offset >= offset })
+		var rgb [3]float64
+		switch {
+		case i == 0:
+			rgb = colors[0]
+		case i == len(sorted):
+			rgb = colors[len(sorted)-1]
+		default:
+			t := (offset - sorted[i-1].Offset) / (sorted[i].Offset - sorted[i-1].Offset)
+			for c := range rgb {
+				rgb[c] = colors[i-1][c] + t*(colors[i][c]-colors[i-1][c])
+			}
+		}
+		pixels = append(pixels, float32(rgb[0]), float32(rgb[1]), float32(rgb[2]))
+	}
+
+	clut := NewMagickWand()
+	defer clut.Destroy()
+	if err := clut.ConstituteImage(256, 1, "RGB", PIXEL_FLOAT, pixels); err != nil {
+		return err
+	}
+
+	if err := mw.TransformImageColorspace(COLORSPACE_GRAY); err != nil {
+		return err
+	}
+	// The gray values are kept, the image just needs to hold colors again
+	if err := mw.SetImageColorspace(COLORSPACE_SRGB); err != nil {
+		return err
+	}
+	return mw.ClutImage(clut)
+}
+
+// Returns a new wand holding a copy of the current image
+func (mw *MagickWand) currentImage() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cmw := C.MagickGetImage(mw.mw)
+	runtime.KeepAlive(mw)
+	if cmw == nil {
+		return nil, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	return newMagickWand(cmw), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,188 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestAddDropShadow(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[40x30]"); err != nil {
+		t.Fatal(err)
+	}
+
+	black := NewPixelWand()
+	defer black.Destroy()
+	black.SetColor("black")
+
+	// A bleed of 4 pixels, covered on the left and top by the offset
+	if err := mw.AddDropShadow(black, 60, 2, 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 49 || h != 39 {
+		t.Fatalf("Expected 49x39, got %dx%d", w, h)
+	}
+	if _, _, x, y, err := mw.GetImagePage(); err != nil || x != 0 || y != 0 {
+		t.Fatalf("Expected the page to be reset, got %+d%+d (%v)", x, y, err)
+	}
+
+	expectPixelColor(t, mw, 0, 0, "red")
+	expectPixelColor(t, mw, 39, 29, "red")
+
+	shadow, err := mw.GetImagePixelColor(42, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shadow.Destroy()
+	if alpha := shadow.GetAlpha(); alpha < 0.2 || alpha > 0.8 {
+		t.Errorf("Expected a partially transparent shadow, got alpha %f", alpha)
+	}
+	if shadow.GetRed() > 0.01 || shadow.GetGreen() > 0.01 || shadow.GetBlue() > 0.01 {
+		t.Errorf("Expected a black shadow, got %s", shadow.GetColorAsString())
+	}
+
+	corner, err := mw.GetImagePixelColor(48, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corner.Destroy()
+	if alpha := corner.GetAlpha(); alpha != 0 {
+		t.Errorf("Expected a transparent corner, got alpha %f", alpha)
+	}
+
+	// A negative offset grows the canvas on the other side
+	if err := mw.ReadImage("xc:red[40x30]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.AddDropShadow(black, 60, 2, -5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 49 || h != 38 {
+		t.Fatalf("Expected 49x38, got %dx%d", w, h)
+	}
+	expectPixelColor(t, mw, 9, 4, "red")
+}
+
+func TestWatermark(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mark := NewMagickWand()
+	defer mark.Destroy()
+	if err := mark.ReadImage("xc:white[10x10]"); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:black[100x80]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Watermark(mark, WatermarkOptions{Gravity: GRAVITY_SOUTH_EAST, OffsetX: 5, OffsetY: 5, Opacity: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	pw, err := mw.GetImagePixelColor(90, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Destroy()
+	if red := pw.GetRed(); red < 0.45 || red > 0.55 {
+		t.Errorf("Expected a 50%% blend of black and white, got %s", pw.GetColorAsString())
+	}
+	expectPixelColor(t, mw, 95, 75, "black")
+	expectPixelColor(t, mw, 80, 60, "black")
+
+	if mark.GetImageAlphaChannel() {
+		t.Fatal("Expected the mark to be left unchanged")
+	}
+
+	tiled := NewMagickWand()
+	defer tiled.Destroy()
+	if err := tiled.ReadImage("xc:black[100x80]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tiled.Watermark(mark, WatermarkOptions{Tile: true, Opacity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, tiled, 0, 0, "white")
+	expectPixelColor(t, tiled, 99, 79, "white")
+
+	rotated := NewMagickWand()
+	defer rotated.Destroy()
+	if err := rotated.ReadImage("xc:black[100x80]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotated.Watermark(mark, WatermarkOptions{Tile: true, Angle: 30, Opacity: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotated.Watermark(mark, WatermarkOptions{Opacity: 2}); err == nil {
+		t.Fatal("Expected an error for an opacity above 1")
+	}
+}
+
+func TestDuotone(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	shadow := NewPixelWand()
+	defer shadow.Destroy()
+	shadow.SetColor("navy")
+	highlight := NewPixelWand()
+	defer highlight.Destroy()
+	highlight.SetColor("yellow")
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.SetSize(4, 256); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("gradient:black-white"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Duotone(shadow, highlight); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, mw, 1, 0, "navy")
+	expectPixelColor(t, mw, 1, 255, "yellow")
+
+	translucent := NewMagickWand()
+	defer translucent.Destroy()
+	if err := translucent.ReadImage("xc:rgba(128,128,128,0.5)[4x4]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := translucent.GradientMap([]GradientStop{{1, highlight}, {0, shadow}, {0.5, shadow}}); err != nil {
+		t.Fatal(err)
+	}
+	pw, err := translucent.GetImagePixelColor(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Destroy()
+	if alpha := pw.GetAlpha(); alpha < 0.49 || alpha > 0.51 {
+		t.Errorf("Expected the alpha to be kept, got %f", alpha)
+	}
+	if blue := pw.GetBlue(); blue < 0.45 || blue > 0.55 {
+		t.Errorf("Expected mid gray to map to the middle stop, got %s", pw.GetColorAsString())
+	}
+
+	if err := mw.GradientMap(nil); err == nil {
+		t.Fatal("Expected an error without stops")
+	}
+}
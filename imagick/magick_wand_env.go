@@ -9,14 +9,26 @@ package imagick
 */
 import "C"
 import (
+	"errors"
+	"fmt"
+	"log"
 	"runtime"
-	"sync"
 	"sync/atomic"
 )
 
+// ErrNotInitialized is returned by the Checked constructors, e.g.
+// NewMagickWandChecked(), when Initialize was never called or the
+// environment was terminated
+var ErrNotInitialized = errors.New("MagickWand environment is not initialized")
+
 var (
-	initOnce      sync.Once
-	terminateOnce *sync.Once
+	// Number of Initialize calls not yet matched by Terminate, guarded by
+	// envSemaphore
+	initCount int
+
+	// Set to 1 by the first Initialize, and to 0 again once the environment
+	// was terminated
+	initialized int32
 
 	// Indicates that terminate method can be called (there are no any ImageMagick objects)
 	canTerminate = make(chan struct{}, 1)
@@ -28,36 +40,64 @@ var (
 	drawingWandCounter   int64
 	pixelIteratorCounter int64
 	pixelWandCounter     int64
+	kernelInfoCounter    int64
+	blobCounter          int64
 )
 
-// Initializes the MagickWand environment
+// Initializes the MagickWand environment. Calls are reference counted, so
+// that independent packages of one process can each pair Initialize with
+// Terminate: the environment is set up by the first call only, and torn
+// down when every Initialize has been matched by a Terminate.
 func Initialize() {
 	envSemaphore <- struct{}{}
 	defer func() {
 		<-envSemaphore
 	}()
 
-	initOnce.Do(func() {
+	initCount++
+	if initCount == 1 {
 		C.MagickWandGenesis()
-		terminateOnce = &sync.Once{}
+		atomic.StoreInt32(&initialized, 1)
 		setCanTerminate()
-	})
+	}
 }
 
-// Terminates the MagickWand environment
-// wait until all imageMagick objects destroyed
+// Terminates the MagickWand environment when it matches the first call to
+// Initialize, waiting until all ImageMagick objects are destroyed. Calls
+// without a matching Initialize are ignored with a logged warning, see
+// TerminateChecked().
 func Terminate() {
+	if err := TerminateChecked(); err != nil {
+		log.Printf("imagick: %s", err)
+	}
+}
+
+// Like Terminate, but returns an error if there is no matching call to
+// Initialize.
+func TerminateChecked() error {
 	envSemaphore <- struct{}{}
 	defer func() {
 		<-envSemaphore
 	}()
 
-	if terminateOnce != nil {
-		terminateOnce.Do(func() {
-			runtime.GC()
-			terminate()
-		})
+	if initCount == 0 {
+		return errors.New("Terminate called more often than Initialize")
+	}
+	initCount--
+	if initCount == 0 {
+		runtime.GC()
+		terminate()
+	}
+	return nil
+}
+
+// Returns an error wrapping ErrNotInitialized instead of crashing in C when
+// a constructor is called before Initialize or after Terminate.
+func checkInitialized(constructor string) error {
+	if atomic.LoadInt32(&initialized) == 0 {
+		return fmt.Errorf("%s: %w, call Initialize first", constructor, ErrNotInitialized)
 	}
+	return nil
 }
 
 // Guarantees that C.MagickWandTerminus run after all ImageMagick objects are destroyed
@@ -65,7 +105,7 @@ func Terminate() {
 func terminate() {
 	<-canTerminate
 	C.MagickWandTerminus()
-	initOnce = sync.Once{}
+	atomic.StoreInt32(&initialized, 0)
 }
 
 // Set status "terminate can be called"
@@ -92,7 +132,7 @@ func unsetCanTerminate() {
 
 // Check are all IM objects are collected by GC
 func isImageMagickCleaned() bool {
-	if atomic.LoadInt64(&magickWandCounter) != 0 || atomic.LoadInt64(&drawingWandCounter) != 0 || atomic.LoadInt64(&pixelIteratorCounter) != 0 || atomic.LoadInt64(&pixelWandCounter) != 0 {
+	if atomic.LoadInt64(&magickWandCounter) != 0 || atomic.LoadInt64(&drawingWandCounter) != 0 || atomic.LoadInt64(&pixelIteratorCounter) != 0 || atomic.LoadInt64(&pixelWandCounter) != 0 || atomic.LoadInt64(&kernelInfoCounter) != 0 || atomic.LoadInt64(&blobCounter) != 0 {
 		return false
 	}
 
@@ -4,7 +4,12 @@
 
 package imagick
 
-import "testing"
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
 
 func TestEnvironmentLifecycle(t *testing.T) {
 	defer func() {
@@ -19,3 +24,64 @@ func TestEnvironmentLifecycle(t *testing.T) {
 	Terminate()
 	Terminate()
 }
+
+func TestEnvironmentConcurrentLifecycle(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				Initialize()
+				mw := NewMagickWand()
+				if err := mw.ReadImage("xc:red[10x10]"); err != nil {
+					t.Error(err)
+				}
+				mw.Destroy()
+				Terminate()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTerminateChecked(t *testing.T) {
+	// Pretend every Initialize was matched
+	envSemaphore <- struct{}{}
+	saved := initCount
+	initCount = 0
+	<-envSemaphore
+	defer func() {
+		envSemaphore <- struct{}{}
+		initCount = saved
+		<-envSemaphore
+	}()
+
+	if err := TerminateChecked(); err == nil {
+		t.Error("Expected an error for Terminate without Initialize")
+	}
+}
+
+func TestNewMagickWandNotInitialized(t *testing.T) {
+	// Pretend Initialize was never called, which is also the state after
+	// Terminate
+	atomic.StoreInt32(&initialized, 0)
+	defer atomic.StoreInt32(&initialized, 1)
+
+	if mw, err := NewMagickWandChecked(); mw != nil || !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected ErrNotInitialized from NewMagickWandChecked, got %v", err)
+	}
+	if pw, err := NewPixelWandChecked(); pw != nil || !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected ErrNotInitialized from NewPixelWandChecked, got %v", err)
+	}
+	if dw, err := NewDrawingWandChecked(); dw != nil || !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected ErrNotInitialized from NewDrawingWandChecked, got %v", err)
+	}
+
+	defer func() {
+		if reco := recover(); reco == nil {
+			t.Error("Expected NewMagickWand to panic before Initialize")
+		}
+	}()
+	NewMagickWand()
+}
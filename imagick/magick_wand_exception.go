@@ -12,16 +12,43 @@ import "C"
 import (
 	"fmt"
 	"runtime"
+	"strings"
 	"unsafe"
 )
 
-type MagickWandException struct {
-	kind        ExceptionType
-	description string
+// MagickError is the error type returned by MagickWand methods. It carries
+// the ImageMagick exception severity so that callers can tell warnings
+// (e.g. a JPEG with trailing garbage which still decodes) from hard errors:
+//
+//	var merr *imagick.MagickError
+//	if errors.As(err, &merr) && merr.IsWarning() {
+//	    // the image was read, log and carry on
+//	}
+type MagickError struct {
+	Severity    ExceptionType
+	Description string
 }
 
-func (mwe *MagickWandException) Error() string {
-	return fmt.Sprintf("%s: %s", mwe.kind.String(), mwe.description)
+// MagickWandException is kept for backwards compatibility
+type MagickWandException = MagickError
+
+func (e *MagickError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Severity.String(), e.Description)
+}
+
+// Returns true if the exception is a warning, the operation itself succeeded
+func (e *MagickError) IsWarning() bool {
+	return e.Severity >= EXCEPTION_WARNING && e.Severity < EXCEPTION_ERROR
+}
+
+// Returns true if the exception is an error
+func (e *MagickError) IsError() bool {
+	return e.Severity >= EXCEPTION_ERROR && e.Severity < EXCEPTION_FATAL_ERROR
+}
+
+// Returns true if the exception is a fatal error
+func (e *MagickError) IsFatal() bool {
+	return e.Severity >= EXCEPTION_FATAL_ERROR
 }
 
 // Clears any exceptions associated with the wand
@@ -29,23 +56,87 @@ func (mw *MagickWand) clearException() bool {
 	return 1 == C.int(C.MagickClearException(mw.mw))
 }
 
-// Returns the kind, reason and description of any error that occurs when using other methods in this API
+// Returns the kind, reason and description of any error that occurs when using other methods in this API.
+// The returned error is a *MagickError.
+//
+// Methods of the wand return the exception raised by their own operation,
+// so GetLastError is only needed after methods which return no error.
 func (mw *MagickWand) GetLastError() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.lastError()
+}
+
+// Returns and clears the pending exception of the wand. The caller holds
+// mw.mu, so that the exception is the one raised by its own operation.
+func (mw *MagickWand) lastError() error {
 	var et C.ExceptionType
 	csdescription := C.MagickGetException(mw.mw, &et)
 	defer relinquishMemory(unsafe.Pointer(csdescription))
 	if ExceptionType(et) != EXCEPTION_UNDEFINED {
 		mw.clearException()
-		return &MagickWandException{ExceptionType(C.int(et)), C.GoString(csdescription)}
+		return &MagickError{ExceptionType(C.int(et)), C.GoString(csdescription)}
 	}
 	runtime.KeepAlive(mw)
 	return nil
 }
 
+// Returns the wand exception when ok is false. Several MagickWand functions
+// fail without raising an exception, in which case a generic error naming
+// the failed method is returned instead of nil. A warning raised by a
+// successful operation is cleared, so that it is not returned by the next
+// one.
 func (mw *MagickWand) getLastErrorIfFailed(ok C.MagickBooleanType) error {
-	if C.int(ok) == 0 {
-		return mw.GetLastError()
-	} else {
+	err := mw.lastError()
+	if C.int(ok) != 0 {
 		return nil
 	}
+	if err != nil {
+		return err
+	}
+	return &MagickError{ERROR_WAND, callerName(1) + ": operation failed"}
+}
+
+// Like getLastErrorIfFailed, but also returns a pending warning when the
+// operation succeeded, e.g. a CorruptImageWarning for a truncated JPEG
+// which still decoded. The returned error satisfies IsWarning() in that case. The caller
+// clears the exception before the operation, so that an exception left by
+// a method without an error result is not returned.
+func (mw *MagickWand) getLastErrorOrWarning(ok C.MagickBooleanType) error {
+	if err := mw.lastError(); err != nil || C.int(ok) != 0 {
+		return err
+	}
+	return &MagickError{ERROR_WAND, callerName(1) + ": operation failed"}
+}
+
+// Returns the result of a predicate, or the exception it raised if any, so
+// that a failed call is not mistaken for false. Warnings are ignored. The
+// caller holds mw.mu and clears the exception before the predicate.
+func (mw *MagickWand) checkedBool(ok C.MagickBooleanType) (bool, error) {
+	err := mw.lastError()
+	if merr, isMagick := err.(*MagickError); err != nil && !(isMagick && merr.IsWarning()) {
+		return false, err
+	}
+	return C.int(ok) != 0, nil
+}
+
+// Returns the short name of the function skip frames above the caller of
+// callerName, e.g. "ResizeImage" for (*MagickWand).ResizeImage
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
 }
@@ -7,21 +7,42 @@ package imagick
 /*
 #include <unistd.h>
 #include <wand/MagickWand.h>
+
+// MagickCompositeImageGravity was added in ImageMagick 6.8.0
+#if MagickLibVersion < 0x680
+#define HAVE_COMPOSITE_IMAGE_GRAVITY 0
+static MagickBooleanType MagickCompositeImageGravity(MagickWand *wand,
+	const MagickWand *source_wand, const CompositeOperator compose,
+	const GravityType gravity) {
+	return MagickFalse;
+}
+#else
+#define HAVE_COMPOSITE_IMAGE_GRAVITY 1
+#endif
 */
 import "C"
 
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"unsafe"
 )
 
 // Returns the current image from the magick wand
 func (mw *MagickWand) GetImageFromMagickWand() *Image {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	return &Image{C.GetImageFromMagickWand(mw.mw)}
 }
 
@@ -36,6 +57,11 @@ func (mw *MagickWand) GetImageFromMagickWand() *Image {
 // sigma: the standard deviation of the Gaussian, in pixels
 //
 func (mw *MagickWand) AdaptiveBlurImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveBlurImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -51,12 +77,22 @@ func (mw *MagickWand) AdaptiveBlurImage(radius, sigma float64) error {
 // sigma: the standard deviation of the Gaussian, in pixels
 //
 func (mw *MagickWand) AdaptiveBlurImageChannel(channel ChannelType, radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Adaptively resize image with data dependent triangulation
 func (mw *MagickWand) AdaptiveResizeImage(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveResizeImage(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -72,6 +108,11 @@ func (mw *MagickWand) AdaptiveResizeImage(cols, rows uint) error {
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) AdaptiveSharpenImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveSharpenImage(mw.mw, C.double(radius), C.double(sigma))
 	runtime.KeepAlive(mw)
 	return mw.getLastErrorIfFailed(ok)
@@ -88,6 +129,11 @@ func (mw *MagickWand) AdaptiveSharpenImage(radius, sigma float64) error {
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) AdaptiveSharpenImageChannel(channel ChannelType, radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveSharpenImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -97,6 +143,11 @@ func (mw *MagickWand) AdaptiveSharpenImageChannel(channel ChannelType, radius, s
 // of an image whose global intensity histogram doesn't contain distinctive
 // peaks.
 func (mw *MagickWand) AdaptiveThresholdImage(width, height uint, offset int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAdaptiveThresholdImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(offset))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -112,6 +163,14 @@ func (mw *MagickWand) AdaptiveThresholdImage(width, height uint, offset int) err
 // previously added images. Caution is advised when multiple image adds are
 // inserted into the middle of the wand image list.
 func (mw *MagickWand) AddImage(wand *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := wand.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAddImage(mw.mw, wand.mw)
 	runtime.KeepAlive(wand)
 	return mw.getLastErrorIfFailed(ok)
@@ -119,18 +178,33 @@ func (mw *MagickWand) AddImage(wand *MagickWand) error {
 
 // Adds random noise to the image
 func (mw *MagickWand) AddNoiseImage(noiseType NoiseType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAddNoiseImage(mw.mw, C.NoiseType(noiseType))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Adds random noise to the image's channel
 func (mw *MagickWand) AddNoiseImageChannel(channel ChannelType, noiseType NoiseType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAddNoiseImageChannel(mw.mw, C.ChannelType(channel), C.NoiseType(noiseType))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Transforms an image as dictaded by the affine matrix of the drawing wand
 func (mw *MagickWand) AffineTransformImage(drawingWand *DrawingWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAffineTransformImage(mw.mw, drawingWand.dw)
 	runtime.KeepAlive(drawingWand)
 	return mw.getLastErrorIfFailed(ok)
@@ -145,6 +219,11 @@ func (mw *MagickWand) AffineTransformImage(drawingWand *DrawingWand) error {
 // angle: rotate text relative to this angle
 //
 func (mw *MagickWand) AnnotateImage(drawingWand *DrawingWand, x, y, angle float64, text string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstext := C.CString(text)
 	defer C.free(unsafe.Pointer(cstext))
 	ok := C.MagickAnnotateImage(mw.mw, drawingWand.dw, C.double(x), C.double(y), C.double(angle), cstext)
@@ -153,8 +232,25 @@ func (mw *MagickWand) AnnotateImage(drawingWand *DrawingWand, x, y, angle float6
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Annotates an image with text placed according to gravity, e.g.
+// GRAVITY_SOUTH_EAST with an offset of 12, 12 for a copyright notice 12
+// pixels from the bottom right corner. The offset moves the text away from
+// the edge it is anchored to, multi-line text is anchored as a whole block.
+// The gravity of dw is left unchanged.
+func (mw *MagickWand) AnnotateImageGravity(dw *DrawingWand, gravity GravityType, offsetX, offsetY, angle float64, text string) error {
+	clone := dw.Clone()
+	defer clone.Destroy()
+	clone.SetGravity(gravity)
+	return mw.AnnotateImage(clone, offsetX, offsetY, angle, text)
+}
+
 // Animates an image or image sequence
 func (mw *MagickWand) AnimateImages(server string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csserver := C.CString(server)
 	defer C.free(unsafe.Pointer(csserver))
 	ok := C.MagickAnimateImages(mw.mw, csserver)
@@ -168,15 +264,25 @@ func (mw *MagickWand) AnimateImages(server string) error {
 // that all the images in the wand's image list will be appended together.
 // By default, images are stacked left-to-right. Set topToBottom to true to
 // stack them top-to-bottom.
-func (mw *MagickWand) AppendImages(topToBottom bool) *MagickWand {
-	ret := newMagickWand(C.MagickAppendImages(mw.mw, b2i(topToBottom)))
+func (mw *MagickWand) AppendImages(topToBottom bool) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickAppendImages(mw.mw, b2i(topToBottom)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Extracts the 'mean' from the image and adjust the image to try make set
 // it's gamma appropriatally
 func (mw *MagickWand) AutoGammaImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAutoGammaImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -184,6 +290,11 @@ func (mw *MagickWand) AutoGammaImage() error {
 // Extracts the 'mean' from the image's channel and adjust the image to try
 // make set it's gamma appropriatally
 func (mw *MagickWand) AutoGammaImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAutoGammaImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -191,6 +302,11 @@ func (mw *MagickWand) AutoGammaImageChannel(channel ChannelType) error {
 // Adjust the levels of a particular image by scaling the minimum and maximum
 // values to the full quantum range.
 func (mw *MagickWand) AutoLevelImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAutoLevelImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -198,6 +314,11 @@ func (mw *MagickWand) AutoLevelImage() error {
 // Adjust the levels of a particular image channel by scaling the minimum and
 // maximum values to the full quantum range.
 func (mw *MagickWand) AutoLevelImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickAutoLevelImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -205,6 +326,11 @@ func (mw *MagickWand) AutoLevelImageChannel(channel ChannelType) error {
 // This is like ThresholdImage() but forces all pixels below the threshold
 // into black while leaving all pixels above the threshold unchanged.
 func (mw *MagickWand) BlackThresholdImage(threshold *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBlackThresholdImage(mw.mw, threshold.pw)
 	runtime.KeepAlive(threshold)
 	return mw.getLastErrorIfFailed(ok)
@@ -213,6 +339,11 @@ func (mw *MagickWand) BlackThresholdImage(threshold *PixelWand) error {
 // Mutes the colors of the image to simulate a scene at nighttime in the
 // moonlight.
 func (mw *MagickWand) BlueShiftImage(factor float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBlueShiftImage(mw.mw, C.double(factor))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -227,6 +358,11 @@ func (mw *MagickWand) BlueShiftImage(factor float64) error {
 // sigma: the standard deviation of the, in pixels
 //
 func (mw *MagickWand) BlurImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBlurImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -241,6 +377,11 @@ func (mw *MagickWand) BlurImage(radius, sigma float64) error {
 // sigma: the standard deviation of the, in pixels
 //
 func (mw *MagickWand) BlurImageChannel(channel ChannelType, radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -248,6 +389,11 @@ func (mw *MagickWand) BlurImageChannel(channel ChannelType, radius, sigma float6
 // Surrounds the image with a border of the color defined by the bordercolor
 // pixel wand.
 func (mw *MagickWand) BorderImage(borderColor *PixelWand, width, height uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBorderImage(mw.mw, borderColor.pw, C.size_t(width), C.size_t(height))
 	runtime.KeepAlive(borderColor)
 	return mw.getLastErrorIfFailed(ok)
@@ -261,6 +407,11 @@ func (mw *MagickWand) BorderImage(borderColor *PixelWand, width, height uint) er
 // contrast: the brightness percent (-100 .. 100)
 //
 func (mw *MagickWand) BrightnessContrastImage(brightness, contrast float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBrightnessContrastImage(mw.mw, C.double(brightness), C.double(contrast))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -273,6 +424,11 @@ func (mw *MagickWand) BrightnessContrastImage(brightness, contrast float64) erro
 // contrast: the brightness percent (-100 .. 100)
 //
 func (mw *MagickWand) BrightnessContrastImageChannel(channel ChannelType, brightness, contrast float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickBrightnessContrastImageChannel(mw.mw, C.ChannelType(channel), C.double(brightness), C.double(contrast))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -284,6 +440,11 @@ func (mw *MagickWand) BrightnessContrastImageChannel(channel ChannelType, bright
 // sigma: the standard deviation of the Gaussian, in pixels
 //
 func (mw *MagickWand) CharcoalImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCharcoalImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -296,24 +457,60 @@ func (mw *MagickWand) CharcoalImage(radius, sigma float64) error {
 // x, y: the region x and y offsets
 //
 func (mw *MagickWand) ChopImage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickChopImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Removes a band from the side of the image given by gravity, e.g. a header
+// with GRAVITY_NORTH. North and south bands take a height and a width of 0,
+// east and west bands the reverse. Other gravities remove width columns and
+// height rows at the respective corner, or through the center.
+func (mw *MagickWand) ChopImageGravity(width, height uint, gravity GravityType) error {
+	if err := checkBandGravity(width, height, gravity); err != nil {
+		return fmt.Errorf("ChopImageGravity: %s", err)
+	}
+	imageWidth, imageHeight := mw.GetImageWidth(), mw.GetImageHeight()
+	if width >= imageWidth || height >= imageHeight {
+		return fmt.Errorf("ChopImageGravity: cannot remove %dx%d from a %dx%d image", width, height, imageWidth, imageHeight)
+	}
+	x, y := gravityOffset(imageWidth, imageHeight, width, height, gravity)
+	return mw.ChopImage(width, height, x, y)
+}
+
 // Restricts the color range from 0 to the quantum depth
 func (mw *MagickWand) ClampImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickClampImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Restricts the color range from 0 to the quantum depth
 func (mw *MagickWand) ClampImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickClampImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Clips along the first path from the 8BIM profile, if present
 func (mw *MagickWand) ClipImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickClipImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -327,6 +524,11 @@ func (mw *MagickWand) ClipImage() error {
 // inside: if true, later operations take effect inside clipping path. Otherwise
 // later operations take effect outside clipping path.
 func (mw *MagickWand) ClipImagePath(pathname string, inside bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspathname := C.CString(pathname)
 	defer C.free(unsafe.Pointer(cspathname))
 	ok := C.MagickClipImagePath(mw.mw, cspathname, b2i(inside))
@@ -335,6 +537,14 @@ func (mw *MagickWand) ClipImagePath(pathname string, inside bool) error {
 
 // Replaces colors in the image from a color lookup table
 func (mw *MagickWand) ClutImage(clut *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := clut.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickClutImage(mw.mw, clut.mw)
 	runtime.KeepAlive(clut)
 	return mw.getLastErrorIfFailed(ok)
@@ -342,6 +552,14 @@ func (mw *MagickWand) ClutImage(clut *MagickWand) error {
 
 // Replaces colors in the image's channel from a color lookup table
 func (mw *MagickWand) ClutImageChannel(channel ChannelType, clut *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := clut.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickClutImageChannel(mw.mw, C.ChannelType(channel), clut.mw)
 	runtime.KeepAlive(clut)
 	return mw.getLastErrorIfFailed(ok)
@@ -353,8 +571,13 @@ func (mw *MagickWand) ClutImageChannel(channel ChannelType, clut *MagickWand) er
 // CoalesceImages() returns a new sequence where each image in the sequence
 // is the same size as the first and composited with the next image in the
 // sequence.
-func (mw *MagickWand) CoalesceImages() *MagickWand {
-	return newMagickWand(C.MagickCoalesceImages(mw.mw))
+func (mw *MagickWand) CoalesceImages() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.resultWand(C.MagickCoalesceImages(mw.mw))
 }
 
 // Accepts a lightweight Color Correction Collection (CCC) file which solely
@@ -375,6 +598,11 @@ func (mw *MagickWand) CoalesceImages() *MagickWand {
 //    </colorcorrectioncollection>
 //
 func (mw *MagickWand) ColorDecisionListImage(cccXML string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cscccXML := C.CString(cccXML)
 	defer C.free(unsafe.Pointer(cscccXML))
 	ok := C.MagickColorDecisionListImage(mw.mw, cscccXML)
@@ -383,6 +611,11 @@ func (mw *MagickWand) ColorDecisionListImage(cccXML string) error {
 
 // Blends the fill color with each pixel in the image
 func (mw *MagickWand) ColorizeImage(colorize, opacity *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickColorizeImage(mw.mw, colorize.pw, opacity.pw)
 	runtime.KeepAlive(colorize)
 	runtime.KeepAlive(opacity)
@@ -397,6 +630,11 @@ func (mw *MagickWand) ColorizeImage(colorize, opacity *PixelWand) error {
 // are in column 6 rather than 5 (in support of CMYKA images) and offsets are
 // normalized (divide Flash offset by 255).
 func (mw *MagickWand) ColorMatrixImage(colorMatrix *KernelInfo) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickColorMatrixImage(mw.mw, colorMatrix.info)
 	runtime.KeepAlive(colorMatrix)
 	return mw.getLastErrorIfFailed(ok)
@@ -406,14 +644,24 @@ func (mw *MagickWand) ColorMatrixImage(colorMatrix *KernelInfo) error {
 // the pixels of each image in the sequence is assigned in order to the
 // specified hannels of the combined image. The typical ordering would be
 // image 1 => Red, 2 => Green, 3 => Blue, etc.
-func (mw *MagickWand) CombineImages(channel ChannelType) *MagickWand {
-	ret := newMagickWand(C.MagickCombineImages(mw.mw, C.ChannelType(channel)))
+func (mw *MagickWand) CombineImages(channel ChannelType) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickCombineImages(mw.mw, C.ChannelType(channel)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Adds a comment to your image
 func (mw *MagickWand) CommentImage(comment string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cscomment := C.CString(comment)
 	defer C.free(unsafe.Pointer(cscomment))
 	ok := C.MagickCommentImage(mw.mw, cscomment)
@@ -422,24 +670,46 @@ func (mw *MagickWand) CommentImage(comment string) error {
 
 // Compares one or more image channels of an image to a reconstructed image
 // and returns the difference image
-func (mw *MagickWand) CompareImageChannels(reference *MagickWand, channel ChannelType, metric MetricType) (wand *MagickWand, distortion float64) {
+func (mw *MagickWand) CompareImageChannels(reference *MagickWand, channel ChannelType, metric MetricType) (wand *MagickWand, distortion float64, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, 0, err
+	}
+	if err := reference.valid(); err != nil {
+		return nil, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cmw := C.MagickCompareImageChannels(mw.mw, reference.mw, C.ChannelType(channel), C.MetricType(metric), (*C.double)(&distortion))
-	wand = newMagickWand(cmw)
+	runtime.KeepAlive(reference)
+	wand, err = mw.resultWand(cmw)
 	return
 }
 
 // Compares each image with the next in a sequence and returns the maximum
 // bounding region of any pixel differences it discovers.
-func (mw *MagickWand) CompareImageLayers(method ImageLayerMethod) *MagickWand {
-	return newMagickWand(C.MagickCompareImageLayers(mw.mw, C.ImageLayerMethod(method)))
+func (mw *MagickWand) CompareImageLayers(method ImageLayerMethod) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.resultWand(C.MagickCompareImageLayers(mw.mw, C.ImageLayerMethod(method)))
 }
 
 // CompareImages() compares an image to a reconstructed image and returns the
 // specified difference image. Returns the new MagickWand and the computed
 // distortion between the images
-func (mw *MagickWand) CompareImages(reference *MagickWand, metric MetricType) (wand *MagickWand, distortion float64) {
+func (mw *MagickWand) CompareImages(reference *MagickWand, metric MetricType) (wand *MagickWand, distortion float64, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, 0, err
+	}
+	if err := reference.valid(); err != nil {
+		return nil, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cmw := C.MagickCompareImages(mw.mw, reference.mw, C.MetricType(metric), (*C.double)(&distortion))
-	wand = newMagickWand(cmw)
+	wand, err = mw.resultWand(cmw)
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(reference)
 	return
@@ -455,6 +725,14 @@ func (mw *MagickWand) CompareImages(reference *MagickWand, metric MetricType) (w
 // y: the row offset of the composited image.
 //
 func (mw *MagickWand) CompositeImage(source *MagickWand, compose CompositeOperator, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := source.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCompositeImage(mw.mw, source.mw, C.CompositeOperator(compose), C.ssize_t(x), C.ssize_t(y))
 	runtime.KeepAlive(source)
 	return mw.getLastErrorIfFailed(ok)
@@ -470,6 +748,14 @@ func (mw *MagickWand) CompositeImage(source *MagickWand, compose CompositeOperat
 // y: the row offset of the composited image.
 //
 func (mw *MagickWand) CompositeImageChannel(channel ChannelType, source *MagickWand, compose CompositeOperator, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := source.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCompositeImageChannel(mw.mw, C.ChannelType(channel), source.mw, C.CompositeOperator(compose), C.ssize_t(x), C.ssize_t(y))
 	runtime.KeepAlive(source)
 	return mw.getLastErrorIfFailed(ok)
@@ -481,7 +767,22 @@ func (mw *MagickWand) CompositeImageChannel(channel ChannelType, source *MagickW
 // The default is Over.
 // gravity : positioning gravity.
 //
+// A source image larger than the destination is composited at negative
+// offsets. With ImageMagick older than 6.8.0 the offsets are computed from
+// the image sizes and CompositeImage is used instead.
 func (mw *MagickWand) CompositeImageGravity(source *MagickWand, compose CompositeOperator, gravity GravityType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := source.valid(); err != nil {
+		return err
+	}
+	if C.HAVE_COMPOSITE_IMAGE_GRAVITY == 0 {
+		x, y := gravityOffset(mw.GetImageWidth(), mw.GetImageHeight(), source.GetImageWidth(), source.GetImageHeight(), gravity)
+		return mw.CompositeImage(source, compose, x, y)
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCompositeImageGravity(mw.mw, source.mw, C.CompositeOperator(compose), C.GravityType(gravity))
 	runtime.KeepAlive(source)
 	return mw.getLastErrorIfFailed(ok)
@@ -511,6 +812,14 @@ func (mw *MagickWand) CompositeImageGravity(source *MagickWand, compose Composit
 // compose, x, y: composition arguments
 //
 func (mw *MagickWand) CompositeLayers(source *MagickWand, compose CompositeOperator, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := source.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCompositeLayers(mw.mw, source.mw, C.CompositeOperator(compose), C.ssize_t(x), C.ssize_t(y))
 	runtime.KeepAlive(source)
 	return mw.getLastErrorIfFailed(ok)
@@ -523,6 +832,11 @@ func (mw *MagickWand) CompositeLayers(source *MagickWand, compose CompositeOpera
 // sharpen: increase or decrease image contrast
 //
 func (mw *MagickWand) ContrastImage(sharpen bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickContrastImage(mw.mw, b2i(sharpen))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -531,6 +845,11 @@ func (mw *MagickWand) ContrastImage(sharpen bool) error {
 // span the entire range of colors available. You can also reduce the
 // influence of a particular channel with a gamma value of 0.
 func (mw *MagickWand) ContrastStretchImage(blackPoint, whitePoint float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickContrastStretchImage(mw.mw, C.double(blackPoint), C.double(whitePoint))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -539,6 +858,11 @@ func (mw *MagickWand) ContrastStretchImage(blackPoint, whitePoint float64) error
 // color to span the entire range of colors available. You can also reduce the
 // influence of a particular channel with a gamma value of 0.
 func (mw *MagickWand) ContrastStretchImageChannel(channel ChannelType, blackPoint, whitePoint float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickContrastStretchImageChannel(mw.mw, C.ChannelType(channel), C.double(blackPoint), C.double(whitePoint))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -550,6 +874,11 @@ func (mw *MagickWand) ContrastStretchImageChannel(channel ChannelType, blackPoin
 // kernel: an array of doubles, representing the convolution kernel
 //
 func (mw *MagickWand) ConvolveImage(order uint, kernel []float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickConvolveImage(mw.mw, C.size_t(order), (*C.double)(&kernel[0]))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -561,12 +890,22 @@ func (mw *MagickWand) ConvolveImage(order uint, kernel []float64) error {
 // kernel: an array of doubles, representing the convolution kernel
 //
 func (mw *MagickWand) ConvolveImageChannel(channel ChannelType, order uint, kernel []float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickConvolveImageChannel(mw.mw, C.ChannelType(channel), C.size_t(order), (*C.double)(&kernel[0]))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Extracts a region of the image
 func (mw *MagickWand) CropImage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCropImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -574,6 +913,11 @@ func (mw *MagickWand) CropImage(width, height uint, x, y int) error {
 // Displaces an Image's colormap by a given number of positions. If you cycle
 // the colormap a number of times you can produce a psychodelic effect.
 func (mw *MagickWand) CycleColormapImage(displace int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickCycleColormapImage(mw.mw, C.ssize_t(displace))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -590,24 +934,32 @@ func (mw *MagickWand) CycleColormapImage(displace int) error {
 // M = magenta, K = black, I = intensity (for grayscale), P = pad.
 //
 // pixels: This array of values contain the pixel components as defined by the
-// type.
+// type. It must hold exactly cols*rows*len(pmap) values, PIXEL_UNDEFINED
+// selects the storage type from the type of pixels.
 //
 func (mw *MagickWand) ConstituteImage(cols, rows uint, pmap string, stype StorageType, pixels interface{}) error {
-	cspmap := C.CString(pmap)
-	defer C.free(unsafe.Pointer(cspmap))
-	ptr, calculatedStype, err := pixelInterfaceToPtr(pixels)
-	if err != nil {
+	if err := mw.valid(); err != nil {
 		return err
 	}
-	if stype == PIXEL_UNDEFINED {
-		stype = calculatedStype
+	ptr, stype, err := pixelBuffer("ConstituteImage", cols, rows, pmap, stype, pixels)
+	if err != nil {
+		return err
 	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cspmap := C.CString(pmap)
+	defer C.free(unsafe.Pointer(cspmap))
 	ok := C.MagickConstituteImage(mw.mw, C.size_t(cols), C.size_t(rows), cspmap, C.StorageType(stype), ptr)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Converts cipher pixels to plain pixels
 func (mw *MagickWand) DecipherImage(passphrase string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspassphrase := C.CString(passphrase)
 	defer C.free(unsafe.Pointer(cspassphrase))
 	ok := C.MagickDecipherImage(mw.mw, cspassphrase)
@@ -616,10 +968,15 @@ func (mw *MagickWand) DecipherImage(passphrase string) error {
 
 // Compares each image with the next in a sequence and returns the maximum
 // bouding region of any pixel differences it discovers.
-func (mw *MagickWand) DeconstructImages() *MagickWand {
-	ret := newMagickWand(C.MagickDeconstructImages(mw.mw))
+func (mw *MagickWand) DeconstructImages() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickDeconstructImages(mw.mw))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Removes skew from the image. Skew is an artifact that occurs in scanned
@@ -628,13 +985,45 @@ func (mw *MagickWand) DeconstructImages() *MagickWand {
 // flat when scanned.
 // threshold: separate background from foreground
 func (mw *MagickWand) DeskewImage(threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickDeskewImage(mw.mw, C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Like DeskewImage, but returns the detected skew angle in degrees, which is
+// read from the "deskew:angle" artifact ImageMagick leaves on the image.
+// Set the "deskew:auto-crop" artifact beforehand to crop the result.
+func (mw *MagickWand) DeskewImageAngle(threshold float64) (float64, error) {
+	if err := mw.DeskewImage(threshold); err != nil {
+		return 0, err
+	}
+	value, err := mw.GetImageArtifact("deskew:angle")
+	if errors.Is(err, ErrNotFound) {
+		// Older releases store the angle as an image property
+		value, err = mw.GetImageProperty("deskew:angle")
+	}
+	if err != nil {
+		return 0, err
+	}
+	angle, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("DeskewImageAngle: invalid angle %q", value)
+	}
+	return angle, nil
+}
+
 // Reduces the speckle noise in an image while perserving the edges of the
 // original image.
 func (mw *MagickWand) DespeckleImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickDespeckleImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -650,6 +1039,11 @@ func (mw *MagickWand) DestroyImage(img *Image) *Image {
 
 // Displays and image
 func (mw *MagickWand) DisplayImage(server string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstring := C.CString(server)
 	defer C.free(unsafe.Pointer(cstring))
 	ok := C.MagickDisplayImage(mw.mw, cstring)
@@ -658,6 +1052,11 @@ func (mw *MagickWand) DisplayImage(server string) error {
 
 // Displays and image or image sequence
 func (mw *MagickWand) DisplayImages(server string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstring := C.CString(server)
 	defer C.free(unsafe.Pointer(cstring))
 	ok := C.MagickDisplayImages(mw.mw, cstring)
@@ -690,12 +1089,22 @@ func (mw *MagickWand) DisplayImages(server string) error {
 // bestfit: Attempt to resize destination to fit distorted source.
 //
 func (mw *MagickWand) DistortImage(method DistortImageMethod, args []float64, bestfit bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickDistortImage(mw.mw, C.DistortImageMethod(method), C.size_t(len(args)), (*C.double)(&args[0]), b2i(bestfit))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Renders the drawing wand on the current image
 func (mw *MagickWand) DrawImage(drawingWand *DrawingWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickDrawImage(mw.mw, drawingWand.dw)
 	runtime.KeepAlive(drawingWand)
 	return mw.getLastErrorIfFailed(ok)
@@ -707,6 +1116,11 @@ func (mw *MagickWand) DrawImage(drawingWand *DrawingWand) error {
 // radius: the radius of the pixel neighborhood
 //
 func (mw *MagickWand) EdgeImage(radius float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEdgeImage(mw.mw, C.double(radius))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -721,12 +1135,22 @@ func (mw *MagickWand) EdgeImage(radius float64) error {
 // sigma: the standard deviation of the Gaussian, in pixels
 //
 func (mw *MagickWand) EmbossImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEmbossImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Converts plain pixels to cipher pixels
 func (mw *MagickWand) EncipherImage(passphrase string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspassphrase := C.CString(passphrase)
 	defer C.free(unsafe.Pointer(cspassphrase))
 	ok := C.MagickEncipherImage(mw.mw, cspassphrase)
@@ -735,18 +1159,33 @@ func (mw *MagickWand) EncipherImage(passphrase string) error {
 
 // Applies a digital filter that improves the quality of a noisy image
 func (mw *MagickWand) EnhanceImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEnhanceImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Equalizes the image histogram.
 func (mw *MagickWand) EqualizeImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEqualizeImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Equalizes the image's channel histogram.
 func (mw *MagickWand) EqualizeImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEqualizeImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -755,6 +1194,11 @@ func (mw *MagickWand) EqualizeImageChannel(channel ChannelType) error {
 // Use these operators to lighten or darken an image, to increase or
 // decrease contrast in an image, or to produce the "negative" of an image.
 func (mw *MagickWand) EvaluateImage(op EvaluateOperator, value float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEvaluateImage(mw.mw, C.MagickEvaluateOperator(op), C.double(value))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -763,14 +1207,24 @@ func (mw *MagickWand) EvaluateImage(op EvaluateOperator, value float64) error {
 // Use these operators to lighten or darken an image, to increase or
 // decrease contrast in an image, or to produce the "negative" of an image.
 func (mw *MagickWand) EvaluateImages(op EvaluateOperator) error {
-	C.MagickEvaluateImages(mw.mw, C.MagickEvaluateOperator(op))
-	return mw.GetLastError()
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ok := C.MagickEvaluateImages(mw.mw, C.MagickEvaluateOperator(op))
+	return mw.getLastErrorIfFailed(ok)
 }
 
 // Applys an arithmetic, relational, or logical expression to an image.
 // Use these operators to lighten or darken an image, to increase or
 // decrease contrast in an image, or to produce the "negative" of an image.
 func (mw *MagickWand) EvaluateImageChannel(channel ChannelType, op EvaluateOperator, value float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickEvaluateImageChannel(mw.mw, C.ChannelType(channel), C.MagickEvaluateOperator(op), C.double(value))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -810,6 +1264,9 @@ func (mw *MagickWand) EvaluateImageChannel(channel ChannelType, op EvaluateOpera
 //
 func (mw *MagickWand) ExportImagePixels(x, y int, cols, rows uint,
 	pmap string, stype StorageType) (interface{}, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
 	if len(pmap) == 0 {
 		return nil, errors.New("zero-length pmap not permitted")
 	}
@@ -868,6 +1325,8 @@ func (mw *MagickWand) ExportImagePixels(x, y int, cols, rows uint,
 
 	}
 
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspmap := C.CString(pmap)
 	defer C.free(unsafe.Pointer(cspmap))
 
@@ -881,6 +1340,46 @@ func (mw *MagickWand) ExportImagePixels(x, y int, cols, rows uint,
 	return pixel_iface, mw.getLastErrorIfFailed(ok)
 }
 
+// Like ExportImagePixels, but writes the pixels into dst instead of
+// allocating a new slice, so that a buffer can be reused for every frame of a
+// video. dst must be a slice of the element type of stype, as returned by
+// ExportImagePixels, e.g. []float32 for PIXEL_FLOAT, holding at least
+// cols*rows*len(pmap) values. PIXEL_UNDEFINED selects the storage type from
+// the type of dst.
+func (mw *MagickWand) ExportImagePixelsInto(x, y int, cols, rows uint,
+	pmap string, stype StorageType, dst interface{}) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if len(pmap) == 0 {
+		return errors.New("zero-length pmap not permitted")
+	}
+	if x < 0 || uint(x) > mw.GetImageWidth() ||
+		y < 0 || uint(y) > mw.GetImageHeight() ||
+		cols == 0 || rows == 0 {
+		return errors.New("Args x, y, cols, and rows produces an invalid region <= 0")
+	}
+
+	ptr, length, dstType, err := pixelSlice(dst)
+	if err != nil {
+		return err
+	}
+	if stype, err = matchStorageType("ExportImagePixelsInto", stype, dstType, dst); err != nil {
+		return err
+	}
+	if need := int(cols) * int(rows) * len(pmap); length < need {
+		return fmt.Errorf("ExportImagePixelsInto: %dx%d %s pixels need %d values, got %d", cols, rows, pmap, need, length)
+	}
+
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cspmap := C.CString(pmap)
+	defer C.free(unsafe.Pointer(cspmap))
+	ok := C.MagickExportImagePixels(mw.mw, C.ssize_t(x), C.ssize_t(y), C.size_t(cols), C.size_t(rows),
+		cspmap, C.StorageType(stype), ptr)
+	return mw.getLastErrorIfFailed(ok)
+}
+
 // Extends the image as defined by the geometry, gravitt, and wand background
 // color. Set the (x,y) offset of the geometry to move the original wand
 // relative to the extended wand.
@@ -894,15 +1393,40 @@ func (mw *MagickWand) ExportImagePixels(x, y int, cols, rows uint,
 // y: the region y offset.
 //
 func (mw *MagickWand) ExtentImage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickExtentImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Extends or crops the image to width x height, placing it on the canvas by
+// gravity, e.g. GRAVITY_CENTER to pad it evenly. The new area is filled with
+// background, which also becomes the background color of the image. A nil
+// background keeps the current background color.
+func (mw *MagickWand) ExtentImageGravity(width, height uint, gravity GravityType, background *PixelWand) error {
+	if background != nil {
+		if err := mw.SetImageBackgroundColor(background); err != nil {
+			return err
+		}
+	}
+	x, y := gravityOffset(width, height, mw.GetImageWidth(), mw.GetImageHeight(), gravity)
+	// ExtentImage takes the offset of the canvas relative to the image
+	return mw.ExtentImage(width, height, -x, -y)
+}
+
 // Applies a custom convolution kernel to the image.
 //
 //  kernel: An array of doubles representing the convolution kernel.
 //
 func (mw *MagickWand) FilterImage(kernel *KernelInfo) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFilterImage(mw.mw, kernel.info)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -912,6 +1436,11 @@ func (mw *MagickWand) FilterImage(kernel *KernelInfo) error {
 //  kernel: An array of doubles representing the convolution kernel.
 //
 func (mw *MagickWand) FilterImageChannel(channel ChannelType, kernel *KernelInfo) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFilterImageChannel(mw.mw, C.ChannelType(channel), kernel.info)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -919,6 +1448,11 @@ func (mw *MagickWand) FilterImageChannel(channel ChannelType, kernel *KernelInfo
 // Creates a vertical mirror image by reflecting the pixels around the central
 // x-axis.
 func (mw *MagickWand) FlipImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFlipImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -945,6 +1479,11 @@ func (mw *MagickWand) FlipImage() error {
 // invert: paint any pixel that does not match the target color.
 //
 func (mw *MagickWand) FloodfillPaintImage(channel ChannelType, fill *PixelWand, fuzz float64, borderColor *PixelWand, x, y int, invert bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFloodfillPaintImage(mw.mw, C.ChannelType(channel), fill.pw, C.double(fuzz), borderColor.pw, C.ssize_t(x), C.ssize_t(y), b2i(invert))
 	runtime.KeepAlive(fill)
 	runtime.KeepAlive(borderColor)
@@ -954,6 +1493,11 @@ func (mw *MagickWand) FloodfillPaintImage(channel ChannelType, fill *PixelWand,
 // Creates a horizontal mirror image by reflecting the pixels around the
 // central y-axis.
 func (mw *MagickWand) FlopImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFlopImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -965,6 +1509,11 @@ func (mw *MagickWand) FlopImage() error {
 //
 // real/imaginary image pair.
 func (mw *MagickWand) ForwardFourierTransformImage(magnitude bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickForwardFourierTransformImage(mw.mw, b2i(magnitude))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -985,6 +1534,11 @@ func (mw *MagickWand) ForwardFourierTransformImage(magnitude bool) error {
 // outerBevel: the outer bevel width.
 //
 func (mw *MagickWand) FrameImage(matteColor *PixelWand, width, height uint, innerBevel, outerBevel int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFrameImage(mw.mw, matteColor.pw, C.size_t(width), C.size_t(height), C.ssize_t(innerBevel), C.ssize_t(outerBevel))
 	runtime.KeepAlive(matteColor)
 	return mw.getLastErrorIfFailed(ok)
@@ -994,6 +1548,11 @@ func (mw *MagickWand) FrameImage(matteColor *PixelWand, width, height uint, inne
 // these operators to lighten or darken an image, to increase or decrease
 // contrast in an image, or to produce the "negative" of an image.
 func (mw *MagickWand) FunctionImage(function MagickFunction, args []float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFunctionImage(mw.mw, C.MagickFunction(function), C.size_t(len(args)), (*C.double)(&args[0]))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1002,25 +1561,38 @@ func (mw *MagickWand) FunctionImage(function MagickFunction, args []float64) err
 // channel. Use these operators to lighten or darken an image, to increase or
 // decrease contrast in an image, or to produce the "negative" of an image.
 func (mw *MagickWand) FunctionImageChannel(channel ChannelType, function MagickFunction, args []float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickFunctionImageChannel(mw.mw, C.ChannelType(channel), C.MagickFunction(function), C.size_t(len(args)), (*C.double)(&args[0]))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Evaluate expression for each pixel in the image.
-func (mw *MagickWand) FxImage(expression string) (fxmw *MagickWand, err error) {
+func (mw *MagickWand) FxImage(expression string) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csexpression := C.CString(expression)
 	defer C.free(unsafe.Pointer(csexpression))
-	fxmw = newMagickWand(C.MagickFxImage(mw.mw, csexpression))
-	err = mw.GetLastError()
-	return
+	return mw.resultWand(C.MagickFxImage(mw.mw, csexpression))
 }
 
 // Evaluate expression for each pixel in the image's channel
-func (mw *MagickWand) FxImageChannel(channel ChannelType, expression string) *MagickWand {
+func (mw *MagickWand) FxImageChannel(channel ChannelType, expression string) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csexpression := C.CString(expression)
 	defer C.free(unsafe.Pointer(csexpression))
 
-	return newMagickWand(C.MagickFxImageChannel(mw.mw, C.ChannelType(channel), csexpression))
+	return mw.resultWand(C.MagickFxImageChannel(mw.mw, C.ChannelType(channel), csexpression))
 }
 
 // Gamma-corrects an image. The same image viewed on different devices will
@@ -1030,6 +1602,11 @@ func (mw *MagickWand) FxImageChannel(channel ChannelType, expression string) *Ma
 // Values typically range from 0.8 to 2.3. You can also reduce the influence
 // of a particular channel with a gamma value of 0.
 func (mw *MagickWand) GammaImage(gamma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGammaImage(mw.mw, C.double(gamma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1041,6 +1618,11 @@ func (mw *MagickWand) GammaImage(gamma float64) error {
 // Values typically range from 0.8 to 2.3. You can also reduce the influence
 // of a particular channel with a gamma value of 0.
 func (mw *MagickWand) GammaImageChannel(channel ChannelType, gamma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGammaImageChannel(mw.mw, C.ChannelType(channel), C.double(gamma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1055,6 +1637,11 @@ func (mw *MagickWand) GammaImageChannel(channel ChannelType, gamma float64) erro
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) GaussianBlurImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGaussianBlurImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1069,32 +1656,69 @@ func (mw *MagickWand) GaussianBlurImage(radius, sigma float64) error {
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) GaussianBlurImageChannel(channel ChannelType, radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGaussianBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Gets the image at the current image index.
-func (mw *MagickWand) GetImage() *MagickWand {
-	ret := newMagickWand(C.MagickGetImage(mw.mw))
+func (mw *MagickWand) GetImage() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickGetImage(mw.mw))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Returns false if the image alpha channel is not activated. That is, the
 // image is RGB rather than RGBA or CMYK rather than CMYKA.
 func (mw *MagickWand) GetImageAlphaChannel() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.MagickGetImageAlphaChannel(mw.mw)
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Like GetImageAlphaChannel, but returns an error instead of false if the
+// call failed, e.g. because the wand has no images.
+func (mw *MagickWand) GetImageAlphaChannelChecked() (bool, error) {
+	if err := mw.valid(); err != nil {
+		return false, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.clearException()
+	return mw.checkedBool(C.MagickGetImageAlphaChannel(mw.mw))
+}
+
 // Gets the image clip mask at the current image index.
-func (mw *MagickWand) GetImageClipMask() *MagickWand {
-	return newMagickWand(C.MagickGetImageClipMask(mw.mw))
+func (mw *MagickWand) GetImageClipMask() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.resultWand(C.MagickGetImageClipMask(mw.mw))
 }
 
 // Returns the image background color.
 func (mw *MagickWand) GetImageBackgroundColor() (bgColor *PixelWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cbgcolor := NewPixelWand()
 	ok := C.MagickGetImageBackgroundColor(mw.mw, cbgcolor.pw)
 	return cbgcolor, mw.getLastErrorIfFailed(ok)
@@ -1106,6 +1730,11 @@ func (mw *MagickWand) GetImageBackgroundColor() (bgColor *PixelWand, err error)
 // write to the blob (GIF, JPEG, PNG, etc.). Utilize ResetIterator() to ensure
 // the write is from the beginning of the image sequence.
 func (mw *MagickWand) GetImageBlob() []byte {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	clen := C.size_t(0)
 	csblob := C.MagickGetImageBlob(mw.mw, &clen)
 	defer relinquishMemory(unsafe.Pointer(csblob))
@@ -1121,6 +1750,11 @@ func (mw *MagickWand) GetImageBlob() []byte {
 // multiple images to the same image stream (e.g. JPEG). in this instance,
 // just the first image of the sequence is returned as a blob.
 func (mw *MagickWand) GetImagesBlob() []byte {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	clen := C.size_t(0)
 	csblob := C.MagickGetImagesBlob(mw.mw, &clen)
 	defer relinquishMemory(unsafe.Pointer(csblob))
@@ -1128,13 +1762,74 @@ func (mw *MagickWand) GetImagesBlob() []byte {
 	return C.GoBytes(unsafe.Pointer(csblob), C.int(clen))
 }
 
-// Returns the chromaticy blue primary point for the image.
-//
+// Returns the current image, or with all the image sequence, as a blob.
+// Unlike GetImageBlob and GetImagesBlob it returns the exception raised if
+// the image cannot be encoded.
+func (mw *MagickWand) imageBlob(all bool) ([]byte, error) {
+	csblob, clen, err := mw.encodeBlob(all)
+	if err != nil {
+		return nil, err
+	}
+	defer relinquishMemory(csblob)
+	return C.GoBytes(csblob, C.int(clen)), nil
+}
+
+// Returns the current image, or all the image sequence, encoded in memory
+// allocated by ImageMagick, which the caller relinquishes.
+func (mw *MagickWand) encodeBlob(all bool) (unsafe.Pointer, int, error) {
+	if err := mw.valid(); err != nil {
+		return nil, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	clen := C.size_t(0)
+	var csblob *C.uchar
+	if all {
+		csblob = C.MagickGetImagesBlob(mw.mw, &clen)
+	} else {
+		csblob = C.MagickGetImageBlob(mw.mw, &clen)
+	}
+	runtime.KeepAlive(mw)
+	if csblob == nil {
+		return nil, 0, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	return unsafe.Pointer(csblob), int(clen), nil
+}
+
+// Like GetImageBlob, but returns the blob in the memory ImageMagick encoded
+// it to instead of copying it, which halves the peak memory of writing a
+// large image to a file or network connection. The caller must Free the
+// blob. Returns the exception raised if the image cannot be encoded.
+func (mw *MagickWand) GetImageBlobNoCopy() (*Blob, error) {
+	data, size, err := mw.encodeBlob(false)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(data, size), nil
+}
+
+// Like GetImagesBlob, but returns the blob without copying it, see
+// GetImageBlobNoCopy.
+func (mw *MagickWand) GetImagesBlobNoCopy() (*Blob, error) {
+	data, size, err := mw.encodeBlob(true)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(data, size), nil
+}
+
+// Returns the chromaticy blue primary point for the image.
+//
 // x: the chromaticity blue primary x-point.
 //
 // y: the chromaticity blue primary y-point.
 //
 func (mw *MagickWand) GetImageBluePrimary() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageBluePrimary(mw.mw, (*C.double)(&x), (*C.double)(&y))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1142,6 +1837,11 @@ func (mw *MagickWand) GetImageBluePrimary() (x, y float64, err error) {
 
 // Returns the image border color.
 func (mw *MagickWand) GetImageBorderColor() (borderColor *PixelWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cbc := NewPixelWand()
 	ok := C.MagickGetImageBorderColor(mw.mw, cbc.pw)
 	return cbc, mw.getLastErrorIfFailed(ok)
@@ -1149,12 +1849,25 @@ func (mw *MagickWand) GetImageBorderColor() (borderColor *PixelWand, err error)
 
 // Gets the depth for one or more image channels.
 func (mw *MagickWand) GetImageChannelDepth(channel ChannelType) uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	return uint(C.MagickGetImageChannelDepth(mw.mw, C.ChannelType(channel)))
 }
 
 // Compares one or more image channels of an image to a reconstructed image
 // and returns the specified distortion metrics
 func (mw *MagickWand) GetImageChannelDistortion(reference *MagickWand, channel ChannelType, metric MetricType) (distortion float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, err
+	}
+	if err := reference.valid(); err != nil {
+		return 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageChannelDistortion(mw.mw, reference.mw, C.ChannelType(channel), C.MetricType(metric), (*C.double)(&distortion))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1163,7 +1876,20 @@ func (mw *MagickWand) GetImageChannelDistortion(reference *MagickWand, channel C
 // Compares one or more image channels of an image to a reconstructed image
 // and returns the specified distortion metrics.
 func (mw *MagickWand) GetImageChannelDistortions(reference *MagickWand, metric MetricType) float64 {
+	if mw.valid() != nil {
+		return 0
+	}
+	if reference.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ptrdistortion := C.MagickGetImageChannelDistortions(mw.mw, reference.mw, C.MetricType(metric))
+	runtime.KeepAlive(reference)
+	if ptrdistortion == nil {
+		mw.clearException()
+		return 0
+	}
 	defer relinquishMemory(unsafe.Pointer(ptrdistortion))
 	return float64(*ptrdistortion)
 }
@@ -1179,6 +1905,11 @@ func (mw *MagickWand) GetImageChannelDistortions(reference *MagickWand, metric M
 //   channelFeatures = GetImageChannelFeatures(1);
 //   contrast = channelFeatures[RedChannel].Contrast[0];
 func (mw *MagickWand) GetImageChannelFeatures(distance uint) []ChannelFeatures {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickGetImageChannelFeatures(mw.mw, C.size_t(distance))
 	defer relinquishMemory(unsafe.Pointer(p))
 	var feats []ChannelFeatures
@@ -1196,6 +1927,11 @@ func (mw *MagickWand) GetImageChannelFeatures(distance uint) []ChannelFeatures {
 
 // Gets the kurtosis and skewness of one or more image channels.
 func (mw *MagickWand) GetImageChannelKurtosis(channel ChannelType) (kurtosis, skewness float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageChannelKurtosis(mw.mw, C.ChannelType(channel), (*C.double)(&kurtosis), (*C.double)(&skewness))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1203,6 +1939,11 @@ func (mw *MagickWand) GetImageChannelKurtosis(channel ChannelType) (kurtosis, sk
 
 // Gets the mean and standard deviation of one or more image channels.
 func (mw *MagickWand) GetImageChannelMean(channel ChannelType) (mean, stdev float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageChannelMean(mw.mw, C.ChannelType(channel), (*C.double)(&mean), (*C.double)(&stdev))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1210,6 +1951,11 @@ func (mw *MagickWand) GetImageChannelMean(channel ChannelType) (mean, stdev floa
 
 // Gets the range for one or more image channels.
 func (mw *MagickWand) GetImageChannelRange(channel ChannelType) (min, max float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageChannelRange(mw.mw, C.ChannelType(channel), (*C.double)(&min), (*C.double)(&max))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1222,6 +1968,11 @@ func (mw *MagickWand) GetImageChannelRange(channel ChannelType) (min, max float6
 //    channelStatistics = wand.GetImageChannelStatistics()
 //    redMean = channelStatistics[RedChannel].mean
 func (mw *MagickWand) GetImageChannelStatistics() []ChannelStatistics {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickGetImageChannelStatistics(mw.mw)
 	defer relinquishMemory(unsafe.Pointer(p))
 	var feats []ChannelStatistics
@@ -1239,6 +1990,11 @@ func (mw *MagickWand) GetImageChannelStatistics() []ChannelStatistics {
 
 // Returns the color of the specified colormap index.
 func (mw *MagickWand) GetImageColormapColor(index uint) (color *PixelWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cpw := NewPixelWand()
 	ok := C.MagickGetImageColormapColor(mw.mw, C.size_t(index), cpw.pw)
 	return cpw, mw.getLastErrorIfFailed(ok)
@@ -1246,6 +2002,11 @@ func (mw *MagickWand) GetImageColormapColor(index uint) (color *PixelWand, err e
 
 // Gets the number of unique colors in the image.
 func (mw *MagickWand) GetImageColors() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageColors(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1253,6 +2014,11 @@ func (mw *MagickWand) GetImageColors() uint {
 
 // Gets the image colorspace.
 func (mw *MagickWand) GetImageColorspace() ColorspaceType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := ColorspaceType(C.MagickGetImageColorspace(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1260,6 +2026,11 @@ func (mw *MagickWand) GetImageColorspace() ColorspaceType {
 
 // Returns the composite operator associated with the image.
 func (mw *MagickWand) GetImageCompose() CompositeOperator {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := CompositeOperator(C.MagickGetImageCompose(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1267,6 +2038,11 @@ func (mw *MagickWand) GetImageCompose() CompositeOperator {
 
 // Gets the image compression.
 func (mw *MagickWand) GetImageCompression() CompressionType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := CompressionType(C.MagickGetImageCompression(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1274,6 +2050,11 @@ func (mw *MagickWand) GetImageCompression() CompressionType {
 
 // Gets the image compression quality.
 func (mw *MagickWand) GetImageCompressionQuality() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageCompressionQuality(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1281,6 +2062,11 @@ func (mw *MagickWand) GetImageCompressionQuality() uint {
 
 // Gets the image delay.
 func (mw *MagickWand) GetImageDelay() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageDelay(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1288,6 +2074,11 @@ func (mw *MagickWand) GetImageDelay() uint {
 
 // Gets the image depth.
 func (mw *MagickWand) GetImageDepth() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageDepth(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1296,6 +2087,14 @@ func (mw *MagickWand) GetImageDepth() uint {
 // Compares an image to a reconstructed image and returns the specified
 // distortion metric.
 func (mw *MagickWand) GetImageDistortion(reference *MagickWand, metric MetricType) (distortion float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, err
+	}
+	if err := reference.valid(); err != nil {
+		return 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageDistortion(mw.mw, reference.mw, C.MetricType(metric), (*C.double)(&distortion))
 	runtime.KeepAlive(reference)
 	err = mw.getLastErrorIfFailed(ok)
@@ -1304,6 +2103,11 @@ func (mw *MagickWand) GetImageDistortion(reference *MagickWand, metric MetricTyp
 
 // Gets the image disposal method.
 func (mw *MagickWand) GetImageDispose() DisposeType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := DisposeType(C.MagickGetImageDispose(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1311,6 +2115,11 @@ func (mw *MagickWand) GetImageDispose() DisposeType {
 
 // Gets the image endian.
 func (mw *MagickWand) GetImageEndian() EndianType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := EndianType(C.MagickGetImageEndian(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1318,6 +2127,11 @@ func (mw *MagickWand) GetImageEndian() EndianType {
 
 // Returns the filename of a particular image in a sequence.
 func (mw *MagickWand) GetImageFilename() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickGetImageFilename(mw.mw)
 	defer relinquishMemory(unsafe.Pointer(p))
 	return C.GoString(p)
@@ -1325,6 +2139,11 @@ func (mw *MagickWand) GetImageFilename() string {
 
 // Returns the format of a particular image in a sequence.
 func (mw *MagickWand) GetImageFormat() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickGetImageFormat(mw.mw)
 	runtime.KeepAlive(mw)
 	defer relinquishMemory(unsafe.Pointer(p))
@@ -1333,6 +2152,11 @@ func (mw *MagickWand) GetImageFormat() string {
 
 // Gets the image fuzz.
 func (mw *MagickWand) GetImageFuzz() float64 {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := float64(C.MagickGetImageFuzz(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1340,6 +2164,11 @@ func (mw *MagickWand) GetImageFuzz() float64 {
 
 // Gets the image gamma.
 func (mw *MagickWand) GetImageGamma() float64 {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := float64(C.MagickGetImageGamma(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1347,6 +2176,11 @@ func (mw *MagickWand) GetImageGamma() float64 {
 
 // Gets the image gravity.
 func (mw *MagickWand) GetImageGravity() GravityType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := GravityType(C.MagickGetImageGravity(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1359,6 +2193,11 @@ func (mw *MagickWand) GetImageGravity() GravityType {
 // y: the chromaticity green primary y-point.
 //
 func (mw *MagickWand) GetImageGreenPrimary() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageGreenPrimary(mw.mw, (*C.double)(&x), (*C.double)(&y))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1366,6 +2205,11 @@ func (mw *MagickWand) GetImageGreenPrimary() (x, y float64, err error) {
 
 // Returns the image height.
 func (mw *MagickWand) GetImageHeight() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageHeight(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1376,6 +2220,11 @@ func (mw *MagickWand) GetImageHeight() uint {
 // numberColors: the number of unique colors in the image and the number of
 // pixel wands returned.
 func (mw *MagickWand) GetImageHistogram() (numberColors uint, pws []*PixelWand) {
+	if mw.valid() != nil {
+		return 0, nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cnc := C.size_t(0)
 	p := C.MagickGetImageHistogram(mw.mw, &cnc)
 	defer relinquishMemory(unsafe.Pointer(p))
@@ -1395,6 +2244,11 @@ func (mw *MagickWand) GetImageHistogram() (numberColors uint, pws []*PixelWand)
 
 // Gets the image interlace scheme.
 func (mw *MagickWand) GetImageInterlaceScheme() InterlaceType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := InterlaceType(C.MagickGetImageInterlaceScheme(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1402,6 +2256,11 @@ func (mw *MagickWand) GetImageInterlaceScheme() InterlaceType {
 
 // Returns the interpolation method for the sepcified image.
 func (mw *MagickWand) GetImageInterpolateMethod() InterpolatePixelMethod {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := InterpolatePixelMethod(C.MagickGetImageInterpolateMethod(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1409,6 +2268,11 @@ func (mw *MagickWand) GetImageInterpolateMethod() InterpolatePixelMethod {
 
 // Gets the image iterations.
 func (mw *MagickWand) GetImageIterations() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageIterations(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1416,6 +2280,11 @@ func (mw *MagickWand) GetImageIterations() uint {
 
 // Returns the image length in bytes.
 func (mw *MagickWand) GetImageLength() (length uint, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cl := C.MagickSizeType(0)
 	ok := C.MagickGetImageLength(mw.mw, &cl)
 	return uint(cl), mw.getLastErrorIfFailed(ok)
@@ -1423,6 +2292,11 @@ func (mw *MagickWand) GetImageLength() (length uint, err error) {
 
 // Returns the image matte color.
 func (mw *MagickWand) GetImageMatteColor() (matteColor *PixelWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cptrpw := NewPixelWand()
 	ok := C.MagickGetImageMatteColor(mw.mw, cptrpw.pw)
 	return cptrpw, mw.getLastErrorIfFailed(ok)
@@ -1430,6 +2304,11 @@ func (mw *MagickWand) GetImageMatteColor() (matteColor *PixelWand, err error) {
 
 // Returns the image orientation.
 func (mw *MagickWand) GetImageOrientation() OrientationType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := OrientationType(C.MagickGetImageOrientation(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1442,6 +2321,11 @@ func (mw *MagickWand) GetImageOrientation() OrientationType {
 // x, h: the page x-offset and y-offset.
 //
 func (mw *MagickWand) GetImagePage() (w, h uint, x, y int, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var cw, ch C.size_t
 	var cx, cy C.ssize_t
 	ok := C.MagickGetImagePage(mw.mw, &cw, &ch, &cx, &cy)
@@ -1450,6 +2334,11 @@ func (mw *MagickWand) GetImagePage() (w, h uint, x, y int, err error) {
 
 // Returns the color of the specified pixel.
 func (mw *MagickWand) GetImagePixelColor(x, y int) (color *PixelWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	pw := NewPixelWand()
 	ok := C.MagickGetImagePixelColor(mw.mw, C.ssize_t(x), C.ssize_t(y), pw.pw)
 	return pw, mw.getLastErrorIfFailed(ok)
@@ -1460,20 +2349,35 @@ func (mw *MagickWand) GetImagePixelColor(x, y int) (color *PixelWand, err error)
 // x, y: the chromaticity red primary x/y-point.
 //
 func (mw *MagickWand) GetImageRedPrimary() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var cdx, cdy C.double
 	ok := C.MagickGetImageRedPrimary(mw.mw, &cdx, &cdy)
 	return float64(cdx), float64(cdy), mw.getLastErrorIfFailed(ok)
 }
 
 // Extracts a region of the image and returns it as a a new wand.
-func (mw *MagickWand) GetImageRegion(width uint, height uint, x int, y int) *MagickWand {
-	ret := newMagickWand(C.MagickGetImageRegion(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y)))
+func (mw *MagickWand) GetImageRegion(width uint, height uint, x int, y int) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickGetImageRegion(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Gets the image rendering intent.
 func (mw *MagickWand) GetImageRenderingIntent() RenderingIntent {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := RenderingIntent(C.MagickGetImageRenderingIntent(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1481,6 +2385,11 @@ func (mw *MagickWand) GetImageRenderingIntent() RenderingIntent {
 
 // Gets the image X and Y resolution.
 func (mw *MagickWand) GetImageResolution() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var dx, dy C.double
 	ok := C.MagickGetImageResolution(mw.mw, &dx, &dy)
 	return float64(dx), float64(dy), mw.getLastErrorIfFailed(ok)
@@ -1488,6 +2397,11 @@ func (mw *MagickWand) GetImageResolution() (x, y float64, err error) {
 
 // Gets the image scene.
 func (mw *MagickWand) GetImageScene() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageScene(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1495,6 +2409,11 @@ func (mw *MagickWand) GetImageScene() uint {
 
 // Generates an SHA-256 message digest for the image pixel stream.
 func (mw *MagickWand) GetImageSignature() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickGetImageSignature(mw.mw)
 	defer relinquishMemory(unsafe.Pointer(p))
 	runtime.KeepAlive(mw)
@@ -1503,6 +2422,11 @@ func (mw *MagickWand) GetImageSignature() string {
 
 // Gets the image ticks-per-second.
 func (mw *MagickWand) GetImageTicksPerSecond() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageTicksPerSecond(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1512,6 +2436,11 @@ func (mw *MagickWand) GetImageTicksPerSecond() uint {
 // To ensure the image type matches its potential, use SetImageType():
 // wand.SetImageType(wand.GetImageType())
 func (mw *MagickWand) GetImageType() ImageType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := ImageType(C.MagickGetImageType(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1519,6 +2448,11 @@ func (mw *MagickWand) GetImageType() ImageType {
 
 // Gets the image units of resolution.
 func (mw *MagickWand) GetImageUnits() ResolutionType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := ResolutionType(C.MagickGetImageUnits(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1526,6 +2460,11 @@ func (mw *MagickWand) GetImageUnits() ResolutionType {
 
 // Returns the virtual pixel method for the specified image.
 func (mw *MagickWand) GetImageVirtualPixelMethod() VirtualPixelMethod {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := VirtualPixelMethod(C.MagickGetImageVirtualPixelMethod(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1536,6 +2475,11 @@ func (mw *MagickWand) GetImageVirtualPixelMethod() VirtualPixelMethod {
 // x, y: the chromaticity white x/y-point.
 //
 func (mw *MagickWand) GetImageWhitePoint() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetImageWhitePoint(mw.mw, (*C.double)(&x), (*C.double)(&y))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -1543,6 +2487,11 @@ func (mw *MagickWand) GetImageWhitePoint() (x, y float64, err error) {
 
 // Returns the image width.
 func (mw *MagickWand) GetImageWidth() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetImageWidth(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1550,6 +2499,11 @@ func (mw *MagickWand) GetImageWidth() uint {
 
 // Returns the number of images associated with a magick wand.
 func (mw *MagickWand) GetNumberImages() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetNumberImages(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -1557,25 +2511,44 @@ func (mw *MagickWand) GetNumberImages() uint {
 
 // Gets the image total ink density.
 func (mw *MagickWand) GetImageTotalInkDensity() float64 {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := float64(C.MagickGetImageTotalInkDensity(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
 func (mw *MagickWand) GradientImage(gradientType GradientType, spreadMethod SpreadMethod, startColor string, stopColor string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
 	ppStart := C.PixelPacket{}
 	ppStop := C.PixelPacket{}
 
 	pw := NewPixelWand()
 	defer pw.Destroy()
 	pw.SetColor(startColor)
-	C.SetPixelViaMagickPixel(mw.GetImageFromMagickWand().img, pw.GetMagickColor().mpp, &ppStart)
+	start := pw.GetMagickColor()
 	pw.SetColor(stopColor)
-	C.SetPixelViaMagickPixel(mw.GetImageFromMagickWand().img, pw.GetMagickColor().mpp, &ppStop)
-	ok := C.GradientImage(mw.GetImageFromMagickWand().img,
+	stop := pw.GetMagickColor()
+
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	img := C.GetImageFromMagickWand(mw.mw)
+	if img == nil {
+		return mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	C.SetPixelViaMagickPixel(img, start.mpp, &ppStart)
+	C.SetPixelViaMagickPixel(img, stop.mpp, &ppStop)
+	ok := C.GradientImage(img,
 		C.GradientType(gradientType), C.SpreadMethod(spreadMethod),
 		&ppStart, &ppStop)
 	runtime.KeepAlive(mw)
+	runtime.KeepAlive(start)
+	runtime.KeepAlive(stop)
 	return mw.getLastErrorIfFailed(ok)
 }
 
@@ -1584,6 +2557,14 @@ func (mw *MagickWand) GradientImage(gradientType GradientType, spreadMethod Spre
 // it with the HALD coder. You can apply any color transformation to the Hald
 // image and then use this method to apply the transform to the image.
 func (mw *MagickWand) HaldClutImage(hald *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := hald.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickHaldClutImage(mw.mw, hald.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1593,6 +2574,14 @@ func (mw *MagickWand) HaldClutImage(hald *MagickWand) error {
 // it with the HALD coder. You can apply any color transformation to the Hald
 // image and then use this method to apply the transform to the image.
 func (mw *MagickWand) HaldClutImageChannel(channel ChannelType, hald *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := hald.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickHaldClutImageChannel(mw.mw, C.ChannelType(channel), hald.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1600,22 +2589,61 @@ func (mw *MagickWand) HaldClutImageChannel(channel ChannelType, hald *MagickWand
 // Returns true if the wand has more images when traversing the list in the
 // forward direction
 func (mw *MagickWand) HasNextImage() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.MagickHasNextImage(mw.mw)
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Like HasNextImage, but returns an error instead of false if the call
+// failed, e.g. because the wand has no images.
+func (mw *MagickWand) HasNextImageChecked() (bool, error) {
+	if err := mw.valid(); err != nil {
+		return false, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.clearException()
+	return mw.checkedBool(C.MagickHasNextImage(mw.mw))
+}
+
 // Returns true if the wand has more images when traversing the list in the
 // reverse direction
 func (mw *MagickWand) HasPreviousImage() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.MagickHasPreviousImage(mw.mw)
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Like HasPreviousImage, but returns an error instead of false if the call
+// failed, e.g. because the wand has no images.
+func (mw *MagickWand) HasPreviousImageChecked() (bool, error) {
+	if err := mw.valid(); err != nil {
+		return false, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.clearException()
+	return mw.checkedBool(C.MagickHasPreviousImage(mw.mw))
+}
+
 // Identifies an image by printing its attributes to the file. Attributes
 // include the image width, height, size, and others.
 func (mw *MagickWand) IdentifyImage() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	p := C.MagickIdentifyImage(mw.mw)
 	defer relinquishMemory(unsafe.Pointer(p))
 	runtime.KeepAlive(mw)
@@ -1625,6 +2653,11 @@ func (mw *MagickWand) IdentifyImage() string {
 // Creates a new image that is a copy of an existing one with the image pixels
 // "implode" by the specified percentage.
 func (mw *MagickWand) ImplodeImage(radius float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickImplodeImage(mw.mw, C.double(radius))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1632,45 +2665,90 @@ func (mw *MagickWand) ImplodeImage(radius float64) error {
 // Identifies the type of pixels and returns the storage type and an
 // unsafe pointer to the data.
 func pixelInterfaceToPtr(pixels interface{}) (unsafe.Pointer, StorageType, error) {
-	var ptr unsafe.Pointer
-	var stype StorageType
+	ptr, length, stype, err := pixelSlice(pixels)
+	if err == nil && length == 0 {
+		err = errors.New("zero-length pixel slice not permitted")
+	}
+	return ptr, stype, err
+}
 
+// Returns the backing array, length and storage type of a pixel slice. The
+// pointer is nil for an empty slice.
+func pixelSlice(pixels interface{}) (ptr unsafe.Pointer, length int, stype StorageType, err error) {
 	switch t := pixels.(type) {
-
 	case []byte:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_CHAR
-
+		length, stype = len(t), PIXEL_CHAR
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	case []float64:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_DOUBLE
-
+		length, stype = len(t), PIXEL_DOUBLE
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	case []float32:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_FLOAT
-
+		length, stype = len(t), PIXEL_FLOAT
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	case []int16:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_SHORT
-
+		length, stype = len(t), PIXEL_SHORT
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
+	case []uint16:
+		length, stype = len(t), PIXEL_SHORT
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	case []int32:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_INTEGER
-
+		length, stype = len(t), PIXEL_INTEGER
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	case []int64:
-		v := &t[0]
-		ptr = unsafe.Pointer(v)
-		stype = PIXEL_LONG
-
+		length, stype = len(t), PIXEL_LONG
+		if length > 0 {
+			ptr = unsafe.Pointer(&t[0])
+		}
 	default:
-		return ptr, PIXEL_UNDEFINED, fmt.Errorf("Type %T is not valid for this operation", t)
+		// reflect rather than %T keeps pixels from escaping to the heap
+		return nil, 0, PIXEL_UNDEFINED, fmt.Errorf("Type %s is not valid for this operation", reflect.TypeOf(pixels))
+	}
+	return ptr, length, stype, nil
+}
+
+// Returns the storage type of a pixel slice of type sliceType, checking it
+// against the requested stype. PIXEL_UNDEFINED accepts any slice type.
+func matchStorageType(op string, stype, sliceType StorageType, pixels interface{}) (StorageType, error) {
+	if stype == PIXEL_UNDEFINED || stype == sliceType {
+		return sliceType, nil
 	}
+	return PIXEL_UNDEFINED, fmt.Errorf("%s: storage type %d does not match a %s", op, stype, reflect.TypeOf(pixels))
+}
 
+// Returns a pointer to pixels and their storage type after checking that
+// they hold exactly cols*rows pixels of pmap.
+func pixelBuffer(op string, cols, rows uint, pmap string, stype StorageType, pixels interface{}) (unsafe.Pointer, StorageType, error) {
+	if len(pmap) == 0 {
+		return nil, PIXEL_UNDEFINED, fmt.Errorf("%s: zero-length pmap not permitted", op)
+	}
+	// Every character, including the P pad, is one value per pixel
+	if i := strings.IndexFunc(pmap, func(r rune) bool {
+		return !strings.ContainsRune("RGBAOCYMKIPrgbaocymkip", r)
+	}); i >= 0 {
+		return nil, PIXEL_UNDEFINED, fmt.Errorf("%s: invalid pmap component %q in %q, use R, G, B, A, O, C, Y, M, K, I or P", op, pmap[i], pmap)
+	}
+	ptr, length, sliceType, err := pixelSlice(pixels)
+	if err != nil {
+		return nil, PIXEL_UNDEFINED, err
+	}
+	if stype, err = matchStorageType(op, stype, sliceType, pixels); err != nil {
+		return nil, PIXEL_UNDEFINED, err
+	}
+	if need := int(cols) * int(rows) * len(pmap); length != need || need == 0 {
+		return nil, PIXEL_UNDEFINED, fmt.Errorf("%s: %dx%d %s pixels need %d values, got %d", op, cols, rows, pmap, need, length)
+	}
 	return ptr, stype, nil
 }
 
@@ -1701,31 +2779,48 @@ func pixelInterfaceToPtr(pixels interface{}) (unsafe.Pointer, StorageType, error
 //     PIXEL_DOUBLE
 //
 // pixels: This slice of values contain the pixel components as defined by map
-// and type. You must pre-allocate this slice where the expected length varies
-// depending on the values of width, height, map, and type.
+// and type. It must be a []byte, []int16, []uint16, []int32, []int64,
+// []float32 or []float64 matching stype, and hold exactly
+// cols*rows*len(pmap) values. PIXEL_UNDEFINED selects the storage type from
+// the type of pixels.
 //
 func (mw *MagickWand) ImportImagePixels(x, y int, cols, rows uint, pmap string,
 	stype StorageType, pixels interface{}) error {
-
-	cspmap := C.CString(pmap)
-	defer C.free(unsafe.Pointer(cspmap))
-
-	var ptr unsafe.Pointer
-
-	ptr, calculatedStype, err := pixelInterfaceToPtr(pixels)
-	if err != nil {
+	if err := mw.valid(); err != nil {
 		return err
 	}
-	if stype == PIXEL_UNDEFINED {
-		stype = calculatedStype
+	ptr, stype, err := pixelBuffer("ImportImagePixels", cols, rows, pmap, stype, pixels)
+	if err != nil {
+		return err
 	}
 
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	cspmap := C.CString(pmap)
+	defer C.free(unsafe.Pointer(cspmap))
+
 	ok := C.MagickImportImagePixels(mw.mw, C.ssize_t(x), C.ssize_t(y), C.size_t(cols),
 		C.size_t(rows), cspmap, C.StorageType(stype), ptr)
 
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Like ImportImagePixels with PIXEL_CHAR pixels, from 0 to 255
+func (mw *MagickWand) ImportImagePixelsFromBytes(x, y int, cols, rows uint, pmap string, pixels []byte) error {
+	return mw.ImportImagePixels(x, y, cols, rows, pmap, PIXEL_CHAR, pixels)
+}
+
+// Like ImportImagePixels with PIXEL_FLOAT pixels, normalized to [0..1]
+func (mw *MagickWand) ImportImagePixelsFromFloat32(x, y int, cols, rows uint, pmap string, pixels []float32) error {
+	return mw.ImportImagePixels(x, y, cols, rows, pmap, PIXEL_FLOAT, pixels)
+}
+
+// Like ImportImagePixels with PIXEL_DOUBLE pixels, normalized to [0..1]
+func (mw *MagickWand) ImportImagePixelsFromFloat64(x, y int, cols, rows uint, pmap string, pixels []float64) error {
+	return mw.ImportImagePixels(x, y, cols, rows, pmap, PIXEL_DOUBLE, pixels)
+}
+
 // Implements the inverse discrete Fourier transform (DFT) of the image either
 // as a magnitude/phase or real/imaginary image pair.
 //
@@ -1737,12 +2832,25 @@ func (mw *MagickWand) ImportImagePixels(x, y int, cols, rows uint, pmap string,
 // real/imaginary image pair.
 //
 func (mw *MagickWand) InverseFourierTransformImage(phaseWand *MagickWand, magnitude bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := phaseWand.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickInverseFourierTransformImage(mw.mw, phaseWand.mw, b2i(magnitude))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Adds a label to your image.
 func (mw *MagickWand) LabelImage(label string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cslabel := C.CString(label)
 	defer C.free(unsafe.Pointer(cslabel))
 	ok := C.MagickLabelImage(mw.mw, cslabel)
@@ -1757,6 +2865,11 @@ func (mw *MagickWand) LabelImage(label string) error {
 // apply to the image. White point specifies the lightest color in the image.
 // Colors brighter than the white point are set to the maximum quantum value.
 func (mw *MagickWand) LevelImage(blackPoint, gamma, whitePoint float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickLevelImage(mw.mw, C.double(blackPoint), C.double(gamma), C.double(whitePoint))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1769,6 +2882,11 @@ func (mw *MagickWand) LevelImage(blackPoint, gamma, whitePoint float64) error {
 // apply to the image. White point specifies the lightest color in the image.
 // Colors brighter than the white point are set to the maximum quantum value.
 func (mw *MagickWand) LevelImageChannel(channel ChannelType, blackPoint, gamma, whitePoint float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickLevelImageChannel(mw.mw, C.ChannelType(channel), C.double(blackPoint), C.double(gamma), C.double(whitePoint))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1776,6 +2894,11 @@ func (mw *MagickWand) LevelImageChannel(channel ChannelType, blackPoint, gamma,
 // Stretches with saturation the image intensity. You can also reduce the
 // influence of a particular channel with a gamma value of 0.
 func (mw *MagickWand) LinearStretchImage(blackPoint, whitePoint float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickLinearStretchImage(mw.mw, C.double(blackPoint), C.double(whitePoint))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1788,7 +2911,17 @@ func (mw *MagickWand) LinearStretchImage(blackPoint, whitePoint float64) error {
 //
 // rigidity: introduce a bias for non-straight seams (typically 0).
 //
+// Returns an error wrapping ErrMissingDelegate if ImageMagick was built
+// without liblqr, see HasFeature("lqr") and LiquidRescaleOrResize().
 func (mw *MagickWand) LiquidRescaleImage(cols, rows uint, deltaX, rigidity float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if err := requireDelegate("LiquidRescaleImage", "lqr"); err != nil {
+		return err
+	}
 	ok := C.MagickLiquidRescaleImage(mw.mw, C.size_t(cols), C.size_t(rows), C.double(deltaX), C.double(rigidity))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1796,6 +2929,11 @@ func (mw *MagickWand) LiquidRescaleImage(cols, rows uint, deltaX, rigidity float
 // This is a convenience method that scales an image proportionally to twice
 // its original size.
 func (mw *MagickWand) MagnifyImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMagnifyImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1815,15 +2953,25 @@ func (mw *MagickWand) MagnifyImage() error {
 // a given virtual canvas. MosaicLayer: Start with the virtual canvas of the
 // first image, enlarging left and right edges to contain all images. Images
 // with negative offsets will be clipped.
-func (mw *MagickWand) MergeImageLayers(method ImageLayerMethod) *MagickWand {
-	ret := newMagickWand(C.MagickMergeImageLayers(mw.mw, C.ImageLayerMethod(method)))
+func (mw *MagickWand) MergeImageLayers(method ImageLayerMethod) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickMergeImageLayers(mw.mw, C.ImageLayerMethod(method)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // This is a convenience method that scales an image proportionally to
 // one-half its original size
 func (mw *MagickWand) MinifyImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMinifyImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1843,6 +2991,11 @@ func (mw *MagickWand) MinifyImage() error {
 // hue: the percent change in hue.
 //
 func (mw *MagickWand) ModulateImage(brightness, saturation, hue float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickModulateImage(mw.mw, C.double(brightness), C.double(saturation), C.double(hue))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1864,7 +3017,12 @@ func (mw *MagickWand) ModulateImage(brightness, saturation, hue float64) error {
 // frame: Surround the image with an ornamental border (e.g. 15x15+3+3). The
 // frame color is that of the thumbnail's matte color.
 //
-func (mw *MagickWand) MontageImage(dw *DrawingWand, tileGeo string, thumbGeo string, mode MontageMode, frame string) *MagickWand {
+func (mw *MagickWand) MontageImage(dw *DrawingWand, tileGeo string, thumbGeo string, mode MontageMode, frame string) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstile := C.CString(tileGeo)
 	defer C.free(unsafe.Pointer(cstile))
 	csthumb := C.CString(thumbGeo)
@@ -1872,10 +3030,10 @@ func (mw *MagickWand) MontageImage(dw *DrawingWand, tileGeo string, thumbGeo str
 	csframe := C.CString(frame)
 	defer C.free(unsafe.Pointer(csframe))
 
-	ret := newMagickWand(C.MagickMontageImage(mw.mw, dw.dw, cstile, csthumb, C.MontageMode(mode), csframe))
+	ret, err := mw.resultWand(C.MagickMontageImage(mw.mw, dw.dw, cstile, csthumb, C.MontageMode(mode), csframe))
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(dw)
-	return ret
+	return ret, err
 }
 
 // Method morphs a set of images. Both the image pixels and size are linearly
@@ -1883,10 +3041,15 @@ func (mw *MagickWand) MontageImage(dw *DrawingWand, tileGeo string, thumbGeo str
 // the next.
 //
 // numFrames: the number of in-between images to generate.
-func (mw *MagickWand) MorphImages(numFrames uint) *MagickWand {
-	ret := newMagickWand(C.MagickMorphImages(mw.mw, C.size_t(numFrames)))
+func (mw *MagickWand) MorphImages(numFrames uint) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickMorphImages(mw.mw, C.size_t(numFrames)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Applies a user supplied kernel to the image according to the given mophology
@@ -1902,6 +3065,11 @@ func (mw *MagickWand) MorphImages(numFrames uint) *MagickWand {
 //
 // kernel: An array of doubles representing the morphology kernel.
 func (mw *MagickWand) MorphologyImage(method MorphologyMethod, iterations int, kernel *KernelInfo) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMorphologyImage(mw.mw, C.MorphologyMethod(method), C.ssize_t(iterations), kernel.info)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1919,6 +3087,11 @@ func (mw *MagickWand) MorphologyImage(method MorphologyMethod, iterations int, k
 //
 // kernel: An array of doubles representing the morphology kernel.
 func (mw *MagickWand) MorphologyImageChannel(channel ChannelType, method MorphologyMethod, iterations int, kernel *KernelInfo) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMorphologyImageChannel(mw.mw, C.ChannelType(channel), C.MorphologyMethod(method), C.ssize_t(iterations), kernel.info)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1936,6 +3109,11 @@ func (mw *MagickWand) MorphologyImageChannel(channel ChannelType, method Morphol
 // angle: apply the effect along this angle.
 //
 func (mw *MagickWand) MotionBlurImage(radius, sigma, angle float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMotionBlurImage(mw.mw, C.double(radius), C.double(sigma), C.double(angle))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1953,6 +3131,11 @@ func (mw *MagickWand) MotionBlurImage(radius, sigma, angle float64) error {
 // angle: apply the effect along this angle.
 //
 func (mw *MagickWand) MotionBlurImageChannel(channel ChannelType, radius, sigma, angle float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickMotionBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma), C.double(angle))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1964,6 +3147,11 @@ func (mw *MagickWand) MotionBlurImageChannel(channel ChannelType, radius, sigma,
 // gray: If true, only negate grayscale pixels within the image.
 //
 func (mw *MagickWand) NegateImage(gray bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickNegateImage(mw.mw, b2i(gray))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1975,6 +3163,11 @@ func (mw *MagickWand) NegateImage(gray bool) error {
 // gray: If true, only negate grayscale pixels within the image.
 //
 func (mw *MagickWand) NegateImageChannel(channel ChannelType, gray bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickNegateImageChannel(mw.mw, C.ChannelType(channel), b2i(gray))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1982,6 +3175,11 @@ func (mw *MagickWand) NegateImageChannel(channel ChannelType, gray bool) error {
 // Adds a blank image canvas of the specified size and background color to the
 // wand.
 func (mw *MagickWand) NewImage(cols uint, rows uint, background *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickNewImage(mw.mw, C.size_t(cols), C.size_t(rows), background.pw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -1996,15 +3194,38 @@ func (mw *MagickWand) NewImage(cols uint, rows uint, background *PixelWand) erro
 // reverse direction, starting with the last image (again). You can jump to
 // this condition immeditally using SetLastIterator().
 func (mw *MagickWand) NextImage() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.MagickNextImage(mw.mw)
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Like NextImage, but returns an error instead of false if the call failed,
+// e.g. because the wand has no images. Reaching the end of the list is not
+// an error.
+func (mw *MagickWand) NextImageChecked() (bool, error) {
+	if err := mw.valid(); err != nil {
+		return false, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.clearException()
+	return mw.checkedBool(C.MagickNextImage(mw.mw))
+}
+
 // Enhances the contrast of a color image by adjusting the pixels color to
 // span the entire range of colors available. You can also reduce the
 // influence of a particular channel with a gamma value of 0.
 func (mw *MagickWand) NormalizeImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickNormalizeImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2013,6 +3234,11 @@ func (mw *MagickWand) NormalizeImage() error {
 // color to span the entire range of colors available. You can also reduce the
 // influence of a particular channel with a gamma value of 0.
 func (mw *MagickWand) NormalizeImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickNormalizeImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2024,6 +3250,11 @@ func (mw *MagickWand) NormalizeImageChannel(channel ChannelType) error {
 // radius: the radius of the circular neighborhood.
 //
 func (mw *MagickWand) OilPaintImage(radius float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickOilPaintImage(mw.mw, C.double(radius))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2044,6 +3275,11 @@ func (mw *MagickWand) OilPaintImage(radius float64) error {
 // invert: paint any pixel that does not match the target color.
 //
 func (mw *MagickWand) OpaquePaintImage(target, fill *PixelWand, fuzz float64, invert bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickOpaquePaintImage(mw.mw, target.pw, fill.pw, C.double(fuzz), b2i(invert))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2064,6 +3300,11 @@ func (mw *MagickWand) OpaquePaintImage(target, fill *PixelWand, fuzz float64, in
 // invert: paint any pixel that does not match the target color.
 //
 func (mw *MagickWand) OpaquePaintImageChannel(channel ChannelType, target, fill *PixelWand, fuzz float64, invert bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickOpaquePaintImageChannel(mw.mw, C.ChannelType(channel), target.pw, fill.pw, C.double(fuzz), b2i(invert))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2071,10 +3312,15 @@ func (mw *MagickWand) OpaquePaintImageChannel(channel ChannelType, target, fill
 // Compares each image the GIF disposed forms of the previous image in the
 // sequence. From this it attempts to select the smallest cropped image to
 // replace each frame, while preserving the results of the animation.
-func (mw *MagickWand) OptimizeImageLayers() *MagickWand {
-	ret := newMagickWand(C.MagickOptimizeImageLayers(mw.mw))
+func (mw *MagickWand) OptimizeImageLayers() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickOptimizeImageLayers(mw.mw))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Unsupported in ImageMagick 6.7.7
@@ -2085,6 +3331,11 @@ func (mw *MagickWand) OptimizeImageLayers() *MagickWand {
 // WARNING: This modifies the current images directly, rather than generate a
 // new image sequence.
 func (mw *MagickWand) OptimizeImageTransparency() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickOptimizeImageTransparency(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2105,6 +3356,11 @@ func (mw *MagickWand) OptimizeImageTransparency() error {
 // checkerboard hash pattern (50 grey) between each color level, to basically
 // double the number of color levels with a bare minimim of dithering.
 func (mw *MagickWand) OrderedPosterizeImage(thresholdMap string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstm := C.CString(thresholdMap)
 	defer C.free(unsafe.Pointer(cstm))
 	ok := C.MagickOrderedPosterizeImage(mw.mw, cstm)
@@ -2127,6 +3383,11 @@ func (mw *MagickWand) OrderedPosterizeImage(thresholdMap string) error {
 // checkerboard hash pattern (50 grey) between each color level, to basically
 // double the number of color levels with a bare minimim of dithering.
 func (mw *MagickWand) OrderedPosterizeImageChannel(channel ChannelType, thresholdMap string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstm := C.CString(thresholdMap)
 	defer C.free(unsafe.Pointer(cstm))
 	ok := C.MagickOrderedPosterizeImageChannel(mw.mw, C.ChannelType(channel), cstm)
@@ -2138,34 +3399,66 @@ func (mw *MagickWand) OrderedPosterizeImageChannel(channel ChannelType, threshol
 // this information from a file without reading the entire image sequence into
 // memory.
 func (mw *MagickWand) PingImage(filename string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if err := mw.checkReadFormat(filename, nil); err != nil {
+		return err
+	}
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
 	ok := C.MagickPingImage(mw.mw, csfilename)
-	return mw.getLastErrorIfFailed(ok)
+	return classifyReadError(mw.getLastErrorIfFailed(ok), "")
 }
 
 // Pings an image or image sequence from a blob.
 func (mw *MagickWand) PingImageBlob(blob []byte) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	if len(blob) == 0 {
 		return errors.New("zero-length blob not permitted")
 	}
+	if err := mw.checkReadFormat("", blob); err != nil {
+		return err
+	}
 	ok := C.MagickPingImageBlob(mw.mw, unsafe.Pointer(&blob[0]), C.size_t(len(blob)))
-	return mw.getLastErrorIfFailed(ok)
+	if err := mw.getLastErrorIfFailed(ok); err != nil {
+		return classifyReadError(err, sniffBlobFormat(blob))
+	}
+	return nil
 }
 
 // Pings an image or image sequence from an open file descriptor.
 func (mw *MagickWand) PingImageFile(img *os.File) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if err := mw.checkReadFormatFile(img); err != nil {
+		return err
+	}
 	file, err := cfdopen(img, "rb")
 	if err != nil {
 		return err
 	}
 	defer C.fclose(file)
 	ok := C.MagickPingImageFile(mw.mw, file)
-	return mw.getLastErrorIfFailed(ok)
+	return classifyReadError(mw.getLastErrorIfFailed(ok), "")
 }
 
 // Simulates a Polaroid picture.
 func (mw *MagickWand) PolaroidImage(dw *DrawingWand, angle float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickPolaroidImage(mw.mw, dw.dw, C.double(angle))
 	runtime.KeepAlive(dw)
 	return mw.getLastErrorIfFailed(ok)
@@ -2180,6 +3473,11 @@ func (mw *MagickWand) PolaroidImage(dw *DrawingWand, angle float64) error {
 // mapped image.
 //
 func (mw *MagickWand) PosterizeImage(levels uint, dither bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickPosterizeImage(mw.mw, C.size_t(levels), b2i(dither))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2187,10 +3485,15 @@ func (mw *MagickWand) PosterizeImage(levels uint, dither bool) error {
 // Tiles 9 thumbnails of the specified image with an image processing
 // operation applied at varying strengths. This helpful to quickly pin-point
 // an appropriate parameter for an image processing operation.
-func (mw *MagickWand) PreviewImages(preview PreviewType) *MagickWand {
-	ret := newMagickWand(C.MagickPreviewImages(mw.mw, C.PreviewType(preview)))
+func (mw *MagickWand) PreviewImages(preview PreviewType) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickPreviewImages(mw.mw, C.PreviewType(preview)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Sets the previous image in the wand as the current image. It is typically
@@ -2204,11 +3507,29 @@ func (mw *MagickWand) PreviewImages(preview PreviewType) *MagickWand {
 // or ReadImages() will be prepended before the first image. In this sense the
 // condition is not quite exactly the same as ResetIterator().
 func (mw *MagickWand) PreviousImage() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.MagickPreviousImage(mw.mw)
 	runtime.KeepAlive(mw)
 	return ret
 }
 
+// Like PreviousImage, but returns an error instead of false if the call
+// failed, e.g. because the wand has no images. Reaching the start of the
+// list is not an error.
+func (mw *MagickWand) PreviousImageChecked() (bool, error) {
+	if err := mw.valid(); err != nil {
+		return false, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.clearException()
+	return mw.checkedBool(C.MagickPreviousImage(mw.mw))
+}
+
 // Analyzes the colors within a reference image and chooses a fixed number of
 // colors to represent the image. The goal of the algorithm is to minimize the
 // color difference between the input and output image while minimizing the
@@ -2238,6 +3559,11 @@ func (mw *MagickWand) PreviousImage() bool {
 // quantized value.
 //
 func (mw *MagickWand) QuantizeImage(numColors uint, colorspace ColorspaceType, treedepth uint, dither bool, measureError bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickQuantizeImage(mw.mw, C.size_t(numColors), C.ColorspaceType(colorspace), C.size_t(treedepth), b2i(dither), b2i(measureError))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2271,6 +3597,11 @@ func (mw *MagickWand) QuantizeImage(numColors uint, colorspace ColorspaceType, t
 // quantized value.
 //
 func (mw *MagickWand) QuantizeImages(numColors uint, colorspace ColorspaceType, treedepth uint, dither bool, measureError bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickQuantizeImages(mw.mw, C.size_t(numColors), C.ColorspaceType(colorspace), C.size_t(treedepth), b2i(dither), b2i(measureError))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2280,6 +3611,11 @@ func (mw *MagickWand) QuantizeImages(numColors uint, colorspace ColorspaceType,
 // angle: the angle of the blur in degrees.
 //
 func (mw *MagickWand) RadialBlurImage(angle float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRotationalBlurImage(mw.mw, C.double(angle))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2289,7 +3625,12 @@ func (mw *MagickWand) RadialBlurImage(angle float64) error {
 // angle: the angle of the blur in degrees.
 //
 func (mw *MagickWand) RadialBlurImageChannel(channel ChannelType, angle float64) error {
-	ok := C.MagickRotationalBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(angle))
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ok := C.MagickRotationalBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(angle))
 	return mw.getLastErrorIfFailed(ok)
 }
 
@@ -2303,6 +3644,11 @@ func (mw *MagickWand) RadialBlurImageChannel(channel ChannelType, angle float64)
 // a lowered effect.
 //
 func (mw *MagickWand) RaiseImage(width uint, height uint, x int, y int, raise bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRaiseImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y), b2i(raise))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2314,6 +3660,11 @@ func (mw *MagickWand) RaiseImage(width uint, height uint, x int, y int, raise bo
 // to QuantumRange.
 //
 func (mw *MagickWand) RandomThresholdImage(low, high float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRandomThresholdImage(mw.mw, C.double(low), C.double(high))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2325,6 +3676,11 @@ func (mw *MagickWand) RandomThresholdImage(low, high float64) error {
 // to QuantumRange.
 //
 func (mw *MagickWand) RandomThresholdImageChannel(channel ChannelType, low, high float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRandomThresholdImageChannel(mw.mw, C.ChannelType(channel), C.double(low), C.double(high))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2333,31 +3689,85 @@ func (mw *MagickWand) RandomThresholdImageChannel(channel ChannelType, low, high
 // image pointer position. Use SetFirstIterator(), SetLastIterator, or
 // SetImageIndex() to specify the current image pointer position at the
 // beginning of the image list, the end, or anywhere in-between respectively.
+//
+// The filename is passed to ImageMagick as is, so format prefixes such as
+// "png:", "@" file lists, "|" pipes and "[0]" scene suffixes are
+// interpreted. Use ReadImageSafe for filenames from untrusted sources.
 func (mw *MagickWand) ReadImage(filename string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if err := mw.checkReadFormat(filename, nil); err != nil {
+		return err
+	}
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
+	mw.clearException()
 	ok := C.MagickReadImage(mw.mw, csfilename)
-	return mw.getLastErrorIfFailed(ok)
+	return classifyReadError(mw.getLastErrorOrWarning(ok), "")
 }
 
 // Reads an image or image sequence from a blob.
+//
+// If the image was read but ImageMagick raised a warning (for instance a
+// truncated JPEG), the returned error is a *MagickError for which IsWarning()
+// is true, and the image is available in the wand. If ImageMagick cannot
+// decode the format, the error is a *MissingDelegateError naming the format
+// detected from the blob.
 func (mw *MagickWand) ReadImageBlob(blob []byte) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	if len(blob) == 0 {
 		return errors.New("zero-length blob not permitted")
 	}
+	if err := mw.checkReadFormat("", blob); err != nil {
+		return err
+	}
+	mw.clearException()
 	ok := C.MagickReadImageBlob(mw.mw, unsafe.Pointer(&blob[0]), C.size_t(len(blob)))
-	return mw.getLastErrorIfFailed(ok)
+	if err := mw.getLastErrorOrWarning(ok); err != nil {
+		return classifyReadError(err, sniffBlobFormat(blob))
+	}
+	return nil
 }
 
 // Reads an image or image sequence from an open file descriptor.
 func (mw *MagickWand) ReadImageFile(img *os.File) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if err := mw.checkReadFormatFile(img); err != nil {
+		return err
+	}
 	file, err := cfdopen(img, "rb")
 	if err != nil {
 		return err
 	}
 	defer C.fclose(file)
+	mw.clearException()
 	ok := C.MagickReadImageFile(mw.mw, file)
-	return mw.getLastErrorIfFailed(ok)
+	return classifyReadError(mw.getLastErrorOrWarning(ok), "")
+}
+
+// Reads an image or image sequence from an io.Reader. The reader is consumed
+// until EOF. As with ReadImageBlob, the format is detected from the data
+// unless it was set with SetFormat().
+func (mw *MagickWand) ReadImageReader(r io.Reader) error {
+	blob, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(blob) == 0 {
+		return errors.New("ReadImageReader: reader returned no data")
+	}
+	return mw.ReadImageBlob(blob)
 }
 
 // Replaces the colors of an image with the closest color from a reference image.
@@ -2365,6 +3775,14 @@ func (mw *MagickWand) ReadImageFile(img *os.File) error {
 // method: choose from these dither methods: NoDitherMethod, RiemersmaDitherMethod, or FloydSteinbergDitherMethod.
 //
 func (mw *MagickWand) RemapImage(remap *MagickWand, method DitherMethod) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := remap.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRemapImage(mw.mw, remap.mw, C.DitherMethod(method))
 	runtime.KeepAlive(remap)
 	return mw.getLastErrorIfFailed(ok)
@@ -2372,10 +3790,34 @@ func (mw *MagickWand) RemapImage(remap *MagickWand, method DitherMethod) error {
 
 // Removes an image from the image list.
 func (mw *MagickWand) RemoveImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRemoveImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Removes the current image from the image list and returns it in a new
+// wand. Afterwards the current image is the one that followed the removed
+// image, or the new last image if the last one was removed. Extracting the
+// only image leaves the wand empty.
+func (mw *MagickWand) ExtractImage() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	frame, err := mw.currentImage()
+	if err != nil {
+		return nil, err
+	}
+	if err := mw.RemoveImage(); err != nil {
+		frame.Destroy()
+		return nil, err
+	}
+	return frame, nil
+}
+
 // Resample image to desired resolution.
 //
 // xRes/yRes: the new image x/y resolution.
@@ -2385,6 +3827,11 @@ func (mw *MagickWand) RemoveImage() error {
 // blur: the blur factor where > 1 is blurry, < 1 is sharp.
 //
 func (mw *MagickWand) ResampleImage(xRes, yRes float64, filter FilterType, blur float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickResampleImage(mw.mw, C.double(xRes), C.double(yRes), C.FilterTypes(filter), C.double(blur))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2392,6 +3839,11 @@ func (mw *MagickWand) ResampleImage(xRes, yRes float64, filter FilterType, blur
 // Resets the Wand page canvas and position.
 // page: the relative page specification.
 func (mw *MagickWand) ResetImagePage(page string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspage := C.CString(page)
 	defer C.free(unsafe.Pointer(cspage))
 	ok := C.MagickResetImagePage(mw.mw, cspage)
@@ -2409,6 +3861,11 @@ func (mw *MagickWand) ResetImagePage(page string) error {
 // blur: the blur factor where > 1 is blurry, < 1 is sharp.
 //
 func (mw *MagickWand) ResizeImage(cols, rows uint, filter FilterType, blur float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickResizeImage(mw.mw, C.size_t(cols), C.size_t(rows), C.FilterTypes(filter), C.double(blur))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2420,6 +3877,11 @@ func (mw *MagickWand) ResizeImage(cols, rows uint, filter FilterType, blur float
 // y: the y offset.
 //
 func (mw *MagickWand) RollImage(x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRollImage(mw.mw, C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2432,6 +3894,11 @@ func (mw *MagickWand) RollImage(x, y int) error {
 // degrees: the number of degrees to rotate the image.
 //
 func (mw *MagickWand) RotateImage(background *PixelWand, degrees float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickRotateImage(mw.mw, background.pw, C.double(degrees))
 	runtime.KeepAlive(background)
 	return mw.getLastErrorIfFailed(ok)
@@ -2441,12 +3908,22 @@ func (mw *MagickWand) RotateImage(background *PixelWand, degrees float64) error
 // scaling methods, this method does not introduce any additional color into
 // the scaled image.
 func (mw *MagickWand) SampleImage(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSampleImage(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Scales the size of an image to the given dimensions.
 func (mw *MagickWand) ScaleImage(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickScaleImage(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2465,6 +3942,11 @@ func (mw *MagickWand) ScaleImage(cols, rows uint) error {
 // smoother second derivative.
 //
 func (mw *MagickWand) SegmentImage(colorspace ColorspaceType, verbose bool, clusterThreshold, smoothThreshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSegmentImage(mw.mw, C.ColorspaceType(colorspace), b2i(verbose), C.double(clusterThreshold), C.double(smoothThreshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2481,6 +3963,11 @@ func (mw *MagickWand) SegmentImage(colorspace ColorspaceType, verbose bool, clus
 // blur operation.
 //
 func (mw *MagickWand) SelectiveBlurImage(radius, sigma, threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSelectiveBlurImage(mw.mw, C.double(radius), C.double(sigma), C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2497,6 +3984,11 @@ func (mw *MagickWand) SelectiveBlurImage(radius, sigma, threshold float64) error
 // blur operation.
 //
 func (mw *MagickWand) SelectiveBlurImageChannel(channel ChannelType, radius, sigma, threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSelectiveBlurImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma), C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2504,10 +3996,54 @@ func (mw *MagickWand) SelectiveBlurImageChannel(channel ChannelType, radius, sig
 // Separates a channel from the image and returns a grayscale image. A channel
 // is a particular color component of each pixel in the image.
 func (mw *MagickWand) SeparateImageChannel(channel ChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSeparateImageChannel(mw.mw, C.ChannelType(channel))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Returns a new wand holding one channel of the current image as a
+// grayscale image, see SeparateImageChannel. The current image is not
+// modified. Returns an error for the alpha channel of an image without
+// alpha.
+func (mw *MagickWand) ChannelImage(channel ChannelType) (*MagickWand, error) {
+	switch channel {
+	case CHANNEL_ALPHA, CHANNEL_TRUE_ALPHA:
+		if !mw.GetImageAlphaChannel() {
+			return nil, errors.New("ChannelImage: image has no alpha channel")
+		}
+	}
+	separated, err := mw.currentImage()
+	if err != nil {
+		return nil, err
+	}
+	if err := separated.SeparateImageChannel(channel); err != nil {
+		separated.Destroy()
+		return nil, err
+	}
+	return separated, nil
+}
+
+// Returns a new wand per channel of the current image, see ChannelImage.
+// On error no wands are returned.
+func (mw *MagickWand) ChannelImages(channels ...ChannelType) ([]*MagickWand, error) {
+	wands := make([]*MagickWand, 0, len(channels))
+	for _, channel := range channels {
+		separated, err := mw.ChannelImage(channel)
+		if err != nil {
+			for _, w := range wands {
+				w.Destroy()
+			}
+			return nil, err
+		}
+		wands = append(wands, separated)
+	}
+	return wands, nil
+}
+
 // Applies a special effect to the image, similar to the effect achieved in a
 // photo darkroom by sepia toning. Threshold ranges from 0 to QuantumRange and
 // is a measure of the extent of the sepia toning. A threshold of 80 is a good
@@ -2516,6 +4052,11 @@ func (mw *MagickWand) SeparateImageChannel(channel ChannelType) error {
 // threshold: Define the extent of the sepia toning.
 //
 func (mw *MagickWand) SepiaToneImage(threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSepiaToneImage(mw.mw, C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2523,6 +4064,14 @@ func (mw *MagickWand) SepiaToneImage(threshold float64) error {
 // Replaces the last image returned by SetImageIndex(), NextImage(),
 // PreviousImage() with the images from the specified wand.
 func (mw *MagickWand) SetImage(source *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := source.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImage(mw.mw, source.mw)
 	runtime.KeepAlive(source)
 	return mw.getLastErrorIfFailed(ok)
@@ -2530,12 +4079,22 @@ func (mw *MagickWand) SetImage(source *MagickWand) error {
 
 // Activates, deactivates, resets, or sets the alpha channel.
 func (mw *MagickWand) SetImageAlphaChannel(act AlphaChannelType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageAlphaChannel(mw.mw, C.AlphaChannelType(act))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image background color.
 func (mw *MagickWand) SetImageBackgroundColor(background *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageBackgroundColor(mw.mw, background.pw)
 	runtime.KeepAlive(background)
 	return mw.getLastErrorIfFailed(ok)
@@ -2544,18 +4103,33 @@ func (mw *MagickWand) SetImageBackgroundColor(background *PixelWand) error {
 // Sets the image bias for any method that convolves an image (e.g.
 // ConvolveImage()).
 func (mw *MagickWand) SetImageBias(bias float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageBias(mw.mw, C.double(bias))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image chromaticity blue primary point.
 func (mw *MagickWand) SetImageBluePrimary(x, y float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageBluePrimary(mw.mw, C.double(x), C.double(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image border color.
 func (mw *MagickWand) SetImageBorderColor(border *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageBorderColor(mw.mw, border.pw)
 	runtime.KeepAlive(border)
 	return mw.getLastErrorIfFailed(ok)
@@ -2566,12 +4140,25 @@ func (mw *MagickWand) SetImageBorderColor(border *PixelWand) error {
 // depth: the image depth in bits.
 //
 func (mw *MagickWand) SetImageChannelDepth(channel ChannelType, depth uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageChannelDepth(mw.mw, C.ChannelType(channel), C.size_t(depth))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets image clip mask.
 func (mw *MagickWand) SetImageClipMask(clipmask *MagickWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if err := clipmask.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageClipMask(mw.mw, clipmask.mw)
 	runtime.KeepAlive(clipmask)
 	return mw.getLastErrorIfFailed(ok)
@@ -2579,6 +4166,11 @@ func (mw *MagickWand) SetImageClipMask(clipmask *MagickWand) error {
 
 // Set the entire wand canvas to the specified color.
 func (mw *MagickWand) SetImageColor(color *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageColor(mw.mw, color.pw)
 	runtime.KeepAlive(color)
 	return mw.getLastErrorIfFailed(ok)
@@ -2591,12 +4183,22 @@ func (mw *MagickWand) SetImageColor(color *PixelWand) error {
 // color: return the colormap color in this wand.
 //
 func (mw *MagickWand) SetImageColormapColor(index uint, color *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageColormapColor(mw.mw, C.size_t(index), color.pw)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image colorspace.
 func (mw *MagickWand) SetImageColorspace(colorspace ColorspaceType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageColorspace(mw.mw, C.ColorspaceType(colorspace))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2604,18 +4206,33 @@ func (mw *MagickWand) SetImageColorspace(colorspace ColorspaceType) error {
 // Sets the image composite operator, useful for specifying how to composite
 /// the image thumbnail when using the MontageImage() method.
 func (mw *MagickWand) SetImageCompose(compose CompositeOperator) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageCompose(mw.mw, C.CompositeOperator(compose))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image compression.
 func (mw *MagickWand) SetImageCompression(compression CompressionType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageCompression(mw.mw, C.CompressionType(compression))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image compression quality.
 func (mw *MagickWand) SetImageCompressionQuality(quality uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageCompressionQuality(mw.mw, C.size_t(quality))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2625,6 +4242,11 @@ func (mw *MagickWand) SetImageCompressionQuality(quality uint) error {
 // delay: the image delay in ticks-per-second units.
 //
 func (mw *MagickWand) SetImageDelay(delay uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageDelay(mw.mw, C.size_t(delay))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2634,18 +4256,33 @@ func (mw *MagickWand) SetImageDelay(delay uint) error {
 // depth: the image depth in bits: 8, 16, or 32.
 //
 func (mw *MagickWand) SetImageDepth(depth uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageDepth(mw.mw, C.size_t(depth))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image disposal method.
 func (mw *MagickWand) SetImageDispose(dispose DisposeType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageDispose(mw.mw, C.DisposeType(dispose))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image endian method.
 func (mw *MagickWand) SetImageEndian(endian EndianType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageEndian(mw.mw, C.EndianType(endian))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2657,12 +4294,22 @@ func (mw *MagickWand) SetImageEndian(endian EndianType) error {
 // rows: The image height in pixels.
 //
 func (mw *MagickWand) SetImageExtent(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageExtent(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the filename of a particular image in a sequence.
 func (mw *MagickWand) SetImageFilename(filename string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
 	ok := C.MagickSetImageFilename(mw.mw, csfilename)
@@ -2674,6 +4321,11 @@ func (mw *MagickWand) SetImageFilename(filename string) error {
 // format: the image format.
 //
 func (mw *MagickWand) SetImageFormat(format string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csformat := C.CString(format)
 	defer C.free(unsafe.Pointer(csformat))
 	ok := C.MagickSetImageFormat(mw.mw, csformat)
@@ -2682,54 +4334,99 @@ func (mw *MagickWand) SetImageFormat(format string) error {
 
 // Sets the image fuzz.
 func (mw *MagickWand) SetImageFuzz(fuzz float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageFuzz(mw.mw, C.double(fuzz))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image gamma.
 func (mw *MagickWand) SetImageGamma(gamma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageGamma(mw.mw, C.double(gamma))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image gravity type.
 func (mw *MagickWand) SetImageGravity(gravity GravityType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageGravity(mw.mw, C.GravityType(gravity))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image chromaticity green primary point.
 func (mw *MagickWand) SetImageGreenPrimary(x, y float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageGreenPrimary(mw.mw, C.double(x), C.double(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image interlace scheme.
 func (mw *MagickWand) SetImageInterlaceScheme(interlace InterlaceType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageInterlaceScheme(mw.mw, C.InterlaceType(interlace))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image interpolate pixel method.
 func (mw *MagickWand) SetImageInterpolateMethod(method InterpolatePixelMethod) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageInterpolateMethod(mw.mw, C.InterpolatePixelMethod(method))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image iterations.
 func (mw *MagickWand) SetImageIterations(iterations uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageIterations(mw.mw, C.size_t(iterations))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image matte channel.
 func (mw *MagickWand) SetImageMatte(matte bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageMatte(mw.mw, b2i(matte))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image matte color.
 func (mw *MagickWand) SetImageMatteColor(matte *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageMatteColor(mw.mw, matte.pw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2740,77 +4437,155 @@ func (mw *MagickWand) SetImageMatteColor(matte *PixelWand) error {
 // transparent.
 //
 func (mw *MagickWand) SetImageOpacity(alpha float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageOpacity(mw.mw, C.double(alpha))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image orientation.
 func (mw *MagickWand) SetImageOrientation(orientation OrientationType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageOrientation(mw.mw, C.OrientationType(orientation))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Auto orient the image
+// Rotates and flips the current image according to its orientation (usually
+// taken from the EXIF data of camera JPEGs), so that it displays correctly
+// in viewers which ignore the orientation. Afterwards the orientation is
+// reset to ORIENTATION_TOP_LEFT and any "exif:Orientation" property is
+// removed, so the image is not rotated a second time.
 func (mw *MagickWand) AutoOrientImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
 	ok := C.MagickAutoOrientImage(mw.mw)
-	return mw.getLastErrorIfFailed(ok)
+	err := mw.getLastErrorIfFailed(ok)
+	mw.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := mw.SetImageOrientation(ORIENTATION_TOP_LEFT); err != nil {
+		return err
+	}
+	// Fails when there is no such property, which is fine
+	mw.DeleteImageProperty("exif:Orientation")
+	return nil
 }
 
 // Sets the page geometry of the image.
 func (mw *MagickWand) SetImagePage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImagePage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image chromaticity red primary point.
 func (mw *MagickWand) SetImageRedPrimary(x, y float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageRedPrimary(mw.mw, C.double(x), C.double(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image rendering intent.
 func (mw *MagickWand) SetImageRenderingIntent(ri RenderingIntent) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageRenderingIntent(mw.mw, C.RenderingIntent(ri))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image resolution.
 func (mw *MagickWand) SetImageResolution(xRes, yRes float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageResolution(mw.mw, C.double(xRes), C.double(yRes))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image scene.
 func (mw *MagickWand) SetImageScene(scene uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageScene(mw.mw, C.size_t(scene))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image ticks-per-second.
 func (mw *MagickWand) SetImageTicksPerSecond(tps int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageTicksPerSecond(mw.mw, C.ssize_t(tps))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image type.
 func (mw *MagickWand) SetImageType(imgtype ImageType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageType(mw.mw, C.ImageType(imgtype))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image units of resolution.
 func (mw *MagickWand) SetImageUnits(units ResolutionType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageUnits(mw.mw, C.ResolutionType(units))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image virtual pixel method.
 func (mw *MagickWand) SetImageVirtualPixelMethod(method VirtualPixelMethod) VirtualPixelMethod {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	return VirtualPixelMethod(C.MagickSetImageVirtualPixelMethod(mw.mw, C.VirtualPixelMethod(method)))
 }
 
 // Sets the image chromaticity white point.
 func (mw *MagickWand) SetImageWhitePoint(x, y float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetImageWhitePoint(mw.mw, C.double(x), C.double(y))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2825,6 +4600,11 @@ func (mw *MagickWand) SetImageWhitePoint(x, y float64) error {
 // azimuth, elevation: define the light source direction.
 //
 func (mw *MagickWand) ShadeImage(gray bool, azimuth, elevation float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickShadeImage(mw.mw, b2i(gray), C.double(azimuth), C.double(elevation))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2840,6 +4620,11 @@ func (mw *MagickWand) ShadeImage(gray bool, azimuth, elevation float64) error {
 // y: the shadow y-offset.
 //
 func (mw *MagickWand) ShadowImage(opacity, sigma float64, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickShadowImage(mw.mw, C.double(opacity), C.double(sigma), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2854,6 +4639,11 @@ func (mw *MagickWand) ShadowImage(opacity, sigma float64, x, y int) error {
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) SharpenImage(radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSharpenImage(mw.mw, C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2868,6 +4658,11 @@ func (mw *MagickWand) SharpenImage(radius, sigma float64) error {
 // sigma: the standard deviation of the Gaussian, in pixels.
 //
 func (mw *MagickWand) SharpenImageChannel(channel ChannelType, radius, sigma float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSharpenImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2875,6 +4670,11 @@ func (mw *MagickWand) SharpenImageChannel(channel ChannelType, radius, sigma flo
 // Shaves pixels from the image edges. It allocates the memory necessary for
 // the new Image structure and returns a pointer to the new image.
 func (mw *MagickWand) ShaveImage(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickShaveImage(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2887,6 +4687,11 @@ func (mw *MagickWand) ShaveImage(cols, rows uint) error {
 // the X axis. Empty triangles left over from shearing the image are filled
 // with the background color.
 func (mw *MagickWand) ShearImage(background *PixelWand, xShear, yShear float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickShearImage(mw.mw, background.pw, C.double(xShear), C.double(yShear))
 	runtime.KeepAlive(background)
 	return mw.getLastErrorIfFailed(ok)
@@ -2908,6 +4713,11 @@ func (mw *MagickWand) ShearImage(background *PixelWand, xShear, yShear float64)
 // beta: midpoint of the function as a color value 0 to QuantumRange.
 //
 func (mw *MagickWand) SigmoidalContrastImage(sharpen bool, alpha, beta float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSigmoidalContrastImage(mw.mw, b2i(sharpen), C.double(alpha), C.double(beta))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2928,6 +4738,11 @@ func (mw *MagickWand) SigmoidalContrastImage(sharpen bool, alpha, beta float64)
 // beta: midpoint of the function as a color value 0 to QuantumRange.
 //
 func (mw *MagickWand) SigmoidalContrastImageChannel(channel ChannelType, sharpen bool, alpha, beta float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSigmoidalContrastImageChannel(mw.mw, C.ChannelType(channel), b2i(sharpen), C.double(alpha), C.double(beta))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2943,11 +4758,22 @@ func (mw *MagickWand) SigmoidalContrastImageChannel(channel ChannelType, sharpen
 //
 // similarity: the computed similarity between the images.
 //
-func (mw *MagickWand) SimilarityImage(reference *MagickWand) (offset *RectangleInfo, similarity float64, area *MagickWand) {
+func (mw *MagickWand) SimilarityImage(reference *MagickWand) (offset *RectangleInfo, similarity float64, area *MagickWand, err error) {
+	if err := mw.valid(); err != nil {
+		return nil, 0, nil, err
+	}
+	if err := reference.valid(); err != nil {
+		return nil, 0, nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var rectInfo C.RectangleInfo
 	mwarea := C.MagickSimilarityImage(mw.mw, reference.mw, &rectInfo, (*C.double)(&similarity))
 	runtime.KeepAlive(reference)
-	return &RectangleInfo{&rectInfo}, similarity, newMagickWand(mwarea)
+	if area, err = mw.resultWand(mwarea); err != nil {
+		return nil, 0, nil, err
+	}
+	return &RectangleInfo{&rectInfo}, similarity, area, nil
 }
 
 // Simulates a pencil sketch. We convolve the image with a Gaussian operator
@@ -2963,6 +4789,11 @@ func (mw *MagickWand) SimilarityImage(reference *MagickWand) (offset *RectangleI
 // angle: Apply the effect along this angle.
 //
 func (mw *MagickWand) SketchImage(radius, sigma, angle float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSketchImage(mw.mw, C.double(radius), C.double(sigma), C.double(angle))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -2976,10 +4807,15 @@ func (mw *MagickWand) SketchImage(radius, sigma, angle float64) error {
 //
 // offset: minimum distance in pixels between images.
 //
-func (mw *MagickWand) SmushImages(stack bool, offset int) *MagickWand {
-	ret := newMagickWand(C.MagickSmushImages(mw.mw, b2i(stack), C.ssize_t(offset)))
+func (mw *MagickWand) SmushImages(stack bool, offset int) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickSmushImages(mw.mw, b2i(stack), C.ssize_t(offset)))
 	runtime.KeepAlive(mw)
-	return ret
+	return ret, err
 }
 
 // Applies a special effect to the image, similar to the effect achieved in a
@@ -2990,6 +4826,11 @@ func (mw *MagickWand) SmushImages(stack bool, offset int) *MagickWand {
 // threshold: define the extent of the solarization.
 //
 func (mw *MagickWand) SolarizeImage(threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSolarizeImage(mw.mw, C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3025,22 +4866,50 @@ func (mw *MagickWand) SolarizeImage(threshold float64) error {
 // arguments: the arguments for this sparseion method.
 //
 func (mw *MagickWand) SparseColorImage(channel ChannelType, method SparseColorMethod, arguments []float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSparseColorImage(mw.mw, C.ChannelType(channel), C.SparseColorMethod(method), C.size_t(len(arguments)), (*C.double)(&arguments[0]))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Splices a solid color into the image.
 func (mw *MagickWand) SpliceImage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSpliceImage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Adds a band in the background color of the image to the side given by
+// gravity, e.g. a footer with GRAVITY_SOUTH. North and south bands take a
+// height and a width of 0, east and west bands the reverse. Other gravities
+// insert width columns and height rows at the respective corner, or through
+// the center.
+func (mw *MagickWand) SpliceImageGravity(width, height uint, gravity GravityType) error {
+	if err := checkBandGravity(width, height, gravity); err != nil {
+		return fmt.Errorf("SpliceImageGravity: %s", err)
+	}
+	x, y := gravityOffset(mw.GetImageWidth(), mw.GetImageHeight(), 0, 0, gravity)
+	return mw.SpliceImage(width, height, x, y)
+}
+
 // Is a special effects method that randomly displaces each pixel in a block
 // defined by the radius parameter.
 //
 // radius: Choose a random pixel in a neighborhood of this extent.
 //
 func (mw *MagickWand) SpreadImage(radius float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSpreadImage(mw.mw, C.double(radius))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3055,6 +4924,11 @@ func (mw *MagickWand) SpreadImage(radius float64) error {
 // height: the height of the pixel neighborhood.
 //
 func (mw *MagickWand) StatisticImage(stype StatisticType, width, height uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickStatisticImage(mw.mw, C.StatisticType(stype), C.size_t(width), C.size_t(height))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3069,6 +4943,11 @@ func (mw *MagickWand) StatisticImage(stype StatisticType, width, height uint) er
 // height: the height of the pixel neighborhood.
 //
 func (mw *MagickWand) StatisticImageChannel(channel ChannelType, stype StatisticType, width, height uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickStatisticImageChannel(mw.mw, C.ChannelType(channel), C.StatisticType(stype), C.size_t(width), C.size_t(height))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3079,24 +4958,45 @@ func (mw *MagickWand) StatisticImageChannel(channel ChannelType, stype Statistic
 //
 // offset: start hiding at this offset into the image.
 //
-func (mw *MagickWand) SteganoImage(watermark *MagickWand, offset int) *MagickWand {
-	ret := newMagickWand(C.MagickSteganoImage(mw.mw, watermark.mw, C.ssize_t(offset)))
+func (mw *MagickWand) SteganoImage(watermark *MagickWand, offset int) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if err := watermark.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickSteganoImage(mw.mw, watermark.mw, C.ssize_t(offset)))
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(watermark)
-	return ret
+	return ret, err
 }
 
 // Composites two images and produces a single image that is the composite of
 // a left and right image of a stereo pair.
-func (mw *MagickWand) StereoImage(offset *MagickWand) *MagickWand {
-	ret := newMagickWand(C.MagickStereoImage(mw.mw, offset.mw))
+func (mw *MagickWand) StereoImage(offset *MagickWand) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if err := offset.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickStereoImage(mw.mw, offset.mw))
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(offset)
-	return ret
+	return ret, err
 }
 
 // Strips an image of all profiles and comments.
 func (mw *MagickWand) StripImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickStripImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3108,16 +5008,29 @@ func (mw *MagickWand) StripImage() error {
 // degrees: define the tightness of the swirling effect.
 //
 func (mw *MagickWand) SwirlImage(degrees float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSwirlImage(mw.mw, C.double(degrees))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Repeatedly tiles the texture image across and down the image canvas.
-func (mw *MagickWand) TextureImage(texture *MagickWand) *MagickWand {
-	ret := newMagickWand(C.MagickTextureImage(mw.mw, texture.mw))
+func (mw *MagickWand) TextureImage(texture *MagickWand) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	if err := texture.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ret, err := mw.resultWand(C.MagickTextureImage(mw.mw, texture.mw))
 	runtime.KeepAlive(mw)
 	runtime.KeepAlive(texture)
-	return ret
+	return ret, err
 }
 
 // Changes the value of individual pixels based on the intensity of each pixel
@@ -3126,6 +5039,11 @@ func (mw *MagickWand) TextureImage(texture *MagickWand) *MagickWand {
 // threshold: define the threshold value.
 //
 func (mw *MagickWand) ThresholdImage(threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickThresholdImage(mw.mw, C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3136,6 +5054,11 @@ func (mw *MagickWand) ThresholdImage(threshold float64) error {
 // threshold: define the threshold value.
 //
 func (mw *MagickWand) ThresholdImageChannel(channel ChannelType, threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickThresholdImageChannel(mw.mw, C.ChannelType(channel), C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3144,6 +5067,11 @@ func (mw *MagickWand) ThresholdImageChannel(channel ChannelType, threshold float
 // associated profiles. The goal is to produce small low cost thumbnail images
 // suited for display on the Web.
 func (mw *MagickWand) ThumbnailImage(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickThumbnailImage(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3157,6 +5085,11 @@ func (mw *MagickWand) ThumbnailImage(cols, rows uint) error {
 // opacity: the opacity pixel wand.
 //
 func (mw *MagickWand) TintImage(tint, opacity *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTintImage(mw.mw, tint.pw, opacity.pw)
 	runtime.KeepAlive(tint)
 	runtime.KeepAlive(opacity)
@@ -3170,17 +5103,27 @@ func (mw *MagickWand) TintImage(tint, opacity *PixelWand) error {
 // image to crop.
 // geometry: an image geometry string. This geometry defines the final size
 // of the image.
-func (mw *MagickWand) TransformImage(crop string, geometry string) *MagickWand {
+func (mw *MagickWand) TransformImage(crop string, geometry string) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cscrop, csgeo := C.CString(crop), C.CString(geometry)
 	defer C.free(unsafe.Pointer(cscrop))
 	defer C.free(unsafe.Pointer(csgeo))
 
-	return newMagickWand(C.MagickTransformImage(mw.mw, cscrop, csgeo))
+	return mw.resultWand(C.MagickTransformImage(mw.mw, cscrop, csgeo))
 }
 
 // Transform the image colorspace, setting the images colorspace while
 // transforming the images data to that colorspace.
 func (mw *MagickWand) TransformImageColorspace(colorspace ColorspaceType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTransformImageColorspace(mw.mw, C.ColorspaceType(colorspace))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3200,6 +5143,11 @@ func (mw *MagickWand) TransformImageColorspace(colorspace ColorspaceType) error
 // invert: paint any pixel that does not match the target color.
 //
 func (mw *MagickWand) TransparentPaintImage(target *PixelWand, alpha, fuzz float64, invert bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTransparentPaintImage(mw.mw, target.pw, C.double(alpha), C.double(fuzz), b2i(invert))
 	runtime.KeepAlive(target)
 	return mw.getLastErrorIfFailed(ok)
@@ -3208,6 +5156,11 @@ func (mw *MagickWand) TransparentPaintImage(target *PixelWand, alpha, fuzz float
 // Creates a vertical mirror image by reflecting the pixels around the central
 // x-axis while rotating them 90-degrees.
 func (mw *MagickWand) TransposeImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTransposeImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3215,6 +5168,11 @@ func (mw *MagickWand) TransposeImage() error {
 // Creates a horizontal mirror image by reflecting the pixels around the
 // central y-axis while rotating them 270-degrees.
 func (mw *MagickWand) TransverseImage() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTransverseImage(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3228,12 +5186,79 @@ func (mw *MagickWand) TransverseImage() error {
 // intensities of 100 and 102 respectively are now interpreted as the same
 // color for the purposes of the floodfill.
 func (mw *MagickWand) TrimImage(fuzz float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickTrimImage(mw.mw, C.double(fuzz))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Returns the rectangle TrimImage would keep, relative to the top left corner
+// of the current image. The image is only trimmed if apply is true. The
+// rectangle is empty if the image consists of the background color only.
+func (mw *MagickWand) TrimImageBox(fuzz float64, apply bool) (*RectangleInfo, error) {
+	trimmed, err := mw.currentImage()
+	if err != nil {
+		return nil, err
+	}
+	defer trimmed.Destroy()
+
+	_, _, pageX, pageY, err := mw.GetImagePage()
+	if err != nil {
+		return nil, err
+	}
+	if err := trimmed.TrimImage(fuzz); err != nil {
+		return nil, err
+	}
+	_, _, x, y, err := trimmed.GetImagePage()
+	if err != nil {
+		return nil, err
+	}
+
+	var rectInfo C.RectangleInfo
+	// Trimming everything leaves a 1x1 image at a negative offset
+	if x >= 0 && y >= 0 {
+		rectInfo.width = C.size_t(trimmed.GetImageWidth())
+		rectInfo.height = C.size_t(trimmed.GetImageHeight())
+		rectInfo.x = C.ssize_t(x - pageX)
+		rectInfo.y = C.ssize_t(y - pageY)
+	}
+	if apply {
+		if err := mw.TrimImage(fuzz); err != nil {
+			return nil, err
+		}
+	}
+	return &RectangleInfo{&rectInfo}, nil
+}
+
+// ErrEmptyBoundingBox is returned by GetImageBoundingBox for an image which
+// consists of the background color only.
+var ErrEmptyBoundingBox = errors.New("image has no content besides the background")
+
+// Returns the bounding box of the content of the current image, the
+// rectangle TrimImage would keep, in image coordinates. The image is not
+// modified. Returns a zero size rectangle and ErrEmptyBoundingBox if the
+// image consists of the background color only.
+func (mw *MagickWand) GetImageBoundingBox(fuzz float64) (RectangleInfo, error) {
+	box, err := mw.TrimImageBox(fuzz, false)
+	if err != nil {
+		return newRectangleInfo(0, 0, 0, 0), err
+	}
+	if box.GetWidth() == 0 || box.GetHeight() == 0 {
+		return *box, ErrEmptyBoundingBox
+	}
+	return *box, nil
+}
+
 // Discards all but one of any pixel color.
 func (mw *MagickWand) UniqueImageColors() error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickUniqueImageColors(mw.mw)
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3253,6 +5278,11 @@ func (mw *MagickWand) UniqueImageColors() error {
 // threshold: the threshold in pixels needed to apply the diffence amount.
 //
 func (mw *MagickWand) UnsharpMaskImage(radius, sigma, amount, threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickUnsharpMaskImage(mw.mw, C.double(radius), C.double(sigma), C.double(amount), C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3272,6 +5302,11 @@ func (mw *MagickWand) UnsharpMaskImage(radius, sigma, amount, threshold float64)
 // threshold: the threshold in pixels needed to apply the diffence amount.
 //
 func (mw *MagickWand) UnsharpMaskImageChannel(channel ChannelType, radius, sigma, amount, threshold float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickUnsharpMaskImageChannel(mw.mw, C.ChannelType(channel), C.double(radius), C.double(sigma), C.double(amount), C.double(threshold))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3281,6 +5316,11 @@ func (mw *MagickWand) UnsharpMaskImageChannel(channel ChannelType, radius, sigma
 // x, y: define the x and y ellipse offset.
 //
 func (mw *MagickWand) VignetteImage(blackPoint, whitePoint float64, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickVignetteImage(mw.mw, C.double(blackPoint), C.double(whitePoint), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3292,6 +5332,11 @@ func (mw *MagickWand) VignetteImage(blackPoint, whitePoint float64, x, y int) er
 // amplitude, wavelength: Define the amplitude and wave length of the sine wave.
 //
 func (mw *MagickWand) WaveImage(amplitude, wavelength float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickWaveImage(mw.mw, C.double(amplitude), C.double(wavelength))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -3299,13 +5344,25 @@ func (mw *MagickWand) WaveImage(amplitude, wavelength float64) error {
 // Is like ThresholdImage() but force all pixels above the threshold into white
 // while leaving all pixels below the threshold unchanged.
 func (mw *MagickWand) WhiteThresholdImage(threshold *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickWhiteThresholdImage(mw.mw, threshold.pw)
 	runtime.KeepAlive(threshold)
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Writes an image to the specified filename.
+// Writes an image to the specified filename. As with ReadImage, the filename
+// is passed to ImageMagick as is, use WriteImageSafe for filenames from
+// untrusted sources.
 func (mw *MagickWand) WriteImage(filename string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
 	ok := C.MagickWriteImage(mw.mw, csfilename)
@@ -3314,6 +5371,11 @@ func (mw *MagickWand) WriteImage(filename string) error {
 
 // Writes an image to an open file descriptor.
 func (mw *MagickWand) WriteImageFile(out *os.File) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	file, err := cfdopen(out, "w")
 	if err != nil {
 		return err
@@ -3325,14 +5387,59 @@ func (mw *MagickWand) WriteImageFile(out *os.File) error {
 
 // Writes an image or image sequence.
 func (mw *MagickWand) WriteImages(filename string, adjoin bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
 	ok := C.MagickWriteImages(mw.mw, csfilename, b2i(adjoin))
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Writes each image of the wand to its own file, named by formatting
+// pattern with the image index as by fmt.Sprintf, e.g. "frame_%03d.png"
+// gives "frame_000.png", "frame_001.png" and so on. The format follows from
+// each file name. Returns the paths written. If a frame cannot be written
+// the error names its index, and the paths written before it are returned
+// too. The iterator is reset afterwards.
+func (mw *MagickWand) WriteFrames(pattern string) ([]string, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	n := int(mw.GetNumberImages())
+	if n == 0 {
+		return nil, errors.New("WriteFrames: wand has no images")
+	}
+	first, second := fmt.Sprintf(pattern, 0), fmt.Sprintf(pattern, 1)
+	if strings.Contains(first, "%!") || first == second {
+		return nil, fmt.Errorf("WriteFrames: pattern %q must format the frame index with one verb such as %%d", pattern)
+	}
+
+	defer mw.ResetIterator()
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		path := fmt.Sprintf(pattern, i)
+		mw.SetIteratorIndex(i)
+		if err := mw.SetImageFilename(path); err != nil {
+			return paths, fmt.Errorf("WriteFrames: frame %d: %w", i, err)
+		}
+		if err := mw.WriteImage(path); err != nil {
+			return paths, fmt.Errorf("WriteFrames: frame %d: %w", i, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
 // Writes an image sequence to an open file descriptor.
 func (mw *MagickWand) WriteImagesFile(out *os.File) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	file, err := cfdopen(out, "w")
 	if err != nil {
 		return err
@@ -3342,6 +5449,29 @@ func (mw *MagickWand) WriteImagesFile(out *os.File) error {
 	return mw.getLastErrorIfFailed(ok)
 }
 
+// Writes the current image to an io.Writer, encoded in the format set with
+// SetImageFormat().
+func (mw *MagickWand) WriteImageWriter(w io.Writer) error {
+	return mw.writeBlob(w, mw.GetImageBlob())
+}
+
+// Writes the image sequence to an io.Writer, encoded in the format set with
+// SetImageFormat().
+func (mw *MagickWand) WriteImagesWriter(w io.Writer) error {
+	return mw.writeBlob(w, mw.GetImagesBlob())
+}
+
+func (mw *MagickWand) writeBlob(w io.Writer, blob []byte) error {
+	if len(blob) == 0 {
+		if err := mw.GetLastError(); err != nil {
+			return err
+		}
+		return errors.New("no image data to write, is an image format set?")
+	}
+	_, err := w.Write(blob)
+	return err
+}
+
 // cfdopen returns a C-level FILE*. mode should be as described in fdopen(3).
 // Caller is responsible for closing the file when successfully returned,
 // via C.fclose()
@@ -0,0 +1,80 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+)
+
+// Makes the corners of the current image transparent, rounded with the
+// given radii in pixels. Existing transparency of the image is kept.
+func (mw *MagickWand) RoundCorners(radiusX, radiusY float64) error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("RoundCorners: wand has no image")
+	}
+	return mw.applyShapeMask(width, height, func(dw *DrawingWand) {
+		dw.RoundRectangle(0, 0, float64(width-1), float64(height-1), radiusX, radiusY)
+	})
+}
+
+// Crops the current image to the largest centered circle, the result is a
+// square with transparent corners. Existing transparency of the image is
+// kept.
+func (mw *MagickWand) CircleCrop() error {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("CircleCrop: wand has no image")
+	}
+	size := width
+	if height < size {
+		size = height
+	}
+	if width != height {
+		if err := mw.ResetImagePage(""); err != nil {
+			return err
+		}
+		if err := mw.CropImage(size, size, int(width-size)/2, int(height-size)/2); err != nil {
+			return err
+		}
+		if err := mw.ResetImagePage(""); err != nil {
+			return err
+		}
+	}
+	center := float64(size) / 2
+	return mw.applyShapeMask(size, size, func(dw *DrawingWand) {
+		dw.Circle(center, center, center, 0)
+	})
+}
+
+// Keeps the pixels of the current image inside the shape drawn by draw and
+// makes the rest transparent.
+func (mw *MagickWand) applyShapeMask(width, height uint, draw func(dw *DrawingWand)) error {
+	transparent := NewPixelWand()
+	defer transparent.Destroy()
+	transparent.SetColor("none")
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+
+	mask := NewMagickWand()
+	defer mask.Destroy()
+	if err := mask.NewImage(width, height, transparent); err != nil {
+		return err
+	}
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	dw.SetFillColor(white)
+	draw(dw)
+	if err := mask.DrawImage(dw); err != nil {
+		return err
+	}
+
+	// Without an alpha channel DstIn has nowhere to store the mask
+	if err := mw.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		return err
+	}
+	return mw.CompositeImage(mask, COMPOSITE_OP_DST_IN, 0, 0)
+}
@@ -0,0 +1,100 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+// Returns the alpha of a pixel after a PNG round trip
+func pngAlphaAt(t *testing.T, mw *MagickWand, x, y int) float64 {
+	png := encodeDecode(t, mw, "PNG")
+	defer png.Destroy()
+	pw, err := png.GetImagePixelColor(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Destroy()
+	return pw.GetAlpha()
+}
+
+func TestRoundCorners(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[120x40]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.RoundCorners(10, 10); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 120 || h != 40 {
+		t.Fatalf("Expected the size to be kept, got %dx%d", w, h)
+	}
+
+	points := []struct {
+		x, y  int
+		alpha float64
+	}{
+		{0, 0, 0},
+		{119, 39, 0},
+		{60, 20, 1},
+		// The radius is not stretched along the long side
+		{15, 1, 1},
+		{1, 15, 1},
+	}
+	for _, p := range points {
+		if alpha := pngAlphaAt(t, mw, p.x, p.y); alpha != p.alpha {
+			t.Errorf("Pixel (%d,%d): expected alpha %f, got %f", p.x, p.y, p.alpha, alpha)
+		}
+	}
+}
+
+func TestCircleCrop(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:blue[60x40]"); err != nil {
+		t.Fatal(err)
+	}
+	// Punch a hole which must survive the crop
+	hole := NewMagickWand()
+	defer hole.Destroy()
+	if err := hole.ReadImage("xc:none[4x4]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageAlphaChannel(ALPHA_CHANNEL_SET); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.CompositeImage(hole, COMPOSITE_OP_COPY, 28, 18); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.CircleCrop(); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 40 || h != 40 {
+		t.Fatalf("Expected a 40x40 square, got %dx%d", w, h)
+	}
+	if alpha := pngAlphaAt(t, mw, 0, 0); alpha != 0 {
+		t.Errorf("Expected a transparent corner, got alpha %f", alpha)
+	}
+	if alpha := pngAlphaAt(t, mw, 12, 20); alpha != 1 {
+		t.Errorf("Expected an opaque center, got alpha %f", alpha)
+	}
+	if alpha := pngAlphaAt(t, mw, 19, 19); alpha != 0 {
+		t.Errorf("Expected the hole to stay transparent, got alpha %f", alpha)
+	}
+}
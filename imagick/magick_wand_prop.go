@@ -9,14 +9,23 @@ package imagick
 */
 import "C"
 import (
-	//"fmt"
 	"errors"
+	"fmt"
 	"runtime"
 	"unsafe"
 )
 
+// ErrNotFound is returned, possibly wrapped, when a named image property,
+// artifact, option or profile does not exist. Use errors.Is to test for it.
+var ErrNotFound = errors.New("not found")
+
 // This method deletes a wand artifact
 func (mw *MagickWand) DeleteImageArtifact(artifact string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csartifact := C.CString(artifact)
 	defer C.free(unsafe.Pointer(csartifact))
 	ok := C.MagickDeleteImageArtifact(mw.mw, csartifact)
@@ -25,6 +34,11 @@ func (mw *MagickWand) DeleteImageArtifact(artifact string) error {
 
 // This method deletes a image property
 func (mw *MagickWand) DeleteImageProperty(property string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csproperty := C.CString(property)
 	defer C.free(unsafe.Pointer(csproperty))
 	ok := C.MagickDeleteImageProperty(mw.mw, csproperty)
@@ -33,6 +47,11 @@ func (mw *MagickWand) DeleteImageProperty(property string) error {
 
 // This method deletes a wand option
 func (mw *MagickWand) DeleteOption(option string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csoption := C.CString(option)
 	defer C.free(unsafe.Pointer(csoption))
 	ok := C.MagickDeleteOption(mw.mw, csoption)
@@ -41,20 +60,38 @@ func (mw *MagickWand) DeleteOption(option string) error {
 
 // Returns the antialias property associated with the wand
 func (mw *MagickWand) GetAntialias() bool {
+	if mw.valid() != nil {
+		return false
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := 1 == C.int(C.MagickGetAntialias(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
 // Returns the wand background color
-func (mw *MagickWand) GetBackgroundColor() *PixelWand {
-	ret := newPixelWand(C.MagickGetBackgroundColor(mw.mw))
+func (mw *MagickWand) GetBackgroundColor() (*PixelWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cpw := C.MagickGetBackgroundColor(mw.mw)
 	runtime.KeepAlive(mw)
-	return ret
+	if cpw == nil {
+		return nil, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	return newPixelWand(cpw), nil
 }
 
 // Returns the wand colorspace type
 func (mw *MagickWand) GetColorspace() ColorspaceType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ccst := C.MagickGetColorspace(mw.mw)
 	runtime.KeepAlive(mw)
 	return ColorspaceType(ccst)
@@ -62,6 +99,11 @@ func (mw *MagickWand) GetColorspace() ColorspaceType {
 
 // Gets the wand compression type.
 func (mw *MagickWand) GetCompression() CompressionType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := CompressionType(C.MagickGetCompression(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -69,6 +111,11 @@ func (mw *MagickWand) GetCompression() CompressionType {
 
 // Gets the wand compression quality.
 func (mw *MagickWand) GetCompressionQuality() uint {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := uint(C.MagickGetCompressionQuality(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -76,6 +123,11 @@ func (mw *MagickWand) GetCompressionQuality() uint {
 
 // Returns the filename associated with an image sequence.
 func (mw *MagickWand) GetFilename() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstr := C.MagickGetFilename(mw.mw)
 	runtime.KeepAlive(mw)
 	defer C.free(unsafe.Pointer(cstr))
@@ -84,6 +136,11 @@ func (mw *MagickWand) GetFilename() string {
 
 // Returns the font associated with the MagickWand.
 func (mw *MagickWand) GetFont() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstr := C.MagickGetFont(mw.mw)
 	runtime.KeepAlive(mw)
 	defer relinquishMemory(unsafe.Pointer(cstr))
@@ -92,6 +149,11 @@ func (mw *MagickWand) GetFont() string {
 
 // Returns the format of the magick wand.
 func (mw *MagickWand) GetFormat() string {
+	if mw.valid() != nil {
+		return ""
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cstr := C.MagickGetFormat(mw.mw)
 	runtime.KeepAlive(mw)
 	defer relinquishMemory(unsafe.Pointer(cstr))
@@ -100,25 +162,48 @@ func (mw *MagickWand) GetFormat() string {
 
 // Gets the wand gravity.
 func (mw *MagickWand) GetGravity() GravityType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := GravityType(C.MagickGetGravity(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
-// Returns a value associated with the specified artifact.
-func (mw *MagickWand) GetImageArtifact(artifact string) string {
+// Returns a value associated with the specified artifact, such as
+// "deskew:angle" after DeskewImage(). If the image has no such artifact the
+// returned error wraps ErrNotFound.
+func (mw *MagickWand) GetImageArtifact(artifact string) (string, error) {
+	if err := mw.valid(); err != nil {
+		return "", err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csartifact := C.CString(artifact)
 	defer C.free(unsafe.Pointer(csartifact))
 	cstr := C.MagickGetImageArtifact(mw.mw, csartifact)
 	runtime.KeepAlive(mw)
-	defer C.free(unsafe.Pointer(cstr))
-	return C.GoString(cstr)
+	if cstr == nil {
+		if err := mw.lastError(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("image artifact %q: %w", artifact, ErrNotFound)
+	}
+	defer relinquishMemory(unsafe.Pointer(cstr))
+	return C.GoString(cstr), nil
 }
 
 // Returns all the artifact names that match the specified pattern associated
-// with a wand. Use GetImageProperty() to return the value of a particular
+// with a wand. Use GetImageArtifact() to return the value of a particular
 // artifact.
 func (mw *MagickWand) GetImageArtifacts(pattern string) (artifacts []string) {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cspattern))
 	num := C.size_t(0)
@@ -129,23 +214,37 @@ func (mw *MagickWand) GetImageArtifacts(pattern string) (artifacts []string) {
 	return
 }
 
-// Returns the named image profile.
+// Returns a copy of the named image profile, or nil if the image has no
+// such profile.
 //
-// name: Name of profile to return: ICC, IPTC, or generic profile.
-func (mw *MagickWand) GetImageProfile(name string) string {
+// name: Name of profile to return: ICC, EXIF, IPTC, XMP or generic profile.
+func (mw *MagickWand) GetImageProfile(name string) []byte {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csname := C.CString(name)
 	defer C.free(unsafe.Pointer(csname))
 	szlen := C.size_t(0)
 	csprofile := C.MagickGetImageProfile(mw.mw, csname, &szlen)
 	runtime.KeepAlive(mw)
+	if csprofile == nil {
+		return nil
+	}
 	defer relinquishMemory(unsafe.Pointer(csprofile))
-	return C.GoStringN((*C.char)((unsafe.Pointer)(csprofile)), C.int(szlen))
+	return C.GoBytes(unsafe.Pointer(csprofile), C.int(szlen))
 }
 
 // Returns all the profile names that match the specified pattern associated
 // with a wand. Use GetImageProfile() to return the value of a particular
 // property.
 func (mw *MagickWand) GetImageProfiles(pattern string) (profiles []string) {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cspattern))
 	np := C.size_t(0)
@@ -156,20 +255,38 @@ func (mw *MagickWand) GetImageProfiles(pattern string) (profiles []string) {
 	return
 }
 
-// Returns a value associated with the specified property.
-func (mw *MagickWand) GetImageProperty(property string) string {
+// Returns a value associated with the specified property, such as
+// "exif:DateTimeOriginal" or "png:bit-depth". If the image has no such
+// property the returned error wraps ErrNotFound.
+func (mw *MagickWand) GetImageProperty(property string) (string, error) {
+	if err := mw.valid(); err != nil {
+		return "", err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csproperty := C.CString(property)
 	defer C.free(unsafe.Pointer(csproperty))
 	cspv := C.MagickGetImageProperty(mw.mw, csproperty)
 	runtime.KeepAlive(mw)
+	if cspv == nil {
+		if err := mw.lastError(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("image property %q: %w", property, ErrNotFound)
+	}
 	defer relinquishMemory(unsafe.Pointer(cspv))
-	return C.GoString(cspv)
+	return C.GoString(cspv), nil
 }
 
 // Returns all the property names that match the specified pattern associated
 // with a wand. Use GetImageProperty() to return the value of a particular
 // property.
 func (mw *MagickWand) GetImageProperties(pattern string) (properties []string) {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cspattern))
 	np := C.size_t(0)
@@ -182,6 +299,11 @@ func (mw *MagickWand) GetImageProperties(pattern string) (properties []string) {
 
 // Gets the wand interlace scheme.
 func (mw *MagickWand) GetInterlaceScheme() InterlaceType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := InterlaceType(C.MagickGetInterlaceScheme(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -189,24 +311,43 @@ func (mw *MagickWand) GetInterlaceScheme() InterlaceType {
 
 // Gets the wand compression.
 func (mw *MagickWand) GetInterpolateMethod() InterpolatePixelMethod {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := InterpolatePixelMethod(C.MagickGetInterpolateMethod(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
-// Returns a value associated with a wand and the specified key.
-func (mw *MagickWand) GetOption(key string) string {
+// Returns a value associated with a wand and the specified key. If the
+// option is not set the returned error wraps ErrNotFound.
+func (mw *MagickWand) GetOption(key string) (string, error) {
+	if err := mw.valid(); err != nil {
+		return "", err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cskey := C.CString(key)
 	defer C.free(unsafe.Pointer(cskey))
 	csval := C.MagickGetOption(mw.mw, cskey)
 	runtime.KeepAlive(mw)
+	if csval == nil {
+		return "", fmt.Errorf("option %q: %w", key, ErrNotFound)
+	}
 	defer relinquishMemory(unsafe.Pointer(csval))
-	return C.GoString(csval)
+	return C.GoString(csval), nil
 }
 
 // Returns all the option names that match the specified pattern associated
 // with a wand. Use GetOption() to return the value of a particular option.
 func (mw *MagickWand) GetOptions(pattern string) (options []string) {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cspattern))
 	np := C.size_t(0)
@@ -219,6 +360,11 @@ func (mw *MagickWand) GetOptions(pattern string) (options []string) {
 
 // Gets the wand orientation type.
 func (mw *MagickWand) GetOrientation() OrientationType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := OrientationType(C.MagickGetOrientation(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -226,6 +372,11 @@ func (mw *MagickWand) GetOrientation() OrientationType {
 
 // Returns the page geometry associated with the magick wand.
 func (mw *MagickWand) GetPage() (width, height uint, x, y int, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var cw, ch C.size_t
 	var cx, cy C.ssize_t
 	ok := C.MagickGetPage(mw.mw, &cw, &ch, &cx, &cy)
@@ -236,6 +387,11 @@ func (mw *MagickWand) GetPage() (width, height uint, x, y int, err error) {
 
 // Returns the font pointsize associated with the MagickWand.
 func (mw *MagickWand) GetPointsize() float64 {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := float64(C.MagickGetPointsize(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -243,6 +399,11 @@ func (mw *MagickWand) GetPointsize() float64 {
 
 // Gets the image X and Y resolution.
 func (mw *MagickWand) GetResolution() (x, y float64, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickGetResolution(mw.mw, (*C.double)(&x), (*C.double)(&y))
 	err = mw.getLastErrorIfFailed(ok)
 	return
@@ -250,15 +411,26 @@ func (mw *MagickWand) GetResolution() (x, y float64, err error) {
 
 // Gets the horizontal and vertical sampling factor.
 func (mw *MagickWand) GetSamplingFactors() (factors []float64) {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	num := C.size_t(0)
 	pd := C.MagickGetSamplingFactors(mw.mw, &num)
 	runtime.KeepAlive(mw)
+	defer relinquishMemory(unsafe.Pointer(pd))
 	factors = sizedDoubleArrayToFloat64Slice(pd, num)
 	return
 }
 
 // Returns the size associated with the magick wand.
 func (mw *MagickWand) GetSize() (cols, rows uint, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var cc, cr C.size_t
 	ok := C.MagickGetSize(mw.mw, &cc, &cr)
 	cols, rows, err = uint(cc), uint(cr), mw.getLastErrorIfFailed(ok)
@@ -267,6 +439,11 @@ func (mw *MagickWand) GetSize() (cols, rows uint, err error) {
 
 // Returns the size offset associated with the magick wand.
 func (mw *MagickWand) GetSizeOffset() (offset int, err error) {
+	if err := mw.valid(); err != nil {
+		return 0, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	var co C.ssize_t
 	ok := C.MagickGetSizeOffset(mw.mw, &co)
 	offset, err = int(co), mw.getLastErrorIfFailed(ok)
@@ -275,6 +452,11 @@ func (mw *MagickWand) GetSizeOffset() (offset int, err error) {
 
 // Returns the wand type.
 func (mw *MagickWand) GetType() ImageType {
+	if mw.valid() != nil {
+		return 0
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := ImageType(C.MagickGetType(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
@@ -283,41 +465,72 @@ func (mw *MagickWand) GetType() ImageType {
 // Adds or removes a ICC, IPTC, or generic profile from an image. If the
 // profile is empty, it is removed from the image otherwise added. Use a name
 // of '*' and an empty profile to remove all profiles from the image.
+// Adding an ICC profile to an image which already has one performs a
+// colorimetric conversion from the old profile to the new one.
 //
 // name: Name of profile to add or remove: ICC, IPTC, or generic profile.
 //
 func (mw *MagickWand) ProfileImage(name string, profile []byte) error {
-	if len(profile) == 0 {
-		return errors.New("zero-length profile not permitted")
+	if err := mw.valid(); err != nil {
+		return err
 	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csname := C.CString(name)
 	defer C.free(unsafe.Pointer(csname))
-	ok := C.MagickProfileImage(mw.mw, csname, unsafe.Pointer(&profile[0]), C.size_t(len(profile)))
+	var ptr unsafe.Pointer
+	if len(profile) > 0 {
+		ptr = unsafe.Pointer(&profile[0])
+	}
+	ok := C.MagickProfileImage(mw.mw, csname, ptr, C.size_t(len(profile)))
+	runtime.KeepAlive(profile)
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Removes the named image profile and returns it.
+// Removes the named image profile and returns it, or nil if the image had
+// no such profile. Other profiles are left intact.
 //
 // name: name of profile to return: ICC, IPTC, or generic profile.
 //
 func (mw *MagickWand) RemoveImageProfile(name string) []byte {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csname := C.CString(name)
 	defer C.free(unsafe.Pointer(csname))
 	clen := C.size_t(0)
 	profile := C.MagickRemoveImageProfile(mw.mw, csname, &clen)
 	runtime.KeepAlive(mw)
+	if profile == nil {
+		return nil
+	}
 	defer relinquishMemory(unsafe.Pointer(profile))
 	return C.GoBytes(unsafe.Pointer(profile), C.int(clen))
 }
 
 // Sets the antialias propery of the wand.
 func (mw *MagickWand) SetAntialias(antialias bool) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetAntialias(mw.mw, b2i(antialias))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Sets the wand background color.
+// Sets the wand background color. Unlike SetImageBackgroundColor() it is
+// used when reading images, e.g. as the canvas color of SVG or PDF images,
+// and as default by operations such as RotateImage() and ExtentImage() on
+// images read afterwards.
 func (mw *MagickWand) SetBackgroundColor(background *PixelWand) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetBackgroundColor(mw.mw, background.pw)
 	runtime.KeepAlive(background)
 	return mw.getLastErrorIfFailed(ok)
@@ -325,24 +538,45 @@ func (mw *MagickWand) SetBackgroundColor(background *PixelWand) error {
 
 // Sets the wand colorspace type.
 func (mw *MagickWand) SetColorspace(colorspace ColorspaceType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetColorspace(mw.mw, C.ColorspaceType(colorspace))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the wand compression type.
 func (mw *MagickWand) SetCompression(compression CompressionType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetCompression(mw.mw, C.CompressionType(compression))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the wand compression quality.
 func (mw *MagickWand) SetCompressionQuality(quality uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetCompressionQuality(mw.mw, C.size_t(quality))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Sets the wand pixel depth.
+// Sets the wand pixel depth. Set it before you read a raw image format to
+// give the number of bits per sample.
 func (mw *MagickWand) SetDepth(depth uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetDepth(mw.mw, C.size_t(depth))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -350,6 +584,11 @@ func (mw *MagickWand) SetDepth(depth uint) error {
 // Sets the extract geometry before you read or write an image file. Use it for
 // inline cropping (e.g. 200x200+0+0) or resizing (e.g.200x200).
 func (mw *MagickWand) SetExtract(geometry string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csgeometry := C.CString(geometry)
 	defer C.free(unsafe.Pointer(csgeometry))
 	ok := C.MagickSetExtract(mw.mw, csgeometry)
@@ -358,6 +597,11 @@ func (mw *MagickWand) SetExtract(geometry string) error {
 
 // Sets the filename before you read or write an image file.
 func (mw *MagickWand) SetFilename(filename string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csfilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(csfilename))
 	ok := C.MagickSetFilename(mw.mw, csfilename)
@@ -366,14 +610,30 @@ func (mw *MagickWand) SetFilename(filename string) error {
 
 // Sets the font associated with the MagickWand.
 func (mw *MagickWand) SetFont(font string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csfont := C.CString(font)
 	defer C.free(unsafe.Pointer(csfont))
 	ok := C.MagickSetFont(mw.mw, csfont)
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Sets the format of the magick wand.
+// Sets the format of the magick wand. Set it before you read an image blob
+// which has no magic bytes, such as raw GRAY, RGB or CMYK data:
+//
+//     mw.SetSize(640, 480)
+//     mw.SetFormat("GRAY")
+//     mw.ReadImageBlob(rawBytes)
+//
 func (mw *MagickWand) SetFormat(format string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csformat := C.CString(format)
 	defer C.free(unsafe.Pointer(csformat))
 	ok := C.MagickSetFormat(mw.mw, csformat)
@@ -382,12 +642,24 @@ func (mw *MagickWand) SetFormat(format string) error {
 
 // Sets the gravity type.
 func (mw *MagickWand) SetGravity(gtype GravityType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetGravity(mw.mw, C.GravityType(gtype))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Associates a artifact with an image.
+// Associates a artifact with an image. Artifacts pass per-operation settings
+// to ImageMagick, e.g. "compare:highlight-color", "deskew:auto-crop" or
+// "trim:percent-background".
 func (mw *MagickWand) SetImageArtifact(artifact, value string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csartifact := C.CString(artifact)
 	defer C.free(unsafe.Pointer(csartifact))
 	csvalue := C.CString(value)
@@ -402,6 +674,11 @@ func (mw *MagickWand) SetImageArtifact(artifact, value string) error {
 //
 // name: Name of profile to add or remove: ICC, IPTC, or generic profile.
 func (mw *MagickWand) SetImageProfile(name string, profile []byte) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	if len(profile) == 0 {
 		return errors.New("zero-length profile not permitted")
 	}
@@ -415,6 +692,11 @@ func (mw *MagickWand) SetImageProfile(name string, profile []byte) error {
 // Note: Which properties are persisted(file write, byte slice) depends on the writer for the used file format respectively.
 // Refer to the ImageMagick documention and source for more specific information.
 func (mw *MagickWand) SetImageProperty(property, value string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	csproperty := C.CString(property)
 	defer C.free(unsafe.Pointer(csproperty))
 	csvalue := C.CString(value)
@@ -425,19 +707,36 @@ func (mw *MagickWand) SetImageProperty(property, value string) error {
 
 // Sets the image interlacing scheme
 func (mw *MagickWand) SetInterlaceScheme(scheme InterlaceType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetInterlaceScheme(mw.mw, C.InterlaceType(scheme))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the interpolate pixel method.
 func (mw *MagickWand) SetInterpolateMethod(method InterpolatePixelMethod) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetInterpolateMethod(mw.mw, C.InterpolatePixelMethod(method))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Associates one or options with the wand (.e.g
-// SetOption(wand, "jpeg:perserve", "yes")).
+// SetOption("jpeg:preserve", "yes")). Coder options such as "jpeg:size",
+// "png:compression-level" or "webp:lossless" must be set before
+// ReadImage() or WriteImage() to affect decoding or encoding.
 func (mw *MagickWand) SetOption(key, value string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cskey := C.CString(key)
 	defer C.free(unsafe.Pointer(cskey))
 	csvalue := C.CString(value)
@@ -448,18 +747,33 @@ func (mw *MagickWand) SetOption(key, value string) error {
 
 // Sets the wand orientation type.
 func (mw *MagickWand) SetOrientation(orientation OrientationType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetOrientation(mw.mw, C.OrientationType(orientation))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the page geometry of the magick wand.
 func (mw *MagickWand) SetPage(width, height uint, x, y int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetPage(mw.mw, C.size_t(width), C.size_t(height), C.ssize_t(x), C.ssize_t(y))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the passphrase.
 func (mw *MagickWand) SetPassphrase(passphrase string) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	cspassphrase := C.CString(passphrase)
 	defer C.free(unsafe.Pointer(cspassphrase))
 	ok := C.MagickSetPassphrase(mw.mw, cspassphrase)
@@ -468,18 +782,36 @@ func (mw *MagickWand) SetPassphrase(passphrase string) error {
 
 // Sets the font pointsize associated with the MagickWand.
 func (mw *MagickWand) SetPointsize(pointSize float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetPointsize(mw.mw, C.double(pointSize))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Sets the limit for a particular resource in megabytes.
-func (mw *MagickWand) SetResourceLimit(rtype ResourceType, limit int64) error {
+// Sets the limit for a particular resource, see GetResourceLimit() for the
+// units of each resource. Resource limits apply to the whole process, see
+// the package level SetResourceLimit().
+func (mw *MagickWand) SetResourceLimit(rtype ResourceType, limit uint64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetResourceLimit(C.ResourceType(rtype), C.MagickSizeType(limit))
 	return mw.getLastErrorIfFailed(ok)
 }
 
-// Sets the image resolution.
+// Sets the image resolution. Set it before reading a vector format such as
+// SVG, PDF or EPS to rasterize it at that density, e.g. 300x300 DPI.
 func (mw *MagickWand) SetResolution(xRes, yRes float64) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetResolution(mw.mw, C.double(xRes), C.double(yRes))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -487,15 +819,30 @@ func (mw *MagickWand) SetResolution(xRes, yRes float64) error {
 // Sets the image sampling factors.
 //
 // samplingFactors: An array of floats representing the sampling factor for
-// each color component (in RGB order).
+// each color component (in RGB order). An empty slice clears the factors.
 func (mw *MagickWand) SetSamplingFactors(samplingFactors []float64) error {
-	ok := C.MagickSetSamplingFactors(mw.mw, C.size_t(len(samplingFactors)), (*C.double)(&samplingFactors[0]))
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	var cfactors *C.double
+	if len(samplingFactors) > 0 {
+		cfactors = (*C.double)(&samplingFactors[0])
+	}
+	ok := C.MagickSetSamplingFactors(mw.mw, C.size_t(len(samplingFactors)), cfactors)
+	runtime.KeepAlive(samplingFactors)
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the size of the magick wand. Set it before you read a raw image format
 // such as RGB, GRAY, or CMYK.
 func (mw *MagickWand) SetSize(cols, rows uint) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetSize(mw.mw, C.size_t(cols), C.size_t(rows))
 	return mw.getLastErrorIfFailed(ok)
 }
@@ -503,12 +850,22 @@ func (mw *MagickWand) SetSize(cols, rows uint) error {
 // Sets the size and offset of the magick wand. Set it before you read a raw
 // image format such as RGB, GRAY, or CMYK.
 func (mw *MagickWand) SetSizeOffset(cols, rows uint, offset int) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetSizeOffset(mw.mw, C.size_t(cols), C.size_t(rows), C.ssize_t(offset))
 	return mw.getLastErrorIfFailed(ok)
 }
 
 // Sets the image type attribute.
 func (mw *MagickWand) SetType(itype ImageType) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ok := C.MagickSetType(mw.mw, C.ImageType(itype))
 	return mw.getLastErrorIfFailed(ok)
 }
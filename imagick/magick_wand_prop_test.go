@@ -0,0 +1,454 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// Returns a minimal little-endian EXIF profile holding only an Orientation tag
+func exifProfile(orientation OrientationType) []byte {
+	return []byte{
+		'E', 'x', 'i', 'f', 0, 0,
+		'I', 'I', 0x2a, 0, 8, 0, 0, 0, // TIFF header, IFD0 at offset 8
+		1, 0, // one entry
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0, // Orientation, SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+}
+
+// Returns a JPEG blob of the logo: image carrying an EXIF orientation
+func jpegWithExif(t *testing.T, orientation OrientationType) []byte {
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageProfile("exif", exifProfile(orientation)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+	return mw.GetImageBlob()
+}
+
+func TestImageProperties(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+
+	props := map[string]string{
+		"comment":   "a comment",
+		"my:custom": "custom value",
+	}
+	for k, v := range props {
+		if err := mw.SetImageProperty(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := map[string]bool{}
+	for _, name := range mw.GetImageProperties("*") {
+		names[name] = true
+	}
+	for k, v := range props {
+		if !names[k] {
+			t.Errorf("Property %q not listed by GetImageProperties", k)
+		}
+		got, err := mw.GetImageProperty(k)
+		if err != nil {
+			t.Errorf("GetImageProperty(%q): %s", k, err)
+		} else if got != v {
+			t.Errorf("GetImageProperty(%q): expected %q, got %q", k, v, got)
+		}
+	}
+
+	if err := mw.DeleteImageProperty("my:custom"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mw.GetImageProperty("my:custom"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a deleted property, got %v", err)
+	}
+
+	jpeg := NewMagickWand()
+	defer jpeg.Destroy()
+
+	if err := jpeg.ReadImageBlob(jpegWithExif(t, ORIENTATION_RIGHT_TOP)); err != nil {
+		t.Fatal(err)
+	}
+	if len(jpeg.GetImageProperties("exif:*")) == 0 {
+		t.Fatal("Expected exif:* properties after reading a JPEG with EXIF data")
+	}
+	if v, err := jpeg.GetImageProperty("exif:Orientation"); err != nil || v != "6" {
+		t.Fatalf("Expected exif:Orientation 6, got %q (%v)", v, err)
+	}
+}
+
+// Builds a minimal ICC v2 display profile. Color space is "RGB " for a
+// matrix/TRC sRGB-like profile, or "GRAY" for a gray TRC profile.
+func iccProfile(colorSpace string) []byte {
+	be32 := func(v uint32) []byte {
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, v)
+		return b
+	}
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = append(b, be32(uint32(int32(math.Round(v*65536))))...)
+		}
+		return b
+	}
+	curve := []byte("curv\x00\x00\x00\x00\x00\x00\x00\x01\x02\x33")
+	desc := append([]byte("desc\x00\x00\x00\x00\x00\x00\x00\x05test\x00"), make([]byte, 4+4+2+1+67)...)
+
+	type tag struct {
+		sig  string
+		data []byte
+	}
+	tags := []tag{{"desc", desc}, {"wtpt", xyz(0.9642, 1.0, 0.8249)}}
+	if colorSpace == "GRAY" {
+		tags = append(tags, tag{"kTRC", curve})
+	} else {
+		tags = append(tags,
+			tag{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+			tag{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+			tag{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+			tag{"rTRC", curve}, tag{"gTRC", curve}, tag{"bTRC", curve})
+	}
+
+	var table, data []byte
+	offset := 128 + 4 + 12*len(tags)
+	for _, t := range tags {
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+		table = append(table, t.sig...)
+		table = append(table, be32(uint32(offset+len(data)))...)
+		table = append(table, be32(uint32(len(t.data)))...)
+		data = append(data, t.data...)
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(offset+len(data)))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntr")
+	copy(header[16:], colorSpace)
+	copy(header[20:], "XYZ ")
+	copy(header[36:], "acsp")
+	copy(header[68:], xyz(0.9642, 1.0, 0.8249)[8:])
+
+	profile := append(header, be32(uint32(len(tags)))...)
+	profile = append(profile, table...)
+	return append(profile, data...)
+}
+
+func TestImageProfiles(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+
+	srgb := iccProfile("RGB ")
+	xmp := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"></x:xmpmeta>`)
+
+	if err := mw.SetImageProfile("icc", srgb); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageProfile("xmp", xmp); err != nil {
+		t.Fatal(err)
+	}
+	if got := mw.GetImageProfile("icc"); !bytes.Equal(got, srgb) {
+		t.Fatal("ICC profile read back does not match the one set")
+	}
+	if got := mw.GetImageProfile("nosuchprofile"); got != nil {
+		t.Fatalf("Expected nil for a missing profile, got %d bytes", len(got))
+	}
+
+	gray := iccProfile("GRAY")
+	if err := mw.ProfileImage("icc", gray); err != nil {
+		t.Fatal(err)
+	}
+	if cs := mw.GetImageColorspace(); cs != COLORSPACE_GRAY {
+		t.Fatalf("Expected ProfileImage to convert to GRAY, colorspace is %d", cs)
+	}
+
+	if removed := mw.RemoveImageProfile("icc"); !bytes.Equal(removed, gray) {
+		t.Fatal("RemoveImageProfile did not return the removed ICC profile")
+	}
+	if mw.GetImageProfile("icc") != nil {
+		t.Fatal("ICC profile still present after RemoveImageProfile")
+	}
+	if got := mw.GetImageProfile("xmp"); !bytes.Equal(got, xmp) {
+		t.Fatal("RemoveImageProfile(\"icc\") did not leave the XMP profile intact")
+	}
+	if names := mw.GetImageProfiles("*"); len(names) != 1 || names[0] != "xmp" {
+		t.Fatalf("Expected only the xmp profile to remain, got %v", names)
+	}
+}
+
+func TestDeskewAngleArtifact(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	white := NewPixelWand()
+	defer white.Destroy()
+	black := NewPixelWand()
+	defer black.Destroy()
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+
+	white.SetColor("white")
+	black.SetColor("black")
+	if err := mw.NewImage(400, 300, white); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines of "text" on a scanned page
+	dw.SetStrokeColor(black)
+	dw.SetStrokeWidth(4)
+	for y := 40.0; y < 280; y += 30 {
+		dw.Line(40, y, 360, y)
+	}
+	if err := mw.DrawImage(dw); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.RotateImage(white, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.SetImageArtifact("deskew:auto-crop", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := mw.GetImageArtifact("deskew:auto-crop"); err != nil || v != "true" {
+		t.Fatalf("Expected deskew:auto-crop artifact to be set, got %q (%v)", v, err)
+	}
+
+	_, qrange := GetQuantumRange()
+	if err := mw.DeskewImage(0.4 * float64(qrange)); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := mw.GetImageArtifact("deskew:angle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	angle, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(math.Abs(angle)-5) > 1 {
+		t.Fatalf("Expected a detected angle of about 5 degrees, got %v", angle)
+	}
+
+	if _, err := mw.GetImageArtifact("no:such-artifact"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a missing artifact, got %v", err)
+	}
+}
+
+func TestJpegSizeOption(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	big := NewMagickWand()
+	defer big.Destroy()
+
+	if err := big.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+	if err := big.ScaleImage(1600, 1200); err != nil {
+		t.Fatal(err)
+	}
+	if err := big.SetImageFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+	blob := big.GetImageBlob()
+
+	full := NewMagickWand()
+	defer full.Destroy()
+	if err := full.ReadImageBlob(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	shrunk := NewMagickWand()
+	defer shrunk.Destroy()
+	if err := shrunk.SetOption("jpeg:size", "200x200"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := shrunk.GetOption("jpeg:size"); err != nil || v != "200x200" {
+		t.Fatalf("Expected jpeg:size option to be set, got %q (%v)", v, err)
+	}
+	if err := shrunk.ReadImageBlob(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	if full.GetImageWidth() != 1600 {
+		t.Fatalf("Expected a full decode of 1600px, got %d", full.GetImageWidth())
+	}
+	if w := shrunk.GetImageWidth(); w >= 1600 || w < 200 {
+		t.Fatalf("Expected jpeg:size to shrink on load to at least 200px, got %d", w)
+	}
+
+	if err := shrunk.DeleteOption("jpeg:size"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shrunk.GetOption("jpeg:size"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a deleted option, got %v", err)
+	}
+}
+
+func TestPreReadHints(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	raw := NewMagickWand()
+	defer raw.Destroy()
+
+	if err := raw.SetSize(64, 32); err != nil {
+		t.Fatal(err)
+	}
+	if cols, rows, err := raw.GetSize(); err != nil || cols != 64 || rows != 32 {
+		t.Fatalf("Expected size 64x32, got %dx%d (%v)", cols, rows, err)
+	}
+	if err := raw.SetFormat("GRAY"); err != nil {
+		t.Fatal(err)
+	}
+	if f := raw.GetFormat(); f != "GRAY" {
+		t.Fatalf("Expected format GRAY, got %q", f)
+	}
+	if err := raw.SetDepth(8); err != nil {
+		t.Fatal(err)
+	}
+	if err := raw.ReadImageBlob(make([]byte, 64*32)); err != nil {
+		t.Fatal(err)
+	}
+	if raw.GetImageWidth() != 64 || raw.GetImageHeight() != 32 {
+		t.Fatalf("Expected a 64x32 raw image, got %dx%d", raw.GetImageWidth(), raw.GetImageHeight())
+	}
+
+	gradient := NewMagickWand()
+	defer gradient.Destroy()
+
+	if err := gradient.SetSize(20, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := gradient.ReadImage("gradient:red-blue"); err != nil {
+		t.Fatal(err)
+	}
+	if gradient.GetImageWidth() != 20 || gradient.GetImageHeight() != 100 {
+		t.Fatalf("Expected a 20x100 gradient, got %dx%d", gradient.GetImageWidth(), gradient.GetImageHeight())
+	}
+}
+
+func TestPreReadSettings(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// One inch wide, half an inch high
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="72pt" height="36pt">` +
+		`<rect width="100%" height="100%" fill="red"/></svg>`)
+
+	// Returns the size of the SVG rasterized at the given density
+	rasterize := func(density float64) (uint, uint) {
+		mw := NewMagickWand()
+		defer mw.Destroy()
+		if err := mw.SetResolution(density, density); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetFormat("SVG"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.ReadImageBlob(svg); err != nil {
+			t.Fatal(err)
+		}
+		return mw.GetImageWidth(), mw.GetImageHeight()
+	}
+
+	w72, h72 := rasterize(72)
+	w300, h300 := rasterize(300)
+	if w72 < 70 || w72 > 74 || h72 < 34 || h72 > 38 {
+		t.Fatalf("Expected about 72x36 at 72 DPI, got %dx%d", w72, h72)
+	}
+	if w300 < 298 || w300 > 302 || h300 < 148 || h300 > 152 {
+		t.Fatalf("Expected about 300x150 at 300 DPI, got %dx%d", w300, h300)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	blue := NewPixelWand()
+	defer blue.Destroy()
+	blue.SetColor("blue")
+
+	if err := mw.SetBackgroundColor(blue); err != nil {
+		t.Fatal(err)
+	}
+	bg, err := mw.GetBackgroundColor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bg.Destroy()
+	if !bg.IsSimilar(blue, 0) {
+		t.Fatalf("Expected background blue, got %s", bg.GetColorAsString())
+	}
+
+	settings := []error{
+		mw.SetColorspace(COLORSPACE_GRAY),
+		mw.SetCompression(COMPRESSION_ZIP),
+		mw.SetCompressionQuality(90),
+		mw.SetGravity(GRAVITY_CENTER),
+		mw.SetInterlaceScheme(INTERLACE_PLANE),
+	}
+	for _, err := range settings {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mw.GetColorspace() != COLORSPACE_GRAY || mw.GetCompression() != COMPRESSION_ZIP ||
+		mw.GetCompressionQuality() != 90 || mw.GetGravity() != GRAVITY_CENTER ||
+		mw.GetInterlaceScheme() != INTERLACE_PLANE {
+		t.Fatal("Wand settings did not round trip")
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+
+// MagickInterpolativeResizeImage was added in ImageMagick 6.7.7
+#if MagickLibVersion < 0x677
+#define HAVE_INTERPOLATIVE_RESIZE 0
+static MagickBooleanType MagickInterpolativeResizeImage(MagickWand *wand,
+	const size_t columns, const size_t rows,
+	const InterpolatePixelMethod method) {
+	return MagickFalse;
+}
+#else
+#define HAVE_INTERPOLATIVE_RESIZE 1
+#endif
+*/
+import "C"
+
+import (
+	"errors"
+	"math"
+	"runtime"
+)
+
+// Produces a thumbnail of exactly width x height which covers the whole box.
+// The image is scaled by the larger of the two ratios, so it is enlarged if
+// smaller than the box, and the overflow is cropped according to gravity.
+// The virtual canvas is reset so no pre-crop page geometry is left behind.
+func (mw *MagickWand) ThumbnailCover(width, height uint, gravity GravityType) error {
+	if width == 0 || height == 0 {
+		return errors.New("ThumbnailCover: width and height must not be 0")
+	}
+	cols, rows := mw.GetImageWidth(), mw.GetImageHeight()
+	if cols == 0 || rows == 0 {
+		return errors.New("ThumbnailCover: wand has no image")
+	}
+
+	scale := math.Max(float64(width)/float64(cols), float64(height)/float64(rows))
+	newCols, newRows := coverSize(cols, scale, width), coverSize(rows, scale, height)
+
+	// Crop offsets are relative to the virtual canvas, drop it up front
+	if err := mw.ResetImagePage(""); err != nil {
+		return err
+	}
+	if newCols != cols || newRows != rows {
+		if err := mw.ResizeImage(newCols, newRows, FILTER_LANCZOS, 1); err != nil {
+			return err
+		}
+	}
+	if newCols != width || newRows != height {
+		x, y := gravityOffset(newCols, newRows, width, height, gravity)
+		if err := mw.CropImage(width, height, x, y); err != nil {
+			return err
+		}
+	}
+	return mw.ResetImagePage("")
+}
+
+// Returns size scaled by scale, rounded up so that it is never below min.
+// A small tolerance keeps float noise from adding a needless extra pixel.
+func coverSize(size uint, scale float64, min uint) uint {
+	scaled := uint(math.Ceil(float64(size)*scale - 1e-9))
+	if scaled < min {
+		scaled = min
+	}
+	return scaled
+}
+
+// Resizes the current image in linear light, which avoids the dark fringes
+// that resizing gamma encoded sRGB leaves on high contrast edges. sRGB images
+// are transformed to linear RGB for the resize and back to sRGB, or, for
+// grayscale images and ImageMagick versions before 6.7.7 where RGB is not
+// linear, decoded and encoded with a gamma of 2.2. Linear RGB images are
+// resized as they are. Other colorspaces such as CMYK or Lab are resized
+// without conversion. Only the color channels are converted, alpha is
+// resized unchanged.
+func (mw *MagickWand) ResizeImageLinear(columns, rows uint, filter FilterType) error {
+	switch mw.GetImageColorspace() {
+	case COLORSPACE_SRGB:
+		if _, version := GetVersion(); version >= 0x677 {
+			if err := mw.TransformImageColorspace(COLORSPACE_RGB); err != nil {
+				return err
+			}
+			if err := mw.ResizeImage(columns, rows, filter, 1); err != nil {
+				return err
+			}
+			return mw.TransformImageColorspace(COLORSPACE_SRGB)
+		}
+	case COLORSPACE_GRAY:
+	default:
+		return mw.ResizeImage(columns, rows, filter, 1)
+	}
+
+	const color = CHANNEL_RED | CHANNEL_GREEN | CHANNEL_BLUE
+	if err := mw.GammaImageChannel(color, 1/2.2); err != nil {
+		return err
+	}
+	if err := mw.ResizeImage(columns, rows, filter, 1); err != nil {
+		return err
+	}
+	return mw.GammaImageChannel(color, 2.2)
+}
+
+// ThumbOptions tunes ThumbnailSharp, the zero value selects the defaults
+type ThumbOptions struct {
+	// Resize filter, FILTER_UNDEFINED means FILTER_LANCZOS
+	Filter FilterType
+	// Compression quality, 0 means 82
+	Quality uint
+	// Keep profiles and comments, which are stripped by default
+	KeepMetadata bool
+	// Do not enlarge either side, a side which is already smaller than the
+	// thumbnail keeps its size, so images which fit are left as they are
+	OnlyShrink bool
+}
+
+// Creates a thumbnail of exactly columns x rows of the current image the
+// way it is usually wanted on the web: the image is converted to sRGB
+// unless it is sRGB or grayscale, resized, sharpened with an unsharp mask
+// whose amount grows with the reduction of the image area to make up for
+// the softness of resizing, stripped of metadata and given a moderate
+// compression quality. Enlarged images are not sharpened. opts may be nil.
+func (mw *MagickWand) ThumbnailSharp(columns, rows uint, opts *ThumbOptions) error {
+	if columns == 0 || rows == 0 {
+		return errors.New("ThumbnailSharp: columns and rows must not be 0")
+	}
+	if opts == nil {
+		opts = &ThumbOptions{}
+	}
+	cols, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if cols == 0 || height == 0 {
+		return errors.New("ThumbnailSharp: wand has no image")
+	}
+
+	switch mw.GetImageColorspace() {
+	case COLORSPACE_SRGB, COLORSPACE_GRAY:
+	default:
+		if err := mw.ToSRGB(nil); err != nil {
+			return err
+		}
+	}
+
+	if opts.OnlyShrink {
+		columns, rows = minUint(columns, cols), minUint(rows, height)
+	}
+	if columns != cols || rows != height {
+		filter := opts.Filter
+		if filter == FILTER_UNDEFINED {
+			filter = FILTER_LANCZOS
+		}
+		if err := mw.ResizeImage(columns, rows, filter, 1); err != nil {
+			return err
+		}
+		// Half an amount per halving of the size, up to 1.5. The size is
+		// the geometric mean of the sides, so that shrinking only one side
+		// is not sharpened like shrinking both.
+		factor := math.Sqrt(float64(cols) / float64(columns) * float64(height) / float64(rows))
+		if factor > 1 {
+			amount := math.Min(0.5*math.Log2(factor), 1.5)
+			if err := mw.UnsharpMaskImage(0, 0.5, amount, 0.008); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !opts.KeepMetadata {
+		if err := mw.StripImage(); err != nil {
+			return err
+		}
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = 82
+	}
+	return mw.SetImageCompressionQuality(quality)
+}
+
+// Resizes the current image by interpolating the pixels of the image at
+// the location of each new pixel, rather than with a filter, see
+// SetImageInterpolateMethod. INTERPOLATE_PIXEL_NEAREST_NEIGHBOR keeps hard
+// edges, e.g. for classified raster data, INTERPOLATE_PIXEL_BILINEAR blends
+// neighbouring pixels. Before ImageMagick 6.7.7 nearest neighbor falls back
+// to SampleImage and the other methods to ResizeImage with a triangle
+// filter.
+func (mw *MagickWand) InterpolativeResizeImage(columns, rows uint, method InterpolatePixelMethod) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	if C.HAVE_INTERPOLATIVE_RESIZE == 0 {
+		if method == INTERPOLATE_PIXEL_NEAREST_NEIGHBOR {
+			return mw.SampleImage(columns, rows)
+		}
+		return mw.ResizeImage(columns, rows, FILTER_TRIANGLE, 1)
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ok := C.MagickInterpolativeResizeImage(mw.mw, C.size_t(columns), C.size_t(rows), C.InterpolatePixelMethod(method))
+	return mw.getLastErrorIfFailed(ok)
+}
+
+// Resizes every image of a sequence, e.g. the frames of an animated GIF, see
+// ResizeImage. Sequences are coalesced so that frames with page offsets are
+// resized as full frames, and optimized again afterwards. Frame delays are
+// kept. A single image is resized in place.
+func (mw *MagickWand) ResizeImages(cols, rows uint, filter FilterType, blur float64) error {
+	return mw.transformFrames(func(frame *MagickWand) error {
+		return frame.ResizeImage(cols, rows, filter, blur)
+	})
+}
+
+// Crops every image of a sequence, see ResizeImages and CropImage. The
+// virtual canvas of the result is the cropped size.
+func (mw *MagickWand) CropImages(width, height uint, x, y int) error {
+	return mw.transformFrames(func(frame *MagickWand) error {
+		if err := frame.CropImage(width, height, x, y); err != nil {
+			return err
+		}
+		return frame.ResetImagePage("")
+	})
+}
+
+// Creates thumbnails of every image of a sequence, see ResizeImages and
+// ThumbnailImage.
+func (mw *MagickWand) ThumbnailImages(cols, rows uint) error {
+	return mw.transformFrames(func(frame *MagickWand) error {
+		return frame.ThumbnailImage(cols, rows)
+	})
+}
+
+// Applies op to the current image of a single image wand, or to every frame
+// of a coalesced copy of a sequence which then replaces the wand images.
+func (mw *MagickWand) transformFrames(op func(frame *MagickWand) error) error {
+	if mw.GetNumberImages() <= 1 {
+		return op(mw)
+	}
+
+	coalesced, err := mw.coalesced()
+	if err != nil {
+		return err
+	}
+	defer coalesced.Destroy()
+
+	coalesced.ResetIterator()
+	for coalesced.NextImage() {
+		if err := op(coalesced); err != nil {
+			return err
+		}
+	}
+	return mw.replaceOptimized(coalesced)
+}
+
+// Returns a new wand holding the images of the sequence as full frames
+func (mw *MagickWand) coalesced() (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	ccoalesced := C.MagickCoalesceImages(mw.mw)
+	runtime.KeepAlive(mw)
+	if ccoalesced == nil {
+		return nil, mw.getLastErrorIfFailed(C.MagickFalse)
+	}
+	return newMagickWand(ccoalesced), nil
+}
+
+// Replaces the images of the wand with the full frames of coalesced,
+// optimized again as layers.
+func (mw *MagickWand) replaceOptimized(coalesced *MagickWand) error {
+	if err := coalesced.valid(); err != nil {
+		return err
+	}
+	coalesced.mu.Lock()
+	coptimized := C.MagickOptimizeImageLayers(coalesced.mw)
+	runtime.KeepAlive(coalesced)
+	var err error
+	if coptimized == nil {
+		err = coalesced.getLastErrorIfFailed(C.MagickFalse)
+	}
+	coalesced.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	optimized := newMagickWand(coptimized)
+	defer optimized.Destroy()
+
+	return mw.replaceImages(optimized)
+}
+
+// Replaces all images of the wand with copies of the images of source,
+// keeping the wand settings.
+func (mw *MagickWand) replaceImages(source *MagickWand) error {
+	mw.ResetIterator()
+	for mw.GetNumberImages() > 0 {
+		if err := mw.RemoveImage(); err != nil {
+			return err
+		}
+	}
+	if err := mw.AddImage(source); err != nil {
+		return err
+	}
+	mw.ResetIterator()
+	return nil
+}
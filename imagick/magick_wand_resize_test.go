@@ -0,0 +1,307 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestThumbnailCover(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	tests := []struct {
+		name          string
+		srcW, srcH    uint
+		width, height uint
+	}{
+		{"landscape to portrait", 400, 200, 100, 150},
+		{"portrait to landscape", 200, 400, 150, 100},
+		{"square to square", 300, 300, 100, 100},
+		{"odd ratio", 333, 199, 64, 64},
+		{"upscale", 50, 30, 200, 200},
+		{"exact fit", 120, 80, 120, 80},
+	}
+
+	for _, tt := range tests {
+		mw := NewMagickWand()
+		if err := mw.ReadImage(fmt.Sprintf("gradient:red-blue[%dx%d]", tt.srcW, tt.srcH)); err != nil {
+			t.Fatal(err)
+		}
+		// Give the source a page offset which the crop must not keep
+		if err := mw.SetImagePage(tt.srcW+10, tt.srcH+10, 5, 5); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := mw.ThumbnailCover(tt.width, tt.height, GRAVITY_CENTER); err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			mw.Destroy()
+			continue
+		}
+		if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != tt.width || h != tt.height {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tt.name, tt.width, tt.height, w, h)
+		}
+		if _, _, x, y, err := mw.GetImagePage(); err != nil || x != 0 || y != 0 {
+			t.Errorf("%s: expected the page offset to be reset, got %+d%+d (%v)", tt.name, x, y, err)
+		}
+		mw.Destroy()
+	}
+}
+
+// Returns a 40x30 three frame GIF animation. The last frame is a 10x10
+// square at a page offset of +5+5.
+func animatedGIF(t *testing.T) []byte {
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	frames := []struct {
+		image string
+		delay uint
+	}{
+		{"xc:red[40x30]", 10},
+		{"xc:lime[40x30]", 20},
+		{"xc:blue[10x10]", 30},
+	}
+	for _, f := range frames {
+		if err := mw.ReadImage(f.image); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageDelay(f.delay); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.SetImagePage(40, 30, 5, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	mw.ResetIterator()
+	for mw.NextImage() {
+		if err := mw.SetImageFormat("GIF"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mw.GetImagesBlob()
+}
+
+func TestResizeImages(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImageBlob(animatedGIF(t)); err != nil {
+		t.Fatal(err)
+	}
+	if n := mw.GetNumberImages(); n != 3 {
+		t.Fatalf("Expected 3 frames in the fixture, got %d", n)
+	}
+
+	if err := mw.ResizeImages(20, 15, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := mw.GetNumberImages(); n != 3 {
+		t.Fatalf("Expected ResizeImages to keep 3 frames, got %d", n)
+	}
+
+	delays := []uint{10, 20, 30}
+	coalesced, err := mw.CoalesceImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coalesced.Destroy()
+	coalesced.ResetIterator()
+	for i := 0; coalesced.NextImage(); i++ {
+		if w, h := coalesced.GetImageWidth(), coalesced.GetImageHeight(); w != 20 || h != 15 {
+			t.Errorf("Frame %d: expected 20x15, got %dx%d", i, w, h)
+		}
+		if d := coalesced.GetImageDelay(); d != delays[i] {
+			t.Errorf("Frame %d: expected delay %d, got %d", i, delays[i], d)
+		}
+	}
+
+	// The square of the last frame is at +5+5 on the original canvas, so
+	// about +2+2 to +7+7 after halving
+	if !coalesced.SetIteratorIndex(2) {
+		t.Fatal("Could not select the last frame")
+	}
+	expectPixelColor(t, coalesced, 5, 5, "blue")
+	expectPixelColor(t, coalesced, 15, 12, "lime")
+
+	single := NewMagickWand()
+	defer single.Destroy()
+	if err := single.ReadImage("xc:red[40x30]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := single.CropImages(10, 10, 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := single.GetImageWidth(), single.GetImageHeight(); w != 10 || h != 10 {
+		t.Fatalf("Expected a 10x10 crop, got %dx%d", w, h)
+	}
+}
+
+func TestResizeImageLinear(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// 0.5 in linear light is 188 in sRGB, a gamma encoded resize gives 128
+	for _, colorspace := range []ColorspaceType{COLORSPACE_SRGB, COLORSPACE_GRAY} {
+		checkerboard := make([]byte, 8*8)
+		for i := range checkerboard {
+			if (i/8+i%8)%2 == 0 {
+				checkerboard[i] = 255
+			}
+		}
+		mw := NewMagickWand()
+		if err := mw.ConstituteImage(8, 8, "I", PIXEL_CHAR, checkerboard); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageColorspace(colorspace); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := mw.ResizeImageLinear(4, 4, FILTER_BOX); err != nil {
+			t.Fatal(err)
+		}
+		if cs := mw.GetImageColorspace(); cs != colorspace {
+			t.Errorf("Colorspace %d changed to %d", colorspace, cs)
+		}
+		val, err := mw.ExportImagePixels(0, 0, 4, 4, "R", PIXEL_CHAR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range val.([]byte) {
+			if v < 180 || v > 195 {
+				t.Fatalf("Colorspace %d: pixel %d is %d, expected about 187", colorspace, i, v)
+			}
+		}
+		mw.Destroy()
+	}
+}
+
+func TestThumbnailSharp(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	read := func() *MagickWand {
+		mw := NewMagickWand()
+		if err := mw.ReadImage("logo:"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageProfile("exif", exifProfile(ORIENTATION_TOP_LEFT)); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageFormat("JPEG"); err != nil {
+			t.Fatal(err)
+		}
+		return mw
+	}
+
+	naive := read()
+	defer naive.Destroy()
+	if err := naive.ResizeImage(160, 120, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := read()
+	defer mw.Destroy()
+	if err := mw.ThumbnailSharp(160, 120, nil); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 160 || h != 120 {
+		t.Errorf("Expected 160x120, got %dx%d", w, h)
+	}
+	if q := mw.GetImageCompressionQuality(); q != 82 {
+		t.Errorf("Expected quality 82, got %d", q)
+	}
+
+	thumb := encodeDecode(t, mw, "JPEG")
+	defer thumb.Destroy()
+	if exif := thumb.GetImageProperties("exif:*"); len(exif) != 0 {
+		t.Errorf("Expected no EXIF properties, got %v", exif)
+	}
+	if sharp, plain := len(mw.GetImageBlob()), len(naive.GetImageBlob()); sharp >= plain {
+		t.Errorf("Expected the thumbnail (%d bytes) to be smaller than a plain resize (%d bytes)", sharp, plain)
+	}
+
+	// Small images are left at their size with OnlyShrink
+	small := NewMagickWand()
+	defer small.Destroy()
+	if err := small.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	opts := &ThumbOptions{OnlyShrink: true, KeepMetadata: true, Quality: 90}
+	if err := small.ThumbnailSharp(160, 120, opts); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := small.GetImageWidth(), small.GetImageHeight(); w != 70 || h != 46 {
+		t.Errorf("Expected rose: to keep its 70x46, got %dx%d", w, h)
+	}
+
+	// Only the side which is larger than the thumbnail shrinks
+	banner := NewMagickWand()
+	defer banner.Destroy()
+	if err := banner.ReadImage("gradient:red-blue[2000x100]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := banner.ThumbnailSharp(160, 120, &ThumbOptions{OnlyShrink: true}); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := banner.GetImageWidth(), banner.GetImageHeight(); w != 160 || h != 100 {
+		t.Errorf("Expected the 2000x100 banner to become 160x100, got %dx%d", w, h)
+	}
+}
+
+func TestInterpolativeResizeImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	for _, method := range []InterpolatePixelMethod{INTERPOLATE_PIXEL_NEAREST_NEIGHBOR, INTERPOLATE_PIXEL_BILINEAR} {
+		mw := NewMagickWand()
+		if err := mw.ConstituteImage(2, 2, "I", PIXEL_CHAR, []byte{255, 0, 0, 255}); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.InterpolativeResizeImage(4, 4, method); err != nil {
+			t.Fatal(err)
+		}
+		if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 4 || h != 4 {
+			t.Fatalf("Expected 4x4, got %dx%d", w, h)
+		}
+		val, err := mw.ExportImagePixels(0, 0, 4, 4, "R", PIXEL_CHAR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		intermediate := 0
+		for _, v := range val.([]byte) {
+			if v != 0 && v != 255 {
+				intermediate++
+			}
+		}
+		if method == INTERPOLATE_PIXEL_NEAREST_NEIGHBOR && intermediate != 0 {
+			t.Errorf("Expected only black and white with nearest neighbor, got %v", val)
+		}
+		if method == INTERPOLATE_PIXEL_BILINEAR && intermediate == 0 {
+			t.Errorf("Expected intermediate values with bilinear, got %v", val)
+		}
+		mw.Destroy()
+	}
+}
@@ -5,9 +5,19 @@
 package imagick
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
 	"reflect"
 	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -99,6 +109,55 @@ func TestQueryFonts(t *testing.T) {
 	}
 }
 
+func TestQueryFontMetrics(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+
+	if _, err := mw.QueryFontMetrics(dw, "text"); err == nil {
+		t.Fatal("Expected an error measuring text on a wand without an image")
+	}
+	if err := mw.ReadImage("xc:white"); err != nil {
+		t.Fatal(err)
+	}
+
+	dw.SetFontSize(20)
+	small, err := mw.QueryFontMetrics(dw, "Hello, world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dw.SetFontSize(40)
+	large, err := mw.QueryFontMetrics(dw, "Hello, world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio := large.TextWidth / small.TextWidth; ratio < 1.8 || ratio > 2.2 {
+		t.Fatalf("Expected doubling the font size to double the width, got %v -> %v", small.TextWidth, large.TextWidth)
+	}
+	if large.Ascender <= small.Ascender {
+		t.Fatalf("Expected a larger ascender at a larger size, got %v -> %v", small.Ascender, large.Ascender)
+	}
+
+	line, err := mw.QueryMultilineFontMetrics(dw, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := mw.QueryMultilineFontMetrics(dw, "a\nb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines.TextHeight <= line.TextHeight {
+		t.Fatalf("Expected two lines to be taller than one, got %v and %v", lines.TextHeight, line.TextHeight)
+	}
+}
+
 func TestQueryFormats(t *testing.T) {
 	Initialize()
 	defer func(t *testing.T) {
@@ -151,6 +210,241 @@ func TestReadImageBlob(t *testing.T) {
 	}
 }
 
+func TestEmptyWandOperationsFail(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ResizeImage(10, 10, FILTER_LANCZOS, 1); err == nil {
+		t.Error("Expected ResizeImage to fail on an empty wand")
+	}
+	if err := mw.CropImage(10, 10, 0, 0); err == nil {
+		t.Error("Expected CropImage to fail on an empty wand")
+	}
+
+	tmp, err := ioutil.TempFile("", "imagick_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := mw.WriteImage(tmp.Name()); err == nil {
+		t.Error("Expected WriteImage to fail on an empty wand")
+	}
+}
+
+func TestReadImageBlobWarning(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+	blob := mw.GetImageBlob()
+
+	// A truncated JPEG still decodes, with a CorruptImageWarning
+	truncated := NewMagickWand()
+	defer truncated.Destroy()
+
+	err := truncated.ReadImageBlob(blob[:len(blob)*3/4])
+	var merr *MagickError
+	if !errors.As(err, &merr) {
+		t.Fatalf("Expected a *MagickError for a truncated JPEG, got %v", err)
+	}
+	if !merr.IsWarning() {
+		t.Fatalf("Expected a warning for a truncated JPEG, got %v", merr)
+	}
+	if truncated.GetNumberImages() != 1 {
+		t.Fatal("Expected the truncated JPEG to be read")
+	}
+
+	// Random bytes are a hard error
+	garbage := NewMagickWand()
+	defer garbage.Destroy()
+
+	noise := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(noise)
+	err = garbage.ReadImageBlob(noise)
+	if !errors.As(err, &merr) {
+		t.Fatalf("Expected a *MagickError for random bytes, got %v", err)
+	}
+	if merr.IsWarning() {
+		t.Fatalf("Expected an error for random bytes, got warning %v", merr)
+	}
+}
+
+func TestReaderWriterRoundTrip(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("PNG"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := mw.WriteImageWriter(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Fatal("Expected WriteImageWriter to respect the PNG format")
+	}
+
+	fromBuf := NewMagickWand()
+	defer fromBuf.Destroy()
+	if err := fromBuf.ReadImageReader(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if fromBuf.GetImageWidth() != mw.GetImageWidth() || fromBuf.GetImageHeight() != mw.GetImageHeight() {
+		t.Fatal("Image read from bytes.Buffer does not match the original size")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(mw.WriteImagesWriter(pw))
+	}()
+
+	fromPipe := NewMagickWand()
+	defer fromPipe.Destroy()
+	if err := fromPipe.ReadImageReader(pr); err != nil {
+		t.Fatal(err)
+	}
+	if fromPipe.GetImageWidth() != mw.GetImageWidth() || fromPipe.GetImageHeight() != mw.GetImageHeight() {
+		t.Fatal("Image read from io.Pipe does not match the original size")
+	}
+
+	if err := fromPipe.ReadImageReader(&bytes.Buffer{}); err == nil {
+		t.Fatal("Expected an error when reading from an empty reader")
+	}
+}
+
+func TestAutoOrientImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	background := NewPixelWand()
+	defer background.Destroy()
+
+	tests := []struct {
+		orientation OrientationType
+		degrees     float64
+	}{
+		{ORIENTATION_BOTTOM_RIGHT, 180}, // 3
+		{ORIENTATION_RIGHT_TOP, 90},     // 6
+		{ORIENTATION_LEFT_BOTTOM, 270},  // 8
+	}
+
+	for _, tt := range tests {
+		mw := NewMagickWand()
+		if err := mw.ReadImage(`logo:`); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.ScaleImage(64, 48); err != nil {
+			t.Fatal(err)
+		}
+
+		reference := mw.Clone()
+		if err := reference.RotateImage(background, tt.degrees); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := mw.SetImageOrientation(tt.orientation); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.AutoOrientImage(); err != nil {
+			t.Fatalf("[orientation %d] %s", tt.orientation, err)
+		}
+
+		if o := mw.GetImageOrientation(); o != ORIENTATION_TOP_LEFT {
+			t.Errorf("[orientation %d] Expected orientation to be reset, got %d", tt.orientation, o)
+		}
+		if mw.GetImageWidth() != reference.GetImageWidth() || mw.GetImageHeight() != reference.GetImageHeight() {
+			t.Errorf("[orientation %d] Expected %dx%d, got %dx%d", tt.orientation,
+				reference.GetImageWidth(), reference.GetImageHeight(), mw.GetImageWidth(), mw.GetImageHeight())
+		} else if d, err := mw.GetImageDistortion(reference, METRIC_ROOT_MEAN_SQUARED_ERROR); err != nil || d != 0 {
+			t.Errorf("[orientation %d] Expected pixels to match the reference, distortion %v (%v)", tt.orientation, d, err)
+		}
+
+		reference.Destroy()
+		mw.Destroy()
+	}
+}
+
+func TestResourceLimit(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	previous := GetResourceLimit(RESOURCE_AREA)
+	defer SetResourceLimit(RESOURCE_AREA, previous)
+
+	if err := SetResourceLimit(RESOURCE_AREA, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if limit := GetResourceLimit(RESOURCE_AREA); limit != 1000 {
+		t.Fatalf("Expected area limit 1000, got %d", limit)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.SetSize(5000, 10); err != nil {
+		t.Fatal(err)
+	}
+	err := mw.ReadImage("xc:white")
+	var merr *MagickError
+	if !errors.As(err, &merr) {
+		t.Fatalf("Expected a *MagickError for an image larger than the area limit, got %v", err)
+	}
+	if merr.Severity != ERROR_RESOURCE_LIMIT || !strings.Contains(strings.ToLower(merr.Description), "resource") {
+		t.Errorf("Expected a resource limit error, got %v", merr)
+	}
+
+	// The wand method takes the same limit as the package level function
+	if err := mw.SetResourceLimit(RESOURCE_AREA, previous); err != nil {
+		t.Fatal(err)
+	}
+	if limit := GetResourceLimit(RESOURCE_AREA); limit != previous {
+		t.Fatalf("Expected area limit %d, got %d", previous, limit)
+	}
+
+	mw.Destroy()
+	if err := mw.SetResourceLimit(RESOURCE_AREA, 1000); !errors.Is(err, ErrWandDestroyed) {
+		t.Errorf("Expected ErrWandDestroyed, got %v", err)
+	}
+	if limit := GetResourceLimit(RESOURCE_AREA); limit != previous {
+		t.Errorf("Expected a destroyed wand to leave the area limit at %d, got %d", previous, limit)
+	}
+}
+
 func TestExportImagePixels(t *testing.T) {
 	Initialize()
 	defer func(t *testing.T) {
@@ -290,6 +584,8 @@ func getObjectCountersString() string {
 	str += fmt.Sprintf("drawingWandCounter %d\n", atomic.LoadInt64(&drawingWandCounter))
 	str += fmt.Sprintf("pixelIteratorCounter %d\n", atomic.LoadInt64(&pixelIteratorCounter))
 	str += fmt.Sprintf("pixelWandCounter %d\n", atomic.LoadInt64(&pixelWandCounter))
+	str += fmt.Sprintf("kernelInfoCounter %d\n", atomic.LoadInt64(&kernelInfoCounter))
+	str += fmt.Sprintf("blobCounter %d\n", atomic.LoadInt64(&blobCounter))
 
 	return str
 }
@@ -317,6 +613,74 @@ func BenchmarkExportImagePixels(b *testing.B) {
 	}
 }
 
+func BenchmarkExportImagePixelsInto(b *testing.B) {
+	wand := NewMagickWand()
+
+	wand.ReadImage("logo:")
+	wand.ScaleImage(1024, 1024)
+
+	pixels := make([]float32, 1024*1024*3)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := wand.ExportImagePixelsInto(0, 0, 1024, 1024, "RGB", PIXEL_FLOAT, pixels); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestExportImagePixelsInto(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ScaleImage(64, 32); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := mw.ExportImagePixels(0, 0, 64, 32, "RGBA", PIXEL_CHAR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := val.([]byte)
+
+	got := make([]byte, 64*32*4)
+	if err := mw.ExportImagePixelsInto(0, 0, 64, 32, "RGBA", PIXEL_UNDEFINED, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("ExportImagePixelsInto differs from ExportImagePixels")
+	}
+
+	if err := mw.ExportImagePixelsInto(0, 0, 64, 32, "RGBA", PIXEL_FLOAT, got); err == nil {
+		t.Fatal("expected an error exporting PIXEL_FLOAT into a []byte")
+	}
+	if err := mw.ExportImagePixelsInto(0, 0, 64, 32, "RGBA", PIXEL_CHAR, got[:len(got)-1]); err == nil {
+		t.Fatal("expected an error exporting into a short slice")
+	}
+	if err := mw.ExportImagePixelsInto(0, 0, 64, 32, "RGBA", PIXEL_CHAR, 42); err == nil {
+		t.Fatal("expected an error exporting into a non-slice")
+	}
+
+	floats := make([]float32, 64*32*3)
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := mw.ExportImagePixelsInto(0, 0, 64, 32, "RGB", PIXEL_FLOAT, floats); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+}
+
 func BenchmarkImportImagePixels(b *testing.B) {
 	wand := NewMagickWand()
 
@@ -335,6 +699,99 @@ func BenchmarkImportImagePixels(b *testing.B) {
 	b.StopTimer()
 }
 
+func TestImportImagePixelsRoundTrip(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ScaleImage(64, 48); err != nil {
+		t.Fatal(err)
+	}
+	want := mw.GetImageSignature()
+
+	for _, stype := range []StorageType{PIXEL_CHAR, PIXEL_FLOAT} {
+		pixels, err := mw.ExportImagePixels(0, 0, 64, 48, "RGB", stype)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		copied := NewMagickWand()
+		pw := NewPixelWand()
+		pw.SetColor("black")
+		if err := copied.NewImage(64, 48, pw); err != nil {
+			t.Fatal(err)
+		}
+		switch p := pixels.(type) {
+		case []byte:
+			err = copied.ImportImagePixelsFromBytes(0, 0, 64, 48, "RGB", p)
+		case []float32:
+			err = copied.ImportImagePixelsFromFloat32(0, 0, 64, 48, "RGB", p)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := copied.GetImageSignature(); got != want {
+			t.Errorf("storage type %d: signature %s after import, want %s", stype, got, want)
+		}
+
+		if err := copied.ImportImagePixels(0, 0, 64, 48, "RGB", PIXEL_DOUBLE, pixels); err == nil {
+			t.Errorf("storage type %d: expected an error importing as PIXEL_DOUBLE", stype)
+		}
+		if err := copied.ImportImagePixels(0, 0, 64, 47, "RGB", PIXEL_UNDEFINED, pixels); err == nil {
+			t.Errorf("storage type %d: expected an error importing too many pixels", stype)
+		}
+		if err := copied.ImportImagePixels(0, 0, 64, 49, "RGB", PIXEL_UNDEFINED, pixels); err == nil {
+			t.Errorf("storage type %d: expected an error importing too few pixels", stype)
+		}
+		pw.Destroy()
+		copied.Destroy()
+	}
+}
+
+func TestConstituteImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	pixels := []byte{
+		255, 0, 0, 255, 0, 255, 0, 255,
+		0, 0, 255, 255, 255, 255, 255, 255,
+	}
+	if err := mw.ConstituteImage(2, 2, "RGBA", PIXEL_UNDEFINED, pixels); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, mw, 0, 0, "red")
+	expectPixelColor(t, mw, 1, 0, "lime")
+	expectPixelColor(t, mw, 0, 1, "blue")
+	expectPixelColor(t, mw, 1, 1, "white")
+
+	err := mw.ConstituteImage(2, 2, "RGBA", PIXEL_UNDEFINED, pixels[:15])
+	if err == nil || !strings.Contains(err.Error(), "need 16 values, got 15") {
+		t.Fatalf("Expected a length error, got %v", err)
+	}
+	if err := mw.ConstituteImage(2, 2, "RGBX", PIXEL_UNDEFINED, pixels); err == nil {
+		t.Fatal("Expected an error for an invalid pmap")
+	}
+	if err := mw.ConstituteImage(2, 2, "RGBP", PIXEL_UNDEFINED, pixels); err != nil {
+		t.Fatal(err)
+	}
+	if n := mw.GetNumberImages(); n != 2 {
+		t.Fatalf("Expected 2 images, got %d", n)
+	}
+}
+
 func TestPixelInterfaceToPtr(t *testing.T) {
 	tests := []struct {
 		pixels  interface{}
@@ -344,6 +801,7 @@ func TestPixelInterfaceToPtr(t *testing.T) {
 		{[]float64{0}, PIXEL_DOUBLE},
 		{[]float32{0}, PIXEL_FLOAT},
 		{[]int16{0}, PIXEL_SHORT},
+		{[]uint16{0}, PIXEL_SHORT},
 		{[]int32{0}, PIXEL_INTEGER},
 		{[]int64{0}, PIXEL_LONG},
 	}
@@ -362,3 +820,859 @@ func TestPixelInterfaceToPtr(t *testing.T) {
 		t.Fatal("Expected error when passing invalid type")
 	}
 }
+
+func TestCompositeImageGravity(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	const dstW, dstH, srcW, srcH = 101, 81, 21, 12
+
+	src := NewMagickWand()
+	defer src.Destroy()
+	if err := src.ReadImage(fmt.Sprintf("xc:red[%dx%d]", srcW, srcH)); err != nil {
+		t.Fatal(err)
+	}
+
+	gravities := []struct {
+		gravity GravityType
+		x, y    int
+	}{
+		{GRAVITY_NORTH_WEST, 0, 0},
+		{GRAVITY_NORTH, 40, 0},
+		{GRAVITY_NORTH_EAST, 80, 0},
+		{GRAVITY_WEST, 0, 34},
+		{GRAVITY_CENTER, 40, 34},
+		{GRAVITY_EAST, 80, 34},
+		{GRAVITY_SOUTH_WEST, 0, 69},
+		{GRAVITY_SOUTH, 40, 69},
+		{GRAVITY_SOUTH_EAST, 80, 69},
+	}
+	for _, g := range gravities {
+		x, y := gravityOffset(dstW, dstH, srcW, srcH, g.gravity)
+		if x != g.x || y != g.y {
+			t.Errorf("Gravity %d: expected offset %d,%d, got %d,%d", g.gravity, g.x, g.y, x, y)
+			continue
+		}
+
+		dst := NewMagickWand()
+		if err := dst.ReadImage(fmt.Sprintf("xc:white[%dx%d]", dstW, dstH)); err != nil {
+			t.Fatal(err)
+		}
+		if err := dst.CompositeImageGravity(src, COMPOSITE_OP_OVER, g.gravity); err != nil {
+			t.Fatal(err)
+		}
+
+		expectPixelColor(t, dst, x, y, "red")
+		expectPixelColor(t, dst, x+srcW-1, y+srcH-1, "red")
+		if x > 0 {
+			expectPixelColor(t, dst, x-1, y, "white")
+		}
+		if x+srcW < dstW {
+			expectPixelColor(t, dst, x+srcW, y, "white")
+		}
+		if y > 0 {
+			expectPixelColor(t, dst, x, y-1, "white")
+		}
+		if y+srcH < dstH {
+			expectPixelColor(t, dst, x, y+srcH, "white")
+		}
+		dst.Destroy()
+	}
+
+	// A larger source is composited at negative offsets and covers everything
+	small := NewMagickWand()
+	defer small.Destroy()
+	if err := small.ReadImage("xc:white[15x15]"); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := gravityOffset(15, 15, srcW, srcH, GRAVITY_CENTER); x != -3 || y != 1 {
+		t.Fatalf("Expected center offset -3,1 for a wider source, got %d,%d", x, y)
+	}
+	if err := small.CompositeImageGravity(src, COMPOSITE_OP_OVER, GRAVITY_CENTER); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, small, 0, 7, "red")
+	expectPixelColor(t, small, 14, 7, "red")
+	expectPixelColor(t, small, 7, 0, "white")
+}
+
+func TestFrames(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	for _, c := range []string{"red", "lime", "blue", "yellow"} {
+		if err := mw.ReadImage("xc:" + c + "[20x10]"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := mw.NFrames(); n != 4 {
+		t.Fatalf("Expected 4 frames, got %d", n)
+	}
+
+	var seen []int
+	mw.Frames()(func(i int, frame *MagickWand) bool {
+		seen = append(seen, i)
+		if err := frame.ResizeImage(uint(10+i), 5, FILTER_POINT, 1); err != nil {
+			t.Fatal(err)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(seen, []int{0, 1, 2, 3}) {
+		t.Fatalf("Expected frames 0 to 3, got %v", seen)
+	}
+	for i := 0; i < 4; i++ {
+		mw.SetIteratorIndex(i)
+		if w := mw.GetImageWidth(); w != uint(10+i) {
+			t.Errorf("Frame %d: expected the resize in the loop to give width %d, got %d", i, 10+i, w)
+		}
+	}
+
+	// Breaking early leaves the wand at the frame the loop stopped on
+	mw.Frames()(func(i int, frame *MagickWand) bool {
+		return i < 2
+	})
+	if w := mw.GetImageWidth(); w != 12 {
+		t.Fatalf("Expected the wand positioned at frame 2 after break, got width %d", w)
+	}
+}
+
+func ExampleMagickWand_Frames() {
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	mw.ReadImage("animation.gif")
+
+	// With Go 1.23 or later: for _, frame := range mw.Frames() { ... }
+	mw.Frames()(func(i int, frame *MagickWand) bool {
+		return frame.TransformImageColorspace(COLORSPACE_GRAY) == nil
+	})
+	mw.WriteImages("animation-gray.gif", true)
+}
+
+func TestCloneImages(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	for i := 0; i < 10; i++ {
+		if err := mw.ReadImage("xc:gray[8x8]"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageScene(uint(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageDelay(uint(10 * i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clone, err := mw.CloneImages("0-3,7,9-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clone.Destroy()
+
+	want := []uint{0, 1, 2, 3, 7, 9, 8}
+	if n := clone.GetNumberImages(); n != uint(len(want)) {
+		t.Fatalf("Expected %d images, got %d", len(want), n)
+	}
+	for i, scene := range want {
+		clone.SetIteratorIndex(i)
+		if s := clone.GetImageScene(); s != scene {
+			t.Errorf("Image %d: expected scene %d, got %d", i, scene, s)
+		}
+		if d := clone.GetImageDelay(); d != 10*scene {
+			t.Errorf("Image %d: expected delay %d, got %d", i, 10*scene, d)
+		}
+	}
+	if n := mw.GetNumberImages(); n != 10 {
+		t.Fatalf("Expected the source wand to keep 10 images, got %d", n)
+	}
+
+	for _, scenes := range []string{"0-10", "12", "a-b", ""} {
+		if w, err := mw.CloneImages(scenes); err == nil {
+			w.Destroy()
+			t.Errorf("Expected an error for scenes %q", scenes)
+		}
+	}
+}
+
+func TestExtractImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	for i := 0; i < 4; i++ {
+		if err := mw.ReadImage(fmt.Sprintf("xc:white[%dx1]", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The poster frame, the current image moves on to the next frame
+	mw.SetIteratorIndex(0)
+	poster, err := mw.ExtractImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer poster.Destroy()
+	if poster.GetNumberImages() != 1 || poster.GetImageWidth() != 1 {
+		t.Fatalf("Expected the first frame, got %d images of width %d", poster.GetNumberImages(), poster.GetImageWidth())
+	}
+	if n := mw.GetNumberImages(); n != 3 {
+		t.Fatalf("Expected 3 images left, got %d", n)
+	}
+	if w := mw.GetImageWidth(); w != 2 {
+		t.Fatalf("Expected the current image to be the next frame, got width %d", w)
+	}
+
+	// At the end of the list the current image becomes the new last image
+	mw.SetLastIterator()
+	last, err := mw.ExtractImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer last.Destroy()
+	if w := last.GetImageWidth(); w != 4 {
+		t.Fatalf("Expected the last frame, got width %d", w)
+	}
+	if w := mw.GetImageWidth(); w != 3 {
+		t.Fatalf("Expected the current image to be the new last frame, got width %d", w)
+	}
+
+	single := NewMagickWand()
+	defer single.Destroy()
+	if err := single.ReadImage("xc:white[5x5]"); err != nil {
+		t.Fatal(err)
+	}
+	only, err := single.ExtractImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer only.Destroy()
+	if n := single.GetNumberImages(); n != 0 {
+		t.Fatalf("Expected an empty wand, got %d images", n)
+	}
+	if _, err := single.ExtractImage(); err == nil {
+		t.Fatal("Expected an error extracting from an empty wand")
+	}
+	if err := single.ResizeImage(2, 2, FILTER_POINT, 1); err == nil {
+		t.Fatal("Expected an error resizing an empty wand")
+	}
+}
+
+// Returns the bounding box of everything that is not the background
+func inkBox(t *testing.T, mw *MagickWand) (x, y int, width, height uint) {
+	t.Helper()
+
+	trimmed, err := mw.currentImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trimmed.Destroy()
+	if err := trimmed.TrimImage(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, x, y, err = trimmed.GetImagePage(); err != nil {
+		t.Fatal(err)
+	}
+	return x, y, trimmed.GetImageWidth(), trimmed.GetImageHeight()
+}
+
+func TestAnnotateImageGravity(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	dw.SetFontSize(20)
+	initial := dw.GetGravity()
+
+	for _, gravity := range []GravityType{GRAVITY_NORTH_WEST, GRAVITY_SOUTH_EAST} {
+		mw := NewMagickWand()
+		if err := mw.ReadImage("xc:white[200x200]"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.AnnotateImageGravity(dw, gravity, 12, 12, 0, "Hello\nWorld"); err != nil {
+			t.Fatal(err)
+		}
+		x, y, w, h := inkBox(t, mw)
+		mw.Destroy()
+		right, bottom := x+int(w), y+int(h)
+
+		switch gravity {
+		case GRAVITY_NORTH_WEST:
+			if x < 10 || y < 10 || right > 100 || bottom > 100 {
+				t.Errorf("NorthWest: expected the text within the top left quadrant, got %dx%d%+d%+d", w, h, x, y)
+			}
+		case GRAVITY_SOUTH_EAST:
+			// Both lines are above the bottom margin
+			if x < 100 || y < 100 || right > 190 || bottom > 190 {
+				t.Errorf("SouthEast: expected the text within the bottom right quadrant, got %dx%d%+d%+d", w, h, x, y)
+			}
+			if h < 30 {
+				t.Errorf("SouthEast: expected two lines of text, got a height of %d", h)
+			}
+		}
+	}
+	if g := dw.GetGravity(); g != initial {
+		t.Fatalf("Expected the gravity of the drawing wand to be unchanged, got %d", g)
+	}
+}
+
+func TestDeskewImageAngle(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:white[400x300]"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A page of text lines
+	black := NewPixelWand()
+	defer black.Destroy()
+	black.SetColor("black")
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	dw.SetFillColor(black)
+	for y := 40.0; y < 260; y += 24 {
+		dw.Rectangle(40, y, 360, y+8)
+	}
+	if err := mw.DrawImage(dw); err != nil {
+		t.Fatal(err)
+	}
+
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+	if err := mw.RotateImage(white, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	_, quantumRange := GetQuantumRange()
+	angle, err := mw.DeskewImageAngle(0.4 * float64(quantumRange))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(math.Abs(angle)-2) > 0.5 {
+		t.Errorf("Expected a skew of about 2 degrees, got %g", angle)
+	}
+}
+
+func TestTrimImageBox(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[60x40]"); err != nil {
+		t.Fatal(err)
+	}
+	white := NewPixelWand()
+	defer white.Destroy()
+	white.SetColor("white")
+	if err := mw.BorderImage(white, 15, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	box, err := mw.TrimImageBox(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box.GetX() != 15 || box.GetY() != 10 || box.GetWidth() != 60 || box.GetHeight() != 40 {
+		t.Errorf("Expected 60x40+15+10, got %dx%d%+d%+d", box.GetWidth(), box.GetHeight(), box.GetX(), box.GetY())
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 90 || h != 60 {
+		t.Fatalf("Expected the image to be left untouched, got %dx%d", w, h)
+	}
+
+	if _, err := mw.TrimImageBox(0, true); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 60 || h != 40 {
+		t.Errorf("Expected the image to be trimmed to 60x40, got %dx%d", w, h)
+	}
+
+	blank := NewMagickWand()
+	defer blank.Destroy()
+	if err := blank.ReadImage("xc:white[20x20]"); err != nil {
+		t.Fatal(err)
+	}
+	box, err = blank.TrimImageBox(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box.GetWidth() != 0 || box.GetHeight() != 0 {
+		t.Errorf("Expected an empty box for a blank image, got %dx%d", box.GetWidth(), box.GetHeight())
+	}
+}
+
+func TestGetImageBoundingBox(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:white[200x200]"); err != nil {
+		t.Fatal(err)
+	}
+	square := NewMagickWand()
+	defer square.Destroy()
+	if err := square.ReadImage("xc:red[50x70]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.CompositeImage(square, COMPOSITE_OP_OVER, 30, 40); err != nil {
+		t.Fatal(err)
+	}
+	signature := mw.GetImageSignature()
+
+	box, err := mw.GetImageBoundingBox(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box.GetWidth() != 50 || box.GetHeight() != 70 || box.GetX() != 30 || box.GetY() != 40 {
+		t.Errorf("Expected 50x70+30+40, got %dx%d%+d%+d", box.GetWidth(), box.GetHeight(), box.GetX(), box.GetY())
+	}
+	if mw.GetImageSignature() != signature {
+		t.Error("Expected the image to be left untouched")
+	}
+
+	if err := mw.ReadImage("xc:white[20x20]"); err != nil {
+		t.Fatal(err)
+	}
+	box, err = mw.GetImageBoundingBox(0)
+	if err != ErrEmptyBoundingBox {
+		t.Fatalf("Expected ErrEmptyBoundingBox for a blank image, got %v", err)
+	}
+	if box.GetWidth() != 0 || box.GetHeight() != 0 {
+		t.Errorf("Expected an empty box for a blank image, got %dx%d", box.GetWidth(), box.GetHeight())
+	}
+}
+
+func TestChannelImages(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	signature := mw.GetImageSignature()
+
+	planes, err := mw.ChannelImages(CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mw.GetImageSignature() != signature {
+		t.Error("Expected the image to be left untouched")
+	}
+
+	sequence := NewMagickWand()
+	defer sequence.Destroy()
+	for _, plane := range planes {
+		if err := sequence.AddImage(plane); err != nil {
+			t.Fatal(err)
+		}
+		plane.Destroy()
+	}
+	sequence.ResetIterator()
+	combined, err := sequence.CombineImages(CHANNEL_RED | CHANNEL_GREEN | CHANNEL_BLUE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer combined.Destroy()
+	if combined.GetImageSignature() != signature {
+		t.Error("Expected the recombined planes to reproduce the image")
+	}
+
+	if _, err := mw.ChannelImage(CHANNEL_ALPHA); err == nil {
+		t.Error("Expected an error for the alpha channel of an image without alpha")
+	}
+	if _, err := mw.ChannelImages(CHANNEL_RED, CHANNEL_ALPHA); err == nil {
+		t.Error("Expected an error for the alpha channel of an image without alpha")
+	}
+}
+
+func TestExtentImageGravity(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[100x50]"); err != nil {
+		t.Fatal(err)
+	}
+	blue := NewPixelWand()
+	defer blue.Destroy()
+	blue.SetColor("blue")
+
+	if err := mw.ExtentImageGravity(200, 200, GRAVITY_CENTER, blue); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 200 || h != 200 {
+		t.Fatalf("Expected 200x200, got %dx%d", w, h)
+	}
+
+	// The image covers (50,75) to (149,124)
+	for _, probe := range []struct {
+		x, y  int
+		color string
+	}{
+		{50, 75, "red"}, {149, 124, "red"},
+		{49, 75, "blue"}, {50, 74, "blue"},
+		{150, 124, "blue"}, {149, 125, "blue"},
+	} {
+		expectPixelColor(t, mw, probe.x, probe.y, probe.color)
+	}
+
+	if err := mw.ExtentImageGravity(300, 250, GRAVITY_SOUTH_EAST, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, mw, 299, 249, "blue")
+	expectPixelColor(t, mw, 99, 49, "blue")
+	expectPixelColor(t, mw, 100, 50, "blue")
+	expectPixelColor(t, mw, 150, 125, "red")
+}
+
+func TestSpliceAndChopImageGravity(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ResizeImage(100, 100, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	signature := mw.GetImageSignature()
+
+	green := NewPixelWand()
+	defer green.Destroy()
+	green.SetColor("lime")
+	if err := mw.SetImageBackgroundColor(green); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.SpliceImageGravity(0, 40, GRAVITY_SOUTH); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 100 || h != 140 {
+		t.Fatalf("Expected 100x140, got %dx%d", w, h)
+	}
+	expectPixelColor(t, mw, 0, 100, "lime")
+	expectPixelColor(t, mw, 99, 139, "lime")
+
+	if err := mw.ChopImageGravity(0, 40, GRAVITY_SOUTH); err != nil {
+		t.Fatal(err)
+	}
+	if s := mw.GetImageSignature(); s != signature {
+		t.Errorf("Expected chopping the band to restore the original image")
+	}
+
+	if err := mw.SpliceImageGravity(10, 40, GRAVITY_SOUTH); err == nil {
+		t.Error("Expected an error for a south band with a width")
+	}
+	if err := mw.ChopImageGravity(10, 40, GRAVITY_EAST); err == nil {
+		t.Error("Expected an error for an east band with a height")
+	}
+	if err := mw.ChopImageGravity(0, 100, GRAVITY_NORTH); err == nil {
+		t.Error("Expected an error for a band covering the whole image")
+	}
+}
+
+func TestDestroyedWandMethodsFail(t *testing.T) {
+	// A crash in C cannot be recovered, so the calls run in a child process
+	if os.Getenv("IMAGICK_TEST_DESTROYED_WAND") != "1" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestDestroyedWandMethodsFail$")
+		cmd.Env = append(os.Environ(), "IMAGICK_TEST_DESTROYED_WAND=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Calling methods of a destroyed wand failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	mw.Destroy()
+	mw.Destroy()
+
+	pw := NewPixelWand()
+	defer pw.Destroy()
+	for name, call := range map[string]func() error{
+		"ReadImage":        func() error { return mw.ReadImage("logo:") },
+		"WriteImage":       func() error { return mw.WriteImage(os.DevNull) },
+		"ResizeImage":      func() error { return mw.ResizeImage(10, 10, FILTER_BOX, 1) },
+		"CropImage":        func() error { return mw.CropImage(10, 10, 0, 0) },
+		"BlurImage":        func() error { return mw.BlurImage(0, 1) },
+		"SetImageFormat":   func() error { return mw.SetImageFormat("PNG") },
+		"SetOption":        func() error { return mw.SetOption("a", "b") },
+		"BorderImage":      func() error { return mw.BorderImage(pw, 1, 1) },
+		"GetImageBlob":     func() error { _, err := mw.GetImageBlob(), mw.GetLastError(); return err },
+		"GetImagePage":     func() error { _, _, _, _, err := mw.GetImagePage(); return err },
+		"GetImageProperty": func() error { _, err := mw.GetImageProperty("comment"); return err },
+		"PerceptualHash":   func() error { _, err := mw.PerceptualHash(); return err },
+	} {
+		if err := call(); !errors.Is(err, ErrWandDestroyed) {
+			t.Errorf("%s: expected ErrWandDestroyed, got %v", name, err)
+		}
+	}
+
+	// Getters without an error return the zero value
+	if mw.GetImageWidth() != 0 || mw.GetNumberImages() != 0 || mw.GetImageFormat() != "" {
+		t.Error("Expected getters of a destroyed wand to return zero values")
+	}
+	if mw.Clone() != nil {
+		t.Error("Expected no clone of a destroyed wand")
+	}
+	if coalesced, err := mw.CoalesceImages(); coalesced != nil || !errors.Is(err, ErrWandDestroyed) {
+		t.Errorf("Expected no wand and ErrWandDestroyed, got %v", err)
+	}
+
+	// A destroyed wand passed as an argument is not handed to ImageMagick
+	live := NewMagickWand()
+	defer live.Destroy()
+	if err := live.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	for name, call := range map[string]func() error{
+		"AddImage":         func() error { return live.AddImage(mw) },
+		"CompositeImage":   func() error { return live.CompositeImage(mw, COMPOSITE_OP_OVER, 0, 0) },
+		"SetImageClipMask": func() error { return live.SetImageClipMask(mw) },
+		"CompareImages":    func() error { _, _, err := live.CompareImages(mw, METRIC_ABSOLUTE_ERROR); return err },
+	} {
+		if err := call(); !errors.Is(err, ErrWandDestroyed) {
+			t.Errorf("%s: expected ErrWandDestroyed for a destroyed argument, got %v", name, err)
+		}
+	}
+
+	// No wand is returned when ImageMagick returns NULL
+	empty := NewMagickWand()
+	defer empty.Destroy()
+	if coalesced, err := empty.CoalesceImages(); coalesced != nil || err == nil {
+		t.Error("Expected an error and no wand from CoalesceImages of an empty wand")
+	}
+	if region, err := empty.GetImageRegion(10, 10, 0, 0); region != nil || err == nil {
+		t.Error("Expected an error and no wand from GetImageRegion of an empty wand")
+	}
+	var none *MagickWand
+	if err := none.ReadImage("logo:"); !errors.Is(err, ErrWandInvalid) {
+		t.Errorf("Expected ErrWandInvalid for a nil wand, got %v", err)
+	}
+}
+
+func TestConcurrentMethodErrors(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"first", "second"} {
+		wg.Add(2)
+		// Read-only getters
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 640 || h != 480 {
+					t.Errorf("Expected 640x480, got %dx%d", w, h)
+					return
+				}
+				if _, _, _, _, err := mw.GetImagePage(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		// Failing writes, each error must name its own file
+		dir := "/nonexistent-" + name
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				err := mw.WriteImage(dir + "/image.png")
+				if err == nil || !strings.Contains(err.Error(), dir) {
+					t.Errorf("Expected an error about %s, got %v", dir, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWriteFrames(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick_frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for _, frame := range []string{"xc:red[16x16]", "xc:lime[16x16]", "xc:blue[16x16]"} {
+		if err := mw.ReadImage(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := mw.WriteFrames(dir + "/frame_%03d.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{dir + "/frame_000.png", dir + "/frame_001.png", dir + "/frame_002.png"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Expected %q, got %q", want, paths)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 3 {
+		t.Errorf("Expected exactly 3 files, got %d", len(files))
+	}
+	if i := mw.GetIteratorIndex(); i != 0 {
+		t.Errorf("Expected the iterator to be reset, index is %d", i)
+	}
+
+	signatures := make(map[string]bool)
+	for i, path := range paths {
+		frame := NewMagickWand()
+		if err := frame.ReadImage(path); err != nil {
+			t.Fatal(err)
+		}
+		mw.SetIteratorIndex(i)
+		if frame.GetImageSignature() != mw.GetImageSignature() {
+			t.Errorf("%s does not hold frame %d", path, i)
+		}
+		signatures[frame.GetImageSignature()] = true
+		frame.Destroy()
+	}
+	if len(signatures) != 3 {
+		t.Errorf("Expected 3 different frames, got %d", len(signatures))
+	}
+
+	paths, err = mw.WriteFrames(dir + "/missing/frame_%d.png")
+	if err == nil || len(paths) != 0 || !strings.Contains(err.Error(), "frame 0") {
+		t.Errorf("Expected an error naming frame 0, got %v (%q)", err, paths)
+	}
+	if _, err := mw.WriteFrames(dir + "/frame.png"); err == nil {
+		t.Error("Expected an error for a pattern without a verb")
+	}
+}
+
+func TestCheckedPredicates(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	predicates := map[string]func() (bool, error){
+		"GetImageAlphaChannelChecked": mw.GetImageAlphaChannelChecked,
+		"HasNextImageChecked":         mw.HasNextImageChecked,
+		"HasPreviousImageChecked":     mw.HasPreviousImageChecked,
+		"NextImageChecked":            mw.NextImageChecked,
+		"PreviousImageChecked":        mw.PreviousImageChecked,
+	}
+	for name, predicate := range predicates {
+		if ok, err := predicate(); err == nil || ok {
+			t.Errorf("%s: expected an error on an empty wand, got %v, %v", name, ok, err)
+		}
+	}
+
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	mw.ResetIterator()
+	if alpha, err := mw.GetImageAlphaChannelChecked(); err != nil || alpha {
+		t.Errorf("Expected no alpha channel, got %v, %v", alpha, err)
+	}
+	if next, err := mw.NextImageChecked(); err != nil || !next {
+		t.Errorf("Expected the first image, got %v, %v", next, err)
+	}
+	if more, err := mw.HasNextImageChecked(); err != nil || !more {
+		t.Errorf("Expected a next image, got %v, %v", more, err)
+	}
+	mw.SetLastIterator()
+	if more, err := mw.HasNextImageChecked(); err != nil || more {
+		t.Errorf("Expected no next image after the last one, got %v, %v", more, err)
+	}
+
+	// The exception left by a getter without an error result is not
+	// returned by a later predicate
+	stale := NewMagickWand()
+	defer stale.Destroy()
+	if width := stale.GetImageWidth(); width != 0 {
+		t.Fatalf("Expected no width for an empty wand, got %d", width)
+	}
+	if err := stale.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if more, err := stale.HasNextImageChecked(); err != nil || more {
+		t.Errorf("Expected no next image and no stale error, got %v, %v", more, err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <wand/MagickWand.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+// MontageOptions describes the layout of MontageImages. Zero values select
+// the ImageMagick defaults.
+type MontageOptions struct {
+	// Number of tiles per row and column. If one is 0 it is computed from
+	// the number of images, if both are 0 the grid is about square.
+	Columns, Rows uint
+	// Maximum size of each thumbnail, 0 for both keeps 120x120, or the
+	// image size when concatenating
+	ThumbWidth, ThumbHeight uint
+	// Width of the border drawn around each thumbnail in BorderColor
+	BorderWidth uint
+	// Pixels of background around each tile, ignored when concatenating
+	Spacing uint
+	Mode    MontageMode
+	// Width and bevel of the ornamental frame drawn with MONTAGE_MODE_FRAME,
+	// a FrameWidth of 0 keeps 15x15+3+3
+	FrameWidth, FrameBevel uint
+	// Background defaults to the wand background color
+	Background *PixelWand
+	// Color of the ornamental frame
+	MatteColor *PixelWand
+	// Color of the thumbnail border
+	BorderColor *PixelWand
+	// Label drawn below each tile, escapes such as %f (filename), %wx%h
+	// (size) or %[exif:DateTime] are expanded per image
+	Label string
+}
+
+// Creates a composite image by tiling all images of the wand onto a single
+// grid, see MontageImage. Returns an error if the grid has fewer tiles than
+// there are images instead of spreading them over several pages.
+//
+// dw: the font name, size, fill and stroke color of the labels. May be nil.
+func (mw *MagickWand) MontageImages(dw *DrawingWand, opts MontageOptions) (*MagickWand, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	n := mw.GetNumberImages()
+	if n == 0 {
+		return nil, errors.New("MontageImages: wand has no images")
+	}
+	columns, rows := montageGrid(n, opts.Columns, opts.Rows)
+	if columns*rows < n {
+		return nil, fmt.Errorf("MontageImages: a %dx%d grid can not hold %d images", columns, rows, n)
+	}
+
+	background := opts.Background
+	if background == nil {
+		var err error
+		if background, err = mw.GetBackgroundColor(); err != nil {
+			return nil, err
+		}
+		defer background.Destroy()
+	}
+
+	info := C.AcquireImageInfo()
+	defer C.DestroyImageInfo(info)
+	montage := C.CloneMontageInfo(info, nil)
+	defer C.DestroyMontageInfo(montage)
+
+	setString := func(field **C.char, value string) {
+		cs := C.CString(value)
+		defer C.free(unsafe.Pointer(cs))
+		C.CloneString(field, cs)
+	}
+	setString(&montage.tile, fmt.Sprintf("%dx%d", columns, rows))
+	setString(&montage.geometry, montageThumbGeometry(opts))
+	montage.border_width = C.size_t(opts.BorderWidth)
+	C.PixelGetQuantumColor(background.pw, &montage.background_color)
+	if opts.MatteColor != nil {
+		C.PixelGetQuantumColor(opts.MatteColor.pw, &montage.matte_color)
+	}
+	if opts.BorderColor != nil {
+		C.PixelGetQuantumColor(opts.BorderColor.pw, &montage.border_color)
+	}
+	runtime.KeepAlive(background)
+	runtime.KeepAlive(opts.MatteColor)
+	runtime.KeepAlive(opts.BorderColor)
+
+	switch opts.Mode {
+	case MONTAGE_MODE_FRAME:
+		frame := "15x15+3+3"
+		if opts.FrameWidth > 0 {
+			frame = fmt.Sprintf("%dx%d+%d+%d", opts.FrameWidth, opts.FrameWidth, opts.FrameBevel, opts.FrameBevel)
+		}
+		setString(&montage.frame, frame)
+		montage.shadow = C.MagickTrue
+	case MONTAGE_MODE_CONCATENATE:
+		montage.border_width = 0
+	}
+
+	if dw != nil {
+		if cfont := C.DrawGetFont(dw.dw); cfont != nil {
+			C.CloneString(&montage.font, cfont)
+			relinquishMemory(unsafe.Pointer(cfont))
+		}
+		montage.pointsize = C.DrawGetFontSize(dw.dw)
+		pw := NewPixelWand()
+		C.DrawGetFillColor(dw.dw, pw.pw)
+		C.PixelGetQuantumColor(pw.pw, &montage.fill)
+		C.DrawGetStrokeColor(dw.dw, pw.pw)
+		C.PixelGetQuantumColor(pw.pw, &montage.stroke)
+		pw.Destroy()
+		runtime.KeepAlive(dw)
+	}
+
+	exc := C.AcquireExceptionInfo()
+	defer C.DestroyExceptionInfo(exc)
+
+	// Labels are set on a copy so the images of the wand are left untouched
+	images := C.CloneImageList(C.GetFirstImageInList(C.GetImageFromMagickWand(mw.mw)), exc)
+	runtime.KeepAlive(mw)
+	if images == nil {
+		return nil, montageError(exc)
+	}
+	defer C.DestroyImageList(images)
+	if opts.Label != "" {
+		cslabel := C.CString(opts.Label)
+		defer C.free(unsafe.Pointer(cslabel))
+		csproperty := C.CString("label")
+		defer C.free(unsafe.Pointer(csproperty))
+		for image := images; image != nil; image = C.GetNextImageInList(image) {
+			label := C.InterpretImageProperties(info, image, cslabel)
+			if label == nil {
+				continue
+			}
+			C.SetImageProperty(image, csproperty, label)
+			C.DestroyString(label)
+		}
+	}
+
+	result := C.MontageImageList(info, montage, images, exc)
+	if result == nil {
+		return nil, montageError(exc)
+	}
+	defer C.DestroyImageList(result)
+	cmw := C.NewMagickWandFromImage(result)
+	if cmw == nil {
+		return nil, errors.New("MontageImages: could not create wand")
+	}
+	return newMagickWand(cmw), nil
+}
+
+// Returns the number of columns and rows of a montage of n images, filling
+// in the ones which are 0.
+func montageGrid(n, columns, rows uint) (uint, uint) {
+	switch {
+	case columns == 0 && rows == 0:
+		columns = uint(math.Ceil(math.Sqrt(float64(n))))
+		rows = (n + columns - 1) / columns
+	case columns == 0:
+		columns = (n + rows - 1) / rows
+	case rows == 0:
+		rows = (n + columns - 1) / columns
+	}
+	return columns, rows
+}
+
+// Returns the montage thumbnail geometry, e.g. "120x120+4+4"
+func montageThumbGeometry(opts MontageOptions) string {
+	size := "120x120"
+	if opts.Mode == MONTAGE_MODE_CONCATENATE {
+		size = ""
+	}
+	if opts.ThumbWidth > 0 || opts.ThumbHeight > 0 {
+		size = ""
+		if opts.ThumbWidth > 0 {
+			size = fmt.Sprint(opts.ThumbWidth)
+		}
+		size += "x"
+		if opts.ThumbHeight > 0 {
+			size += fmt.Sprint(opts.ThumbHeight)
+		}
+	}
+	spacing := opts.Spacing
+	if opts.Mode == MONTAGE_MODE_CONCATENATE {
+		spacing = 0
+	}
+	return fmt.Sprintf("%s+%d+%d", size, spacing, spacing)
+}
+
+func montageError(exc *C.ExceptionInfo) error {
+	if err := checkExceptionInfo(exc); err != nil {
+		return err
+	}
+	return errors.New("MontageImages: montage failed")
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestMontageImages(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for _, color := range []string{"red", "lime", "blue", "yellow", "cyan", "magenta"} {
+		if err := mw.ReadImage("xc:" + color + "[80x80]"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		opts          MontageOptions
+		width, height uint
+	}{
+		{MontageOptions{Columns: 3, Rows: 2, ThumbWidth: 40, ThumbHeight: 40, Mode: MONTAGE_MODE_CONCATENATE}, 120, 80},
+		{MontageOptions{Columns: 3, ThumbWidth: 40, ThumbHeight: 40, Spacing: 2, Mode: MONTAGE_MODE_UNFRAME}, 132, 88},
+		{MontageOptions{Rows: 1, ThumbWidth: 20, ThumbHeight: 20, Mode: MONTAGE_MODE_CONCATENATE}, 120, 20},
+	}
+	for _, tt := range tests {
+		montage, err := mw.MontageImages(nil, tt.opts)
+		if err != nil {
+			t.Fatalf("%+v: %s", tt.opts, err)
+		}
+		if w, h := montage.GetImageWidth(), montage.GetImageHeight(); w != tt.width || h != tt.height {
+			t.Errorf("%+v: expected %dx%d, got %dx%d", tt.opts, tt.width, tt.height, w, h)
+		}
+		montage.Destroy()
+	}
+
+	montage, err := mw.MontageImages(nil, MontageOptions{Columns: 3, Rows: 2, ThumbWidth: 40, ThumbHeight: 40, Mode: MONTAGE_MODE_CONCATENATE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer montage.Destroy()
+	expectPixelColor(t, montage, 20, 20, "red")
+	expectPixelColor(t, montage, 100, 60, "magenta")
+
+	if _, err := mw.MontageImages(nil, MontageOptions{Columns: 2, Rows: 2}); err == nil {
+		t.Fatal("Expected an error for a grid smaller than the number of images")
+	}
+}
+
+func TestMontageImagesLabel(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for i := 0; i < 2; i++ {
+		if err := mw.ReadImage("xc:red[40x40]"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	dw.SetFontSize(12)
+
+	montage, err := mw.MontageImages(dw, MontageOptions{Columns: 2, ThumbWidth: 40, ThumbHeight: 40, Label: "%wx%h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer montage.Destroy()
+	if h := montage.GetImageHeight(); h <= 40 {
+		t.Fatalf("Expected the labels to add to the height of 40, got %d", h)
+	}
+	if label, _ := mw.GetImageProperty("label"); label != "" {
+		t.Fatalf("Expected the images of the wand to be left unlabeled, got %q", label)
+	}
+}
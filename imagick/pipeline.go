@@ -0,0 +1,128 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pipeline is a list of operations on the current image of a wand which are
+// run in order by Run, replacing a chain of calls each followed by an error
+// check:
+//
+//	err := NewPipeline(mw).AutoOrient().Resize("800x600>").Sharpen(0, 1).
+//		Quality(82).Format("JPEG").Strip().Run(ctx)
+//
+// The steps are only recorded until Run is called. A Pipeline may be run
+// again, on the same wand.
+type Pipeline struct {
+	mw      *MagickWand
+	steps   []pipelineStep
+	timings []StepTiming
+}
+
+type pipelineStep struct {
+	name string
+	fn   func(mw *MagickWand) error
+}
+
+// StepTiming is the time a pipeline step took, see Pipeline.Timings
+type StepTiming struct {
+	Step     string
+	Duration time.Duration
+}
+
+// Returns an empty pipeline operating on mw
+func NewPipeline(mw *MagickWand) *Pipeline {
+	return &Pipeline{mw: mw}
+}
+
+// Adds a step running fn, named name in errors and timings
+func (p *Pipeline) Step(name string, fn func(mw *MagickWand) error) *Pipeline {
+	p.steps = append(p.steps, pipelineStep{name, fn})
+	return p
+}
+
+// Adds a step rotating the image upright, see AutoOrientImage
+func (p *Pipeline) AutoOrient() *Pipeline {
+	return p.Step("AutoOrient", (*MagickWand).AutoOrientImage)
+}
+
+// Adds a step resizing the image to a geometry such as "800x600>" with a
+// Lanczos filter, see ResizeImageGeometry
+func (p *Pipeline) Resize(geometry string) *Pipeline {
+	return p.Step(fmt.Sprintf("Resize(%s)", geometry), func(mw *MagickWand) error {
+		return mw.ResizeImageGeometry(geometry, FILTER_LANCZOS, 1)
+	})
+}
+
+// Adds a step sharpening the image, see SharpenImage
+func (p *Pipeline) Sharpen(radius, sigma float64) *Pipeline {
+	return p.Step(fmt.Sprintf("Sharpen(%g, %g)", radius, sigma), func(mw *MagickWand) error {
+		return mw.SharpenImage(radius, sigma)
+	})
+}
+
+// Adds a step setting the compression quality, see
+// SetImageCompressionQuality
+func (p *Pipeline) Quality(quality uint) *Pipeline {
+	return p.Step(fmt.Sprintf("Quality(%d)", quality), func(mw *MagickWand) error {
+		return mw.SetImageCompressionQuality(quality)
+	})
+}
+
+// Adds a step setting the output format, see SetImageFormat
+func (p *Pipeline) Format(format string) *Pipeline {
+	return p.Step(fmt.Sprintf("Format(%s)", format), func(mw *MagickWand) error {
+		return mw.SetImageFormat(format)
+	})
+}
+
+// Adds a step removing profiles and comments, see StripImage
+func (p *Pipeline) Strip() *Pipeline {
+	return p.Step("Strip", (*MagickWand).StripImage)
+}
+
+// Runs the steps in order and stops at the first failure, returning an
+// error naming the failed step and wrapping its error. Once ctx is done no
+// further step is started, and long running steps are interrupted through
+// the wand's progress monitor, as with SetContext. A progress monitor the
+// wand already had keeps being called and is restored afterwards.
+func (p *Pipeline) Run(ctx context.Context) error {
+	p.timings = p.timings[:0]
+
+	previous := p.mw.getProgressMonitor()
+	p.mw.SetProgressMonitor(func(operation string, offset, span int64) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		return previous == nil || previous(operation, offset, span)
+	})
+	defer p.mw.SetProgressMonitor(previous)
+
+	for i, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("pipeline step %d %s: %w", i+1, step.name, err)
+		}
+		start := time.Now()
+		err := step.fn(p.mw)
+		p.timings = append(p.timings, StepTiming{step.name, time.Since(start)})
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = fmt.Errorf("%s: %w", err, ctxErr)
+			}
+			return fmt.Errorf("pipeline step %d %s: %w", i+1, step.name, err)
+		}
+	}
+	return nil
+}
+
+// Returns how long each step of the last Run took, up to and including a
+// failed step.
+func (p *Pipeline) Timings() []StepTiming {
+	return append([]StepTiming(nil), p.timings...)
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(mw).AutoOrient().Resize("320x240>").Sharpen(0, 1).Quality(82).Format("JPEG").Strip()
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 320 || h != 240 {
+		t.Errorf("Expected 320x240, got %dx%d", w, h)
+	}
+	if q := mw.GetImageCompressionQuality(); q != 82 {
+		t.Errorf("Expected quality 82, got %d", q)
+	}
+	if f := mw.GetImageFormat(); f != "JPEG" {
+		t.Errorf("Expected format JPEG, got %s", f)
+	}
+
+	timings := p.Timings()
+	want := []string{"AutoOrient", "Resize(320x240>)", "Sharpen(0, 1)", "Quality(82)", "Format(JPEG)", "Strip"}
+	if len(timings) != len(want) {
+		t.Fatalf("Expected %d timings, got %d", len(want), len(timings))
+	}
+	for i, timing := range timings {
+		if timing.Step != want[i] || timing.Duration < 0 {
+			t.Errorf("Timing %d is %+v, expected step %s", i, timing, want[i])
+		}
+	}
+}
+
+func TestPipelineFailure(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+
+	errStep := errors.New("step failed")
+	ran := false
+	p := NewPipeline(mw).Strip().
+		Step("Custom", func(*MagickWand) error { return errStep }).
+		Step("After", func(*MagickWand) error { ran = true; return nil })
+	err := p.Run(context.Background())
+	if !errors.Is(err, errStep) || !strings.Contains(err.Error(), "step 2 Custom") {
+		t.Fatalf("Expected an error naming step 2 Custom, got %v", err)
+	}
+	if ran {
+		t.Error("Expected the pipeline to stop at the failed step")
+	}
+	if timings := p.Timings(); len(timings) != 2 || timings[1].Step != "Custom" {
+		t.Errorf("Expected timings up to the failed step, got %+v", timings)
+	}
+
+	err = NewPipeline(mw).Resize("bad geometry").Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Resize(bad geometry)") {
+		t.Errorf("Expected an error naming the Resize step, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewPipeline(mw).Strip().Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// The wand's own monitor is restored after Run
+	mw.SetProgressMonitor(func(string, int64, int64) bool { return true })
+	if err := NewPipeline(mw).Resize("50%").Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if mw.getProgressMonitor() == nil {
+		t.Error("Expected the wand's progress monitor to be restored")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Returns the intensity of a region of the current image as 16-bit values,
+// without the loss of precision of 8-bit exports. Values above 8 bits are
+// only meaningful if ImageMagick has a quantum depth of 16 or more, see
+// GetQuantumDepth.
+func (mw *MagickWand) ExportGray16(x, y int, cols, rows uint) ([]uint16, error) {
+	if cols == 0 || rows == 0 {
+		return nil, errors.New("ExportGray16: cols and rows must be above 0")
+	}
+	pixels := make([]uint16, int(cols)*int(rows))
+	if err := mw.exportPixels(x, y, cols, rows, "I", PIXEL_SHORT, unsafe.Pointer(&pixels[0])); err != nil {
+		return nil, err
+	}
+	return pixels, nil
+}
+
+// Stores 16-bit intensity values, as returned by ExportGray16, in a region
+// of the current image.
+func (mw *MagickWand) ImportGray16(x, y int, cols, rows uint, pixels []uint16) error {
+	return mw.ImportImagePixels(x, y, cols, rows, "I", PIXEL_SHORT, pixels)
+}
+
+// Returns a region of the current image as float32 values, normalized so
+// that QuantumRange is 1. If ImageMagick is built with HDRI, values outside
+// 0 to 1 are kept rather than clamped. ImportImagePixelsFromFloat32 stores
+// them back.
+func (mw *MagickWand) ExportFloat(x, y int, cols, rows uint, pmap string) ([]float32, error) {
+	if cols == 0 || rows == 0 || len(pmap) == 0 {
+		return nil, errors.New("ExportFloat: cols, rows and pmap must not be empty")
+	}
+	pixels := make([]float32, int(cols)*int(rows)*len(pmap))
+	if err := mw.exportPixels(x, y, cols, rows, pmap, PIXEL_FLOAT, unsafe.Pointer(&pixels[0])); err != nil {
+		return nil, err
+	}
+	return pixels, nil
+}
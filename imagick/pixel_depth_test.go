@@ -0,0 +1,129 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGray16PNGRoundTrip(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	if _, depth := GetQuantumDepth(); depth < 16 {
+		t.Skipf("ImageMagick quantum depth is %d", depth)
+	}
+	if !HasDelegate("png") {
+		t.Skip("ImageMagick has no PNG support")
+	}
+
+	const width, height = 256, 4
+	gradient := make([]uint16, width*height)
+	for i := range gradient {
+		// Steps that are not multiples of 257 do not fit in 8 bits
+		gradient[i] = uint16(i * 63)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(width, height, "I", PIXEL_UNDEFINED, gradient); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageDepth(16); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "imagick_depth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "gradient.png")
+	if err := mw.WriteImage(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	read := NewMagickWand()
+	defer read.Destroy()
+	if err := read.ReadImage(filename); err != nil {
+		t.Fatal(err)
+	}
+	if depth := read.GetImageDepth(); depth != 16 {
+		t.Fatalf("Expected a depth of 16, got %d", depth)
+	}
+	got, err := read.ExportGray16(0, 0, width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range gradient {
+		if got[i] != gradient[i] {
+			t.Fatalf("Value %d: got %d, want %d", i, got[i], gradient[i])
+		}
+	}
+
+	if err := read.ImportGray16(0, 0, width, height, make([]uint16, width*height)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = read.ExportGray16(width-1, height-1, 1, 1); err != nil || got[0] != 0 {
+		t.Fatalf("Expected 0 after ImportGray16, got %v, %v", got, err)
+	}
+}
+
+func TestFloatTIFFRoundTrip(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	if !HasFeature("HDRI") || !HasDelegate("tiff") {
+		t.Skip("ImageMagick has no HDRI or TIFF support")
+	}
+
+	values := []float32{0, 0.25, 1, 1.5, 2.5, 10, -0.5, 0.125, 4}
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ConstituteImage(3, 1, "RGB", PIXEL_FLOAT, values); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetOption("quantum:format", "floating-point"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageDepth(32); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "imagick_depth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "float.tif")
+	if err := mw.WriteImage(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	read := NewMagickWand()
+	defer read.Destroy()
+	if err := read.ReadImage(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, err := read.ExportFloat(0, 0, 3, 1, "RGB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range values {
+		if math.Abs(float64(got[i]-want)) > 1e-4 {
+			t.Errorf("Value %d: got %v, want %v", i, got[i], want)
+		}
+	}
+}
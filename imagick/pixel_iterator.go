@@ -13,6 +13,7 @@ static PixelWand* get_pw_at(PixelWand** pws, size_t pos) {
 */
 import "C"
 import (
+	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -37,20 +38,37 @@ func newPixelIterator(cpi *C.PixelIterator) *PixelIterator {
 // mw: the magick wand to iterate on
 //
 func (mw *MagickWand) NewPixelIterator() *PixelIterator {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 	ret := newPixelIterator(C.NewPixelIterator(mw.mw))
 	runtime.KeepAlive(mw)
 	return ret
 }
 
-// Returns a new pixel iterator
+// Returns a new pixel iterator over a region of the image. An error is
+// returned if the wand has no image or the region is invalid.
 //
 // mw: the magick wand to iterate on
 // x, y, cols, rows: there values define the perimeter of a region of pixels
 //
-func (mw *MagickWand) NewPixelRegionIterator(x, y int, width, height uint) *PixelIterator {
-	ret := newPixelIterator(C.NewPixelRegionIterator(mw.mw, C.ssize_t(x), C.ssize_t(y), C.size_t(width), C.size_t(height)))
+func (mw *MagickWand) NewPixelRegionIterator(x, y int, width, height uint) (*PixelIterator, error) {
+	if err := mw.valid(); err != nil {
+		return nil, err
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	cpi := C.NewPixelRegionIterator(mw.mw, C.ssize_t(x), C.ssize_t(y), C.size_t(width), C.size_t(height))
 	runtime.KeepAlive(mw)
-	return ret
+	if cpi == nil {
+		if err := mw.lastError(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid pixel region %dx%d%+d%+d", width, height, x, y)
+	}
+	return newPixelIterator(cpi), nil
 }
 
 // Clear resources associated with a PixelIterator.
@@ -82,6 +100,13 @@ func (pi *PixelIterator) Destroy() {
 	})
 }
 
+// Destroys the PixelIterator, see Destroy(). It implements io.Closer and may be
+// called more than once.
+func (pi *PixelIterator) Close() error {
+	pi.Destroy()
+	return nil
+}
+
 // Returns true if the iterator is verified as a pixel iterator.
 func (pi *PixelIterator) IsVerified() bool {
 	if pi.pi == nil {
@@ -95,12 +120,14 @@ func (pi *PixelIterator) IsVerified() bool {
 // Increase PixelIterator ref counter and set according "can`t be terminated status"
 func (pi *PixelIterator) IncreaseCount() {
 	atomic.AddInt64(&pixelIteratorCounter, int64(1))
+	trackObject("PixelIterator", unsafe.Pointer(pi))
 	unsetCanTerminate()
 }
 
 // Decrease DrawingWand ref counter and set according "can be terminated status"
 func (pi *PixelIterator) DecreaseCount() {
 	atomic.AddInt64(&pixelIteratorCounter, int64(-1))
+	untrackObject(unsafe.Pointer(pi))
 	setCanTerminate()
 }
 
@@ -47,7 +47,10 @@ func (pi *PixelIterator) GetLastError() error {
 
 func (pi *PixelIterator) getLastErrorIfFailed(ok C.MagickBooleanType) error {
 	if C.int(ok) == 0 {
-		return pi.GetLastError()
+		if err := pi.GetLastError(); err != nil {
+			return err
+		}
+		return &PixelIteratorException{ERROR_WAND, callerName(1) + ": operation failed"}
 	} else {
 		return nil
 	}
@@ -72,3 +72,84 @@ func TestPixelIteratorPrivatePixelWands(t *testing.T) {
 	runtime.GC()
 	time.Sleep(100 * time.Millisecond)
 }
+
+func TestPixelRegionIterator(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+		Terminate()
+	}(t)
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := mw.NewPixelRegionIterator(0, 0, 0, 0); err == nil {
+		t.Fatal("Expected an error for an empty region")
+	}
+
+	const x0, y0, size = 20, 30, 10
+	before, err := mw.GetImagePixelColor(x0-1, y0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer before.Destroy()
+
+	pi, err := mw.NewPixelRegionIterator(x0, y0, size, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pi.Destroy()
+
+	rows := 0
+	for row := pi.GetNextIteratorRow(); len(row) > 0; row = pi.GetNextIteratorRow() {
+		if len(row) != size {
+			t.Fatalf("Expected rows of %d pixels, got %d", size, len(row))
+		}
+		for _, pw := range row {
+			pw.SetColor("red")
+		}
+		if err := pi.SyncIterator(); err != nil {
+			t.Fatal(err)
+		}
+		rows++
+	}
+	if rows != size {
+		t.Fatalf("Expected %d rows, got %d", size, rows)
+	}
+
+	red := NewPixelWand()
+	defer red.Destroy()
+	red.SetColor("red")
+
+	for _, p := range []struct {
+		x, y   int
+		inside bool
+	}{
+		{x0, y0, true},
+		{x0 + size - 1, y0 + size - 1, true},
+		{x0 - 1, y0, false},
+		{x0 + size, y0 + size, false},
+	} {
+		color, err := mw.GetImagePixelColor(p.x, p.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if color.IsSimilar(red, 0) != p.inside {
+			t.Errorf("Pixel (%d,%d): expected red=%v, got %s", p.x, p.y, p.inside, color.GetColorAsString())
+		}
+		color.Destroy()
+	}
+
+	after, err := mw.GetImagePixelColor(x0-1, y0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after.Destroy()
+	if !after.IsSimilar(before, 0) {
+		t.Fatal("Pixel outside the region changed")
+	}
+}
@@ -30,9 +30,23 @@ func newPixelWand(cpw *C.PixelWand) *PixelWand {
 	return pw
 }
 
-// Returns a new pixel wand
+// Returns a new pixel wand. Panics if the environment is not initialized,
+// see NewPixelWandChecked().
 func NewPixelWand() *PixelWand {
-	return newPixelWand(C.NewPixelWand())
+	pw, err := NewPixelWandChecked()
+	if err != nil {
+		panic("imagick: " + err.Error())
+	}
+	return pw
+}
+
+// Like NewPixelWand, but returns an error wrapping ErrNotInitialized if
+// Initialize was not called or the environment was terminated.
+func NewPixelWandChecked() (*PixelWand, error) {
+	if err := checkInitialized("NewPixelWand"); err != nil {
+		return nil, err
+	}
+	return newPixelWand(C.NewPixelWand()), nil
 }
 
 // Clears resources associated with the wand
@@ -64,7 +78,15 @@ func (pw *PixelWand) Destroy() {
 	})
 }
 
-// Returns true if the distance between two colors is less than the specified distance
+// Destroys the PixelWand, see Destroy(). It implements io.Closer and may be
+// called more than once.
+func (pw *PixelWand) Close() error {
+	pw.Destroy()
+	return nil
+}
+
+// Returns true if the distance between two colors is less than the specified
+// distance. The fuzz distance is in quantum units, see IsSimilarNormalized.
 func (pw *PixelWand) IsSimilar(pixelWand *PixelWand, fuzz float64) bool {
 	ret := 1 == C.int(C.IsPixelWandSimilar(pw.pw, pixelWand.pw, C.double(fuzz)))
 	runtime.KeepAlive(pw)
@@ -72,6 +94,13 @@ func (pw *PixelWand) IsSimilar(pixelWand *PixelWand, fuzz float64) bool {
 	return ret
 }
 
+// Same as IsSimilar, but the fuzz distance is normalized to the range 0 to 1
+// and scaled by the quantum range.
+func (pw *PixelWand) IsSimilarNormalized(pixelWand *PixelWand, fuzz float64) bool {
+	_, qrange := GetQuantumRange()
+	return pw.IsSimilar(pixelWand, fuzz*float64(qrange))
+}
+
 // Returns true if the wand is verified as a pixel wand
 func (pw *PixelWand) IsVerified() bool {
 	if pw.pw != nil {
@@ -84,12 +113,14 @@ func (pw *PixelWand) IsVerified() bool {
 // Increase PixelWand ref counter and set according "can`t be terminated status"
 func (pw *PixelWand) IncreaseCount() {
 	atomic.AddInt64(&pixelWandCounter, int64(1))
+	trackObject("PixelWand", unsafe.Pointer(pw))
 	unsetCanTerminate()
 }
 
 // Decrease PixelWand ref counter and set according "can be terminated status"
 func (pw *PixelWand) DecreaseCount() {
 	atomic.AddInt64(&pixelWandCounter, int64(-1))
+	untrackObject(unsafe.Pointer(pw))
 	setCanTerminate()
 }
 
@@ -193,12 +224,13 @@ func (pw *PixelWand) GetGreenQuantum() Quantum {
 	return ret
 }
 
-// Returns the normalized HSL color of the pixel wand
-func (pw *PixelWand) GetHSL() (hue, saturation, brightness float64) {
-	var cdhue, cdsaturation, cdbrightness C.double
-	C.PixelGetHSL(pw.pw, &cdhue, &cdsaturation, &cdbrightness)
+// Returns the normalized HSL color of the pixel wand. All three components,
+// including hue, are in the range 0 to 1.
+func (pw *PixelWand) GetHSL() (hue, saturation, lightness float64) {
+	var cdhue, cdsaturation, cdlightness C.double
+	C.PixelGetHSL(pw.pw, &cdhue, &cdsaturation, &cdlightness)
 	runtime.KeepAlive(pw)
-	hue, saturation, brightness = float64(cdhue), float64(cdsaturation), float64(cdbrightness)
+	hue, saturation, lightness = float64(cdhue), float64(cdsaturation), float64(cdlightness)
 	return
 }
 
@@ -365,9 +397,10 @@ func (pw *PixelWand) SetGreenQuantum(green Quantum) {
 	runtime.KeepAlive(pw)
 }
 
-// Sets the normalized HSL color of the pixel wand
-func (pw *PixelWand) SetHSL(hue, saturation, brightness float64) {
-	C.PixelSetHSL(pw.pw, C.double(hue), C.double(saturation), C.double(brightness))
+// Sets the normalized HSL color of the pixel wand. Hue is a fraction of a
+// full turn, so hsl(120,100%,50%) is SetHSL(120.0/360, 1, 0.5).
+func (pw *PixelWand) SetHSL(hue, saturation, lightness float64) {
+	C.PixelSetHSL(pw.pw, C.double(hue), C.double(saturation), C.double(lightness))
 	runtime.KeepAlive(pw)
 }
 
@@ -0,0 +1,125 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPixelWandHSL(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	pw := NewPixelWand()
+	defer pw.Destroy()
+	green := NewPixelWand()
+	defer green.Destroy()
+
+	pw.SetHSL(120.0/360, 1, 0.5)
+	if !green.SetColor("#00FF00") {
+		t.Fatal("Could not set color #00FF00")
+	}
+
+	if got, want := pw.GetColorAsString(), green.GetColorAsString(); got != want {
+		t.Fatalf("Expected hsl(120,100%%,50%%) to be %q, got %q", want, got)
+	}
+	if got, want := pw.GetColorAsNormalizedString(), green.GetColorAsNormalizedString(); got != want {
+		t.Fatalf("Expected normalized color %q, got %q", want, got)
+	}
+	if pw.GetRed() != 0 || pw.GetGreen() != 1 || pw.GetBlue() != 0 {
+		t.Fatalf("Expected pure green, got %v,%v,%v", pw.GetRed(), pw.GetGreen(), pw.GetBlue())
+	}
+
+	h, s, l := pw.GetHSL()
+	if math.Abs(h-1.0/3) > 1e-6 || math.Abs(s-1) > 1e-6 || math.Abs(l-0.5) > 1e-6 {
+		t.Fatalf("Expected HSL 0.333,1,0.5, got %v,%v,%v", h, s, l)
+	}
+}
+
+func TestPixelWandQuantum(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	pw := NewPixelWand()
+	defer pw.Destroy()
+
+	_, depth := GetQuantumDepth()
+	max := Quantum(uint64(1)<<depth - 1)
+	mid := Quantum(uint64(1) << (depth - 1))
+
+	channels := []struct {
+		name string
+		set  func(Quantum)
+		get  func() Quantum
+	}{
+		{"red", pw.SetRedQuantum, pw.GetRedQuantum},
+		{"green", pw.SetGreenQuantum, pw.GetGreenQuantum},
+		{"blue", pw.SetBlueQuantum, pw.GetBlueQuantum},
+		{"alpha", pw.SetAlphaQuantum, pw.GetAlphaQuantum},
+		{"black", pw.SetBlackQuantum, pw.GetBlackQuantum},
+	}
+	for _, c := range channels {
+		for _, q := range []Quantum{0, 1, mid, max} {
+			c.set(q)
+			if got := c.get(); got != q {
+				t.Errorf("%s: expected quantum %v at depth %d, got %v", c.name, q, depth, got)
+			}
+		}
+	}
+
+	pw.SetRedQuantum(max)
+	if r := pw.GetRed(); r != 1 {
+		t.Fatalf("Expected normalized red 1 for quantum %v, got %v", max, r)
+	}
+}
+
+func TestPixelWandIsSimilar(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	red := NewPixelWand()
+	defer red.Destroy()
+	nearRed := NewPixelWand()
+	defer nearRed.Destroy()
+	same := NewPixelWand()
+	defer same.Destroy()
+
+	red.SetColor("rgb(255,0,0)")
+	nearRed.SetColor("rgb(250,0,0)")
+	same.SetColor("red")
+
+	if !red.IsSimilar(same, 0) {
+		t.Fatal("Expected identical colors to be similar with zero fuzz")
+	}
+	if red.IsSimilar(nearRed, 0) {
+		t.Fatal("Expected different colors not to be similar with zero fuzz")
+	}
+	if red.IsSimilarNormalized(nearRed, 0.001) {
+		t.Fatal("Expected nearby reds not to be similar with a small fuzz")
+	}
+	if !red.IsSimilarNormalized(nearRed, 0.1) {
+		t.Fatal("Expected nearby reds to be similar with a large fuzz")
+	}
+
+	_, qrange := GetQuantumRange()
+	if !red.IsSimilar(nearRed, 0.1*float64(qrange)) {
+		t.Fatal("Expected IsSimilar with a quantum fuzz to match IsSimilarNormalized")
+	}
+
+	red.SetFuzz(42)
+	if f := red.GetFuzz(); f != 42 {
+		t.Fatalf("Expected fuzz 42, got %v", f)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include <stdint.h>
+#include <wand/MagickWand.h>
+#include "_cgo_export.h"
+
+static MagickBooleanType progressMonitorTrampoline(const char *text,
+	const MagickOffsetType offset, const MagickSizeType span, void *client_data)
+{
+	return goProgressMonitor((char *) text, offset, span, (uintptr_t) client_data);
+}
+
+// Installs the Go progress monitor with the given registry id on the wand
+// and on every image it holds. An id of 0 removes the monitor.
+void setWandProgressMonitor(MagickWand *wand, uintptr_t id)
+{
+	MagickProgressMonitor monitor = id == 0 ? NULL : progressMonitorTrampoline;
+	ssize_t index;
+
+	MagickSetProgressMonitor(wand, monitor, (void *) id);
+	if (MagickGetNumberImages(wand) == 0)
+		return;
+	index = MagickGetIteratorIndex(wand);
+	MagickResetIterator(wand);
+	while (MagickNextImage(wand) != MagickFalse)
+		MagickSetImageProgressMonitor(wand, monitor, (void *) id);
+	MagickSetIteratorIndex(wand, index);
+}
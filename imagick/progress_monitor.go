@@ -0,0 +1,107 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+/*
+#include <stdint.h>
+#include <wand/MagickWand.h>
+
+void setWandProgressMonitor(MagickWand *wand, uintptr_t id);
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ProgressMonitor is called periodically by long running operations.
+// operation describes the running task (e.g. "Resize/Image"), offset is the
+// amount of work done so far out of span. Returning false cancels the
+// operation, which then returns an error.
+//
+// The monitor may be called concurrently from several ImageMagick threads.
+// It runs while the monitored wand is locked by the operation, so it must
+// not call methods of that wand: they would wait for the operation to end
+// and deadlock. Other wands may be used.
+type ProgressMonitor func(operation string, offset, span int64) bool
+
+var (
+	progressMonitorsMu sync.RWMutex
+	progressMonitors   = map[uintptr]ProgressMonitor{}
+	progressMonitorID  uintptr
+)
+
+//export goProgressMonitor
+func goProgressMonitor(text *C.char, offset C.MagickOffsetType, span C.MagickSizeType, id C.uintptr_t) C.MagickBooleanType {
+	progressMonitorsMu.RLock()
+	fn := progressMonitors[uintptr(id)]
+	progressMonitorsMu.RUnlock()
+
+	if fn == nil || fn(C.GoString(text), int64(offset), int64(span)) {
+		return C.MagickTrue
+	}
+	return C.MagickFalse
+}
+
+// Installs a progress monitor on the wand and on the images it holds or
+// will read. Passing nil removes the monitor. The monitor is released when
+// the wand is destroyed. Clones of the wand share the monitor until the
+// original wand is destroyed or the clone sets its own.
+func (mw *MagickWand) SetProgressMonitor(fn ProgressMonitor) {
+	if mw.valid() != nil {
+		return
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.releaseProgressMonitor()
+
+	var id uintptr
+	if fn != nil {
+		progressMonitorsMu.Lock()
+		progressMonitorID++
+		id = progressMonitorID
+		progressMonitors[id] = fn
+		progressMonitorsMu.Unlock()
+	}
+	mw.progressMonitor = id
+
+	C.setWandProgressMonitor(mw.mw, C.uintptr_t(id))
+	runtime.KeepAlive(mw)
+}
+
+// Installs a progress monitor which cancels long running operations once
+// ctx is done. The interrupted operation returns an error. It replaces any
+// ProgressMonitor of the wand, and like one, code run when ctx is done must
+// not wait for methods of the wand while an operation is running.
+func (mw *MagickWand) SetContext(ctx context.Context) {
+	mw.SetProgressMonitor(func(string, int64, int64) bool {
+		return ctx.Err() == nil
+	})
+}
+
+// Returns the wand's progress monitor, nil if it has none
+func (mw *MagickWand) getProgressMonitor() ProgressMonitor {
+	if mw.valid() != nil {
+		return nil
+	}
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	progressMonitorsMu.RLock()
+	defer progressMonitorsMu.RUnlock()
+	return progressMonitors[mw.progressMonitor]
+}
+
+// Removes the wand's progress monitor from the registry
+func (mw *MagickWand) releaseProgressMonitor() {
+	if mw.progressMonitor == 0 {
+		return
+	}
+	progressMonitorsMu.Lock()
+	delete(progressMonitors, mw.progressMonitor)
+	progressMonitorsMu.Unlock()
+	mw.progressMonitor = 0
+}
@@ -0,0 +1,124 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProgressMonitor(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int64
+	mw.SetProgressMonitor(func(operation string, offset, span int64) bool {
+		atomic.AddInt64(&calls, 1)
+		return true
+	})
+	if err := mw.ResizeImage(2000, 1500, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&calls) == 0 {
+		t.Fatal("Expected the progress monitor to be called during ResizeImage")
+	}
+
+	mw.SetProgressMonitor(nil)
+	if len(progressMonitors) != 0 {
+		t.Fatal("Progress monitor was not released")
+	}
+}
+
+func TestProgressMonitorContext(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	mw.SetContext(ctx)
+	start := time.Now()
+	if err := mw.ResizeImage(8000, 6000, FILTER_LANCZOS, 1); err == nil {
+		t.Fatal("Expected ResizeImage to fail once the context is done")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected a cancelled ResizeImage to return promptly, took %s", elapsed)
+	}
+
+	mw.Destroy()
+	if len(progressMonitors) != 0 {
+		t.Fatal("Progress monitor was not released when the wand was destroyed")
+	}
+}
+
+func TestProgressMonitorLocksWand(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	if err := mw.ReadImage(`logo:`); err != nil {
+		t.Fatal(err)
+	}
+
+	// A method of the monitored wand called from the monitor would deadlock,
+	// call it from another goroutine and check that it waits for the
+	// operation instead
+	done := make(chan struct{})
+	var started, returned int32
+	mw.SetProgressMonitor(func(operation string, offset, span int64) bool {
+		if atomic.CompareAndSwapInt32(&started, 0, 1) {
+			go func() {
+				mw.GetImageWidth()
+				close(done)
+			}()
+			select {
+			case <-done:
+				atomic.StoreInt32(&returned, 1)
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+		return true
+	})
+	if err := mw.ResizeImage(2000, 1500, FILTER_LANCZOS, 1); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&started) == 0 {
+		t.Fatal("Expected the progress monitor to be called during ResizeImage")
+	}
+	if atomic.LoadInt32(&returned) != 0 {
+		t.Fatal("Expected the wand to be locked while the progress monitor runs")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the wand to be unlocked after ResizeImage")
+	}
+}
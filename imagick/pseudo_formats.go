@@ -0,0 +1,111 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Colors as PixelWand.GetColorAsString formats them, e.g. "srgb(255,0,0)",
+// or color names, the only colors put into pseudo image filenames
+var pseudoColorRe = regexp.MustCompile(`^(?:[a-z]+\([0-9.,%]+\)|[A-Za-z][A-Za-z0-9]*|#[0-9A-Fa-f]+)$`)
+
+// Colors of a plasma seed, see NewPlasmaWand
+var plasmaSeedRe = regexp.MustCompile(`^[A-Za-z0-9#]+(?:-[A-Za-z0-9#]+)?$`)
+
+// Returns a new wand holding a width x height image filled with color, as
+// NewImage.
+func NewCanvasWand(width, height uint, color *PixelWand) (*MagickWand, error) {
+	if color == nil {
+		return nil, errors.New("NewCanvasWand: color must not be nil")
+	}
+	return newPseudoWand(width, height, func(mw *MagickWand) error {
+		return mw.NewImage(width, height, color)
+	})
+}
+
+// Returns a new wand holding a width x height gradient from the color from
+// at the top to the color to at the bottom, or if radial is true, from the
+// center to the corners.
+func NewGradientWand(width, height uint, from, to *PixelWand, radial bool) (*MagickWand, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("NewGradientWand: colors must not be nil")
+	}
+	fromColor, err := pseudoColor(from)
+	if err != nil {
+		return nil, fmt.Errorf("NewGradientWand: %s", err)
+	}
+	toColor, err := pseudoColor(to)
+	if err != nil {
+		return nil, fmt.Errorf("NewGradientWand: %s", err)
+	}
+	format := "gradient"
+	if radial {
+		format = "radial-gradient"
+	}
+	return newPseudoWand(width, height, func(mw *MagickWand) error {
+		return mw.ReadImage(format + ":" + fromColor + "-" + toColor)
+	})
+}
+
+// Returns a new wand holding a width x height plasma fractal. seed selects
+// the colors the plasma starts from, "fractal" or empty for random colors,
+// a color such as "red", or two colors such as "red-yellow".
+func NewPlasmaWand(width, height uint, seed string) (*MagickWand, error) {
+	if seed == "" {
+		seed = "fractal"
+	}
+	if !plasmaSeedRe.MatchString(seed) {
+		return nil, fmt.Errorf("NewPlasmaWand: invalid seed %q", seed)
+	}
+	return newPseudoWand(width, height, func(mw *MagickWand) error {
+		return mw.ReadImage("plasma:" + seed)
+	})
+}
+
+// Returns a new wand holding a width x height mid gray image with noise
+// added, see AddNoiseImage.
+func NewNoiseWand(width, height uint, noise NoiseType) (*MagickWand, error) {
+	if noise <= NOISE_UNDEFINED || noise > NOISE_RANDOM {
+		return nil, fmt.Errorf("NewNoiseWand: invalid noise type %d", noise)
+	}
+	return newPseudoWand(width, height, func(mw *MagickWand) error {
+		gray := NewPixelWand()
+		defer gray.Destroy()
+		gray.SetColor("gray50")
+		if err := mw.NewImage(width, height, gray); err != nil {
+			return err
+		}
+		return mw.AddNoiseImage(noise)
+	})
+}
+
+// Returns a new wand of size width x height, see SetSize, filled by read
+func newPseudoWand(width, height uint, read func(mw *MagickWand) error) (*MagickWand, error) {
+	if width == 0 || height == 0 {
+		return nil, errors.New("width and height must not be 0")
+	}
+	mw := NewMagickWand()
+	if err := mw.SetSize(width, height); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	if err := read(mw); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Returns the color of pw for a pseudo image filename
+func pseudoColor(pw *PixelWand) (string, error) {
+	color := pw.GetColorAsString()
+	if !pseudoColorRe.MatchString(color) {
+		return "", fmt.Errorf("unexpected color %q", color)
+	}
+	return color, nil
+}
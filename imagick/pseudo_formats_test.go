@@ -0,0 +1,61 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestPseudoFormatWands(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	red, blue := NewPixelWand(), NewPixelWand()
+	defer red.Destroy()
+	defer blue.Destroy()
+	red.SetColor("red")
+	blue.SetColor("blue")
+
+	constructors := map[string]func() (*MagickWand, error){
+		"canvas":          func() (*MagickWand, error) { return NewCanvasWand(40, 30, red) },
+		"gradient":        func() (*MagickWand, error) { return NewGradientWand(40, 30, red, blue, false) },
+		"radial-gradient": func() (*MagickWand, error) { return NewGradientWand(40, 30, red, blue, true) },
+		"plasma":          func() (*MagickWand, error) { return NewPlasmaWand(40, 30, "") },
+		"plasma colors":   func() (*MagickWand, error) { return NewPlasmaWand(40, 30, "red-yellow") },
+		"noise":           func() (*MagickWand, error) { return NewNoiseWand(40, 30, NOISE_GAUSSIAN) },
+	}
+	for name, constructor := range constructors {
+		mw, err := constructor()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 40 || h != 30 {
+			t.Errorf("%s: expected 40x30, got %dx%d", name, w, h)
+		}
+		mw.Destroy()
+	}
+
+	mw, err := NewGradientWand(40, 30, red, blue, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mw.Destroy()
+	expectPixelColor(t, mw, 20, 0, "srgb(255,0,0)")
+	expectPixelColor(t, mw, 20, 29, "srgb(0,0,255)")
+
+	if _, err := NewPlasmaWand(40, 30, "red;rm -rf"); err == nil {
+		t.Error("Expected an error for an invalid plasma seed")
+	}
+	if _, err := NewCanvasWand(0, 30, red); err == nil {
+		t.Error("Expected an error for a width of 0")
+	}
+	if _, err := NewNoiseWand(40, 30, NOISE_UNDEFINED); err == nil {
+		t.Error("Expected an error for an undefined noise type")
+	}
+}
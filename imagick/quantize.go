@@ -0,0 +1,101 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reduces every image of the wand to at most maxColors colors, e.g. for GIF
+// or PNG8 output. The colors are chosen in sRGB, and a sequence shares one
+// set of colors. If palette is not nil its colors are used instead, see
+// NewWebSafePalette and NewGrayscalePalette. Returns an error if an image
+// still has more than maxColors colors afterwards.
+//
+// DITHER_METHOD_FLOYD_STEINBERG or a palette remaps the images with
+// RemapImage, otherwise they are quantized with QuantizeImages, dithering
+// unless dither is DITHER_METHOD_NO.
+func (mw *MagickWand) QuantizeToPalette(maxColors uint, dither DitherMethod, palette *MagickWand) error {
+	if maxColors == 0 {
+		return errors.New("QuantizeToPalette: maxColors must not be 0")
+	}
+	if mw.GetNumberImages() == 0 {
+		return errors.New("QuantizeToPalette: wand has no images")
+	}
+
+	if palette == nil && dither != DITHER_METHOD_FLOYD_STEINBERG {
+		if err := mw.QuantizeImages(maxColors, COLORSPACE_SRGB, 0, dither != DITHER_METHOD_NO, false); err != nil {
+			return err
+		}
+	} else {
+		if palette == nil {
+			var err error
+			if palette, err = mw.sharedPalette(maxColors); err != nil {
+				return err
+			}
+			defer palette.Destroy()
+		}
+		mw.ResetIterator()
+		for mw.NextImage() {
+			if err := mw.RemapImage(palette, dither); err != nil {
+				return err
+			}
+		}
+	}
+
+	mw.ResetIterator()
+	for i := 0; mw.NextImage(); i++ {
+		if colors := mw.GetImageColors(); colors > maxColors {
+			return fmt.Errorf("QuantizeToPalette: image %d has %d colors, expected at most %d", i, colors, maxColors)
+		}
+	}
+	mw.ResetIterator()
+	return nil
+}
+
+// Returns a single image holding the colors of all images of the wand,
+// quantized without dithering to at most maxColors colors.
+func (mw *MagickWand) sharedPalette(maxColors uint) (*MagickWand, error) {
+	mw.ResetIterator()
+	palette, err := mw.AppendImages(false)
+	if err != nil {
+		return nil, err
+	}
+	if err := palette.QuantizeImage(maxColors, COLORSPACE_SRGB, 0, false, false); err != nil {
+		palette.Destroy()
+		return nil, err
+	}
+	return palette, nil
+}
+
+// Returns a palette of the 216 web safe colors, for use with
+// QuantizeToPalette.
+func NewWebSafePalette() (*MagickWand, error) {
+	mw := NewMagickWand()
+	if err := mw.ReadImage("netscape:"); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Returns a palette of levels evenly spaced grays from black to white, for
+// use with QuantizeToPalette. levels must be between 2 and 256.
+func NewGrayscalePalette(levels uint) (*MagickWand, error) {
+	if levels < 2 || levels > 256 {
+		return nil, fmt.Errorf("NewGrayscalePalette: levels must be between 2 and 256, got %d", levels)
+	}
+	grays := make([]byte, levels)
+	for i := range grays {
+		grays[i] = byte((uint(i)*255 + (levels-1)/2) / (levels - 1))
+	}
+	mw := NewMagickWand()
+	if err := mw.ConstituteImage(levels, 1, "I", PIXEL_CHAR, grays); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
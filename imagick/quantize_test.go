@@ -0,0 +1,98 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestQuantizeToPalette(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	for _, dither := range []DitherMethod{DITHER_METHOD_NO, DITHER_METHOD_RIEMERSMA, DITHER_METHOD_FLOYD_STEINBERG} {
+		mw := NewMagickWand()
+		if err := mw.ReadImage("logo:"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.QuantizeToPalette(16, dither, nil); err != nil {
+			t.Fatalf("Dither %d: %s", dither, err)
+		}
+		if colors := mw.GetImageColors(); colors > 16 {
+			t.Errorf("Dither %d: expected at most 16 colors, got %d", dither, colors)
+		}
+		mw.Destroy()
+	}
+}
+
+func TestQuantizeToPaletteSequence(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for _, image := range []string{"logo:", "rose:"} {
+		if err := mw.ReadImage(image); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mw.QuantizeToPalette(8, DITHER_METHOD_FLOYD_STEINBERG, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; mw.SetIteratorIndex(i); i++ {
+		if colors := mw.GetImageColors(); colors > 8 {
+			t.Errorf("Frame %d: expected at most 8 colors, got %d", i, colors)
+		}
+	}
+}
+
+func TestBuiltInPalettes(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	gray, err := NewGrayscalePalette(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gray.Destroy()
+	if colors := gray.GetImageColors(); colors != 4 {
+		t.Fatalf("Expected 4 grays, got %d", colors)
+	}
+
+	websafe, err := NewWebSafePalette()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer websafe.Destroy()
+	if colors := websafe.GetImageColors(); colors != 216 {
+		t.Fatalf("Expected 216 web safe colors, got %d", colors)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.QuantizeToPalette(4, DITHER_METHOD_RIEMERSMA, gray); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.QuantizeToPalette(216, DITHER_METHOD_NO, websafe); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewGrayscalePalette(1); err == nil {
+		t.Fatal("Expected an error for a single gray level")
+	}
+}
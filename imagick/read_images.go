@@ -0,0 +1,88 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IndexedError is the error reading one input of ReadImageFilenames or
+// ReadImageBlobs, Index is the position of the input in the argument.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e IndexedError) Error() string {
+	return fmt.Sprintf("input %d: %s", e.Index, e.Err)
+}
+
+func (e IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// MultiReadError is returned by ReadImageFilenames and ReadImageBlobs when
+// inputs could not be read, see Errors.
+type MultiReadError struct {
+	errs []IndexedError
+}
+
+func (e *MultiReadError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return "reading images failed: " + strings.Join(msgs, "; ")
+}
+
+// Returns the errors of the inputs which could not be read, in input order
+func (e *MultiReadError) Errors() []IndexedError {
+	return append([]IndexedError(nil), e.errs...)
+}
+
+// Reads the images in paths in turn, see ReadImage. The images are added to
+// the wand in the order of paths, a file holding a sequence adds all its
+// frames. Failures are recorded per input and returned as a
+// *MultiReadError. With continueOnError the remaining inputs are still
+// read, otherwise reading stops at the first failure. In both cases the
+// images read so far stay in the wand. Warnings, for which the image is
+// read, are not failures.
+func (mw *MagickWand) ReadImageFilenames(paths []string, continueOnError bool) error {
+	return mw.readImages(len(paths), continueOnError, func(i int) error {
+		return mw.ReadImage(paths[i])
+	})
+}
+
+// Reads the images in blobs in turn, see ReadImageBlob and
+// ReadImageFilenames.
+func (mw *MagickWand) ReadImageBlobs(blobs [][]byte, continueOnError bool) error {
+	return mw.readImages(len(blobs), continueOnError, func(i int) error {
+		return mw.ReadImageBlob(blobs[i])
+	})
+}
+
+func (mw *MagickWand) readImages(n int, continueOnError bool, read func(i int) error) error {
+	if err := mw.valid(); err != nil {
+		return err
+	}
+	var errs []IndexedError
+	for i := 0; i < n; i++ {
+		err := read(i)
+		var merr *MagickError
+		if err == nil || errors.As(err, &merr) && merr.IsWarning() {
+			continue
+		}
+		errs = append(errs, IndexedError{i, err})
+		if !continueOnError {
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return &MultiReadError{errs}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadImageBlobs(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	blob := func(width uint, format string) []byte {
+		mw := NewMagickWand()
+		defer mw.Destroy()
+		if err := mw.ReadImage("logo:"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.ScaleImage(width, width); err != nil {
+			t.Fatal(err)
+		}
+		if err := mw.SetImageFormat(format); err != nil {
+			t.Fatal(err)
+		}
+		return mw.GetImageBlob()
+	}
+	blobs := [][]byte{blob(10, "PNG"), blob(20, "GIF"), randomBytes(256)}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	err := mw.ReadImageBlobs(blobs, true)
+	var merr *MultiReadError
+	if !errors.As(err, &merr) {
+		t.Fatalf("Expected a *MultiReadError, got %v", err)
+	}
+	if errs := merr.Errors(); len(errs) != 1 || errs[0].Index != 2 || errs[0].Err == nil {
+		t.Fatalf("Expected input 2 to fail, got %v", errs)
+	}
+	if n := mw.GetNumberImages(); n != 2 {
+		t.Fatalf("Expected the 2 valid images to be read, got %d", n)
+	}
+	for i, width := range []uint{10, 20} {
+		mw.SetIteratorIndex(i)
+		if w := mw.GetImageWidth(); w != width {
+			t.Errorf("Image %d is %d wide, expected %d", i, w, width)
+		}
+	}
+
+	// Without continueOnError reading stops at the first failure
+	aborted := NewMagickWand()
+	defer aborted.Destroy()
+	err = aborted.ReadImageBlobs([][]byte{blobs[0], blobs[2], blobs[1]}, false)
+	if !errors.As(err, &merr) || len(merr.Errors()) != 1 || merr.Errors()[0].Index != 1 {
+		t.Fatalf("Expected input 1 to fail, got %v", err)
+	}
+	if n := aborted.GetNumberImages(); n != 1 {
+		t.Errorf("Expected reading to stop after 1 image, got %d", n)
+	}
+
+	ok := NewMagickWand()
+	defer ok.Destroy()
+	if err := ok.ReadImageBlobs(blobs[:2], false); err != nil {
+		t.Fatal(err)
+	}
+	if n := ok.GetNumberImages(); n != 2 {
+		t.Errorf("Expected 2 images, got %d", n)
+	}
+
+	files := NewMagickWand()
+	defer files.Destroy()
+	err = files.ReadImageFilenames([]string{"logo:", "/nonexistent/image.png", "rose:"}, true)
+	if !errors.As(err, &merr) || len(merr.Errors()) != 1 || merr.Errors()[0].Index != 1 {
+		t.Fatalf("Expected input 1 to fail, got %v", err)
+	}
+	if n := files.GetNumberImages(); n != 2 {
+		t.Errorf("Expected 2 images, got %d", n)
+	}
+}
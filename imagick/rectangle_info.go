@@ -12,3 +12,32 @@ import "C"
 type RectangleInfo struct {
 	info *C.RectangleInfo
 }
+
+// Returns the width of the rectangle
+func (ri *RectangleInfo) GetWidth() uint {
+	return uint(ri.info.width)
+}
+
+// Returns the height of the rectangle
+func (ri *RectangleInfo) GetHeight() uint {
+	return uint(ri.info.height)
+}
+
+// Returns the x offset of the rectangle
+func (ri *RectangleInfo) GetX() int {
+	return int(ri.info.x)
+}
+
+// Returns the y offset of the rectangle
+func (ri *RectangleInfo) GetY() int {
+	return int(ri.info.y)
+}
+
+func newRectangleInfo(x, y int, width, height uint) RectangleInfo {
+	return RectangleInfo{&C.RectangleInfo{
+		width:  C.size_t(width),
+		height: C.size_t(height),
+		x:      C.ssize_t(x),
+		y:      C.ssize_t(y),
+	}}
+}
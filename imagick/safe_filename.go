@@ -0,0 +1,113 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SafeFilenameOptions permits some of the special filename syntax rejected
+// by ReadImageSafe and WriteImageSafe
+type SafeFilenameOptions struct {
+	// Format prefixes which are passed on, e.g. "PNG" for "png:image".
+	// Matching ignores case.
+	AllowFormats []string
+	// Pass on a trailing bracket suffix, which selects scenes or a region,
+	// e.g. "image.gif[0]" or "image.png[64x64+0+0]"
+	AllowScenes bool
+}
+
+// A scene selection as ReadImageIndexed accepts it, e.g. "0", "1-3" or "0,2"
+var sceneListRe = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+// Reads an image from a file named by an untrusted source, such as a user
+// upload. ReadImage passes the filename to ImageMagick as is, where
+// "png:name" selects a format, "@list.txt" reads the files listed in
+// list.txt, "|command" runs a command and "image.gif[0]" selects scenes.
+// ReadImageSafe rejects a leading @ or |, reads a filename with a format
+// prefix not allowed by opts as a file of that name in the working
+// directory, and rejects a bracket suffix unless a file of exactly that name
+// exists or opts allows scenes. Use ReadImageIndexed to select scenes.
+// opts may be nil.
+func (mw *MagickWand) ReadImageSafe(filename string, opts *SafeFilenameOptions) error {
+	safe, err := safeFilename(filename, opts)
+	if err != nil {
+		return fmt.Errorf("ReadImageSafe: %s", err)
+	}
+	return mw.ReadImage(safe)
+}
+
+// Writes the current image to a file named by an untrusted source, the way
+// ReadImageSafe reads one. opts may be nil.
+func (mw *MagickWand) WriteImageSafe(filename string, opts *SafeFilenameOptions) error {
+	safe, err := safeFilename(filename, opts)
+	if err != nil {
+		return fmt.Errorf("WriteImageSafe: %s", err)
+	}
+	return mw.WriteImage(safe)
+}
+
+// Reads the scenes of an image file, e.g. "0" for the first frame of an
+// animation or page of a document, "1-3" or "0,2". The filename is treated
+// as by ReadImageSafe with the default options.
+func (mw *MagickWand) ReadImageIndexed(filename string, scenes string) error {
+	if !sceneListRe.MatchString(scenes) {
+		return fmt.Errorf("ReadImageIndexed: invalid scenes %q", scenes)
+	}
+	safe, err := safeFilename(filename, &SafeFilenameOptions{AllowScenes: true})
+	if err != nil {
+		return fmt.Errorf("ReadImageIndexed: %s", err)
+	}
+	return mw.ReadImage(safe + "[" + scenes + "]")
+}
+
+// Returns filename in a form ImageMagick takes literally, or an error if it
+// uses syntax which cannot be escaped
+func safeFilename(filename string, opts *SafeFilenameOptions) (string, error) {
+	if opts == nil {
+		opts = &SafeFilenameOptions{}
+	}
+	if filename == "" {
+		return "", fmt.Errorf("empty filename")
+	}
+
+	prefix, rest := "", filename
+	if m := formatPrefixRe.FindStringSubmatch(filename); m != nil {
+		allowed := false
+		for _, format := range opts.AllowFormats {
+			if strings.EqualFold(format, m[1]) {
+				allowed = true
+				break
+			}
+		}
+		if allowed {
+			prefix, rest = m[0], filename[len(m[0]):]
+		} else {
+			// A path starting with ./ has no format prefix
+			rest = "./" + filename
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "@"):
+		return "", fmt.Errorf("filename %q reads a list of files", filename)
+	case strings.HasPrefix(rest, "|"):
+		return "", fmt.Errorf("filename %q pipes a command", filename)
+	case rest == "-":
+		// Standard input or output
+		rest = "./-"
+	}
+
+	// ImageMagick takes the suffix literally if the whole path exists
+	if strings.HasSuffix(rest, "]") && !opts.AllowScenes {
+		if _, err := os.Stat(rest); err != nil {
+			return "", fmt.Errorf("filename %q selects scenes, use ReadImageIndexed", filename)
+		}
+	}
+	return prefix + rest, nil
+}
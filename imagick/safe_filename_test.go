@@ -0,0 +1,86 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadImageSafe(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	dir, err := ioutil.TempDir("", "imagick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageFormat("PNG"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file literally named with a scene suffix
+	bracketed := filepath.Join(dir, "logo[1]")
+	if err := mw.WriteImageSafe(bracketed, nil); err == nil {
+		t.Fatal("Expected an error writing a scene suffix which is not a file")
+	}
+	if err := ioutil.WriteFile(bracketed, mw.GetImageBlob(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.WriteImageSafe(bracketed, nil); err != nil {
+		t.Fatal(err)
+	}
+	read := NewMagickWand()
+	defer read.Destroy()
+	if err := read.ReadImageSafe(bracketed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if read.GetImageWidth() != mw.GetImageWidth() || read.GetImageHeight() != mw.GetImageHeight() {
+		t.Fatalf("Expected %dx%d, got %dx%d", mw.GetImageWidth(), mw.GetImageHeight(),
+			read.GetImageWidth(), read.GetImageHeight())
+	}
+
+	for _, filename := range []string{"@/etc/hostname", "|cat /etc/hostname", filepath.Join(dir, "logo.png[0]")} {
+		if err := read.ReadImageSafe(filename, nil); err == nil {
+			t.Errorf("%q: expected an error", filename)
+		}
+	}
+	if err := read.ReadImageSafe("logo:", nil); err == nil {
+		t.Error("Expected the logo: prefix to be read as a file name")
+	}
+	if err := read.ReadImageSafe("logo:", &SafeFilenameOptions{AllowFormats: []string{"LOGO"}}); err != nil {
+		t.Errorf("Expected an allowed prefix to be passed on: %s", err)
+	}
+
+	gif := filepath.Join(dir, "frames.gif")
+	frames := gradientSequence(t, 3)
+	defer frames.Destroy()
+	if err := frames.WriteImages(gif, true); err != nil {
+		t.Fatal(err)
+	}
+	indexed := NewMagickWand()
+	defer indexed.Destroy()
+	if err := indexed.ReadImageIndexed(gif, "0,2"); err != nil {
+		t.Fatal(err)
+	}
+	if n := indexed.GetNumberImages(); n != 2 {
+		t.Errorf("Expected scenes 0 and 2, got %d images", n)
+	}
+	if err := indexed.ReadImageIndexed(gif, "0]|ls"); err == nil {
+		t.Error("Expected an error for invalid scenes")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServeOptions controls how ServeImage encodes and serves an image
+type ServeOptions struct {
+	// Formats the image may be served in, e.g. "WEBP", "JPEG", in order of
+	// preference. Empty means the format of the image.
+	Formats []string
+	// Compression quality per upper case format name, e.g. {"JPEG": 82}.
+	// Formats not listed keep the quality of the image.
+	Quality map[string]uint
+	// Value of the Cache-Control header, e.g. "public, max-age=86400". Not
+	// set if empty.
+	CacheControl string
+	// Whether to pick the format from the Accept header of the request. The
+	// first of Formats the client explicitly accepts, e.g. "image/webp", is
+	// served, otherwise the last, which should be one every client can
+	// display such as "JPEG". Without negotiation the first is served.
+	Negotiate bool
+}
+
+// MIME types of the common web formats, others are looked up by extension
+var formatMIMETypes = map[string]string{
+	"AVIF": "image/avif",
+	"BMP":  "image/bmp",
+	"GIF":  "image/gif",
+	"HEIC": "image/heic",
+	"JPEG": "image/jpeg",
+	"JPG":  "image/jpeg",
+	"PNG":  "image/png",
+	"SVG":  "image/svg+xml",
+	"TIFF": "image/tiff",
+	"WEBP": "image/webp",
+}
+
+// Writes the current image to w as the response to r, see ServeOptions.
+// The Content-Type and Content-Length headers are set, and an ETag derived
+// from the image signature, format and quality, so that conditional
+// requests with a matching If-None-Match are answered with 304 Not
+// Modified without encoding the image. If the image cannot be encoded a
+// 500 Internal Server Error is written and the error returned.
+func (mw *MagickWand) ServeImage(w http.ResponseWriter, r *http.Request, opts ServeOptions) error {
+	if err := mw.valid(); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+	formats := opts.Formats
+	if len(formats) == 0 {
+		formats = []string{mw.GetImageFormat()}
+	}
+	format := formats[0]
+	if opts.Negotiate {
+		format = negotiateFormat(r.Header.Get("Accept"), formats)
+		w.Header().Add("Vary", "Accept")
+	}
+	format = strings.ToUpper(format)
+	quality := opts.Quality[format]
+
+	header := w.Header()
+	header.Set("ETag", fmt.Sprintf(`"%.32s-%s-%d"`, mw.GetImageSignature(), strings.ToLower(format), quality))
+	if opts.CacheControl != "" {
+		header.Set("Cache-Control", opts.CacheControl)
+	}
+	if etagMatches(r.Header.Get("If-None-Match"), header.Get("ETag")) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	blob, err := mw.exportBlobQuality(format, quality)
+	if err != nil {
+		header.Del("ETag")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return fmt.Errorf("ServeImage: %w", err)
+	}
+	header.Set("Content-Type", formatMIMEType(format))
+	header.Set("Content-Length", strconv.Itoa(len(blob)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		if _, err := w.Write(blob); err != nil {
+			return fmt.Errorf("ServeImage: %w", err)
+		}
+	}
+	return nil
+}
+
+// Like ExportBlob, with the compression quality set to quality unless it is
+// 0. The quality of the image is restored afterwards.
+func (mw *MagickWand) exportBlobQuality(format string, quality uint) ([]byte, error) {
+	if quality == 0 {
+		return mw.ExportBlob(format)
+	}
+	previous := mw.GetImageCompressionQuality()
+	if err := mw.SetImageCompressionQuality(quality); err != nil {
+		return nil, err
+	}
+	defer mw.SetImageCompressionQuality(previous)
+	return mw.ExportBlob(format)
+}
+
+// Returns the first of formats explicitly accepted by an Accept header,
+// otherwise the last of formats. Wildcards such as "image/*" do not count,
+// as clients send them for formats they cannot display too.
+func negotiateFormat(accept string, formats []string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[mediaType] = q > 0
+	}
+	for _, format := range formats {
+		if accepted[formatMIMEType(strings.ToUpper(format))] {
+			return format
+		}
+	}
+	return formats[len(formats)-1]
+}
+
+// Returns whether an If-None-Match header matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the MIME type of an ImageMagick format name such as "JPEG"
+func formatMIMEType(format string) string {
+	if t, ok := formatMIMETypes[format]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension("." + strings.ToLower(format)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,115 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestServeImage(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	opts := ServeOptions{
+		Formats:      []string{"WEBP", "PNG", "JPEG"},
+		Quality:      map[string]uint{"JPEG": 82},
+		CacheControl: "public, max-age=60",
+		Negotiate:    true,
+	}
+	if !CanWriteFormat("WEBP") {
+		opts.Formats = opts.Formats[1:]
+	}
+
+	serve := func(accept, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/logo", nil)
+		r.Header.Set("Accept", accept)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		if err := mw.ServeImage(w, r, opts); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	// A wildcard alone does not select WebP or PNG
+	w := serve("image/*,*/*;q=0.8", "")
+	if ct := w.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Expected image/jpeg for a wildcard Accept, got %s", ct)
+	}
+	if w.Header().Get("Cache-Control") != opts.CacheControl || w.Header().Get("Vary") != "Accept" {
+		t.Errorf("Unexpected headers %v", w.Header())
+	}
+	if n, _ := strconv.Atoi(w.Header().Get("Content-Length")); n != w.Body.Len() || n == 0 {
+		t.Errorf("Content-Length %s does not match the body of %d bytes", w.Header().Get("Content-Length"), w.Body.Len())
+	}
+	decoded := NewMagickWand()
+	defer decoded.Destroy()
+	if err := decoded.ReadImageBlob(w.Body.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if q := decoded.GetImageCompressionQuality(); q != 82 {
+		t.Errorf("Expected JPEG quality 82, got %d", q)
+	}
+
+	want := "image/png"
+	if CanWriteFormat("WEBP") {
+		want = "image/webp"
+	}
+	w = serve("image/avif,image/webp,image/png,image/*;q=0.8", "")
+	if ct := w.Header().Get("Content-Type"); ct != want {
+		t.Errorf("Expected %s, got %s", want, ct)
+	}
+	if w = serve("image/webp;q=0,image/png", ""); w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected image/png when WebP is refused, got %s", w.Header().Get("Content-Type"))
+	}
+
+	// Revalidation
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+	if w = serve("image/webp;q=0,image/png", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 with an empty body, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if w = serve("image/jpeg", etag); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a different format, got %d", w.Code)
+	}
+}
+
+func TestServeImageEncodeFailure(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+
+	r := httptest.NewRequest(http.MethodGet, "/empty", nil)
+	w := httptest.NewRecorder()
+	if err := mw.ServeImage(w, r, ServeOptions{Formats: []string{"PNG"}}); err == nil {
+		t.Fatal("Expected an error serving an empty wand")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") != "" {
+		t.Error("Expected no ETag on an error response")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"math"
+)
+
+// Longest side of the copy SmartCrop analyses
+const smartCropAnalysisSize = 256
+
+// Crops the current image to width x height around its most detailed
+// region, the window with the most edge energy, instead of the center,
+// which keeps the subject of off-center photos. The window is searched on
+// a grayscale edge map of a copy scaled down to at most 256 pixels. Images
+// without a clear best window, such as uniform images, are cropped at the
+// center. If the image is smaller than width or height along an axis, it
+// is kept whole along that axis.
+func (mw *MagickWand) SmartCrop(width, height uint) error {
+	if width == 0 || height == 0 {
+		return errors.New("SmartCrop: width and height must not be 0")
+	}
+	x, y, err := mw.smartCropWindow(width, height)
+	if err != nil {
+		return err
+	}
+	cols, rows := mw.GetImageWidth(), mw.GetImageHeight()
+	if err := mw.ResetImagePage(""); err != nil {
+		return err
+	}
+	if err := mw.CropImage(minUint(width, cols), minUint(height, rows), x, y); err != nil {
+		return err
+	}
+	return mw.ResetImagePage("")
+}
+
+// Returns the offset of the width x height window SmartCrop keeps
+func (mw *MagickWand) smartCropWindow(width, height uint) (x, y int, err error) {
+	cols, rows := mw.GetImageWidth(), mw.GetImageHeight()
+	if cols == 0 || rows == 0 {
+		return 0, 0, errors.New("SmartCrop: wand has no image")
+	}
+	width, height = minUint(width, cols), minUint(height, rows)
+	centerX, centerY := gravityOffset(cols, rows, width, height, GRAVITY_CENTER)
+	if width == cols && height == rows {
+		return 0, 0, nil
+	}
+
+	analysis, err := mw.currentImage()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer analysis.Destroy()
+	scale := math.Min(1, float64(smartCropAnalysisSize)/float64(maxUint(cols, rows)))
+	aw, ah := scaledSize(cols, scale), scaledSize(rows, scale)
+	if err := analysis.TransformImageColorspace(COLORSPACE_GRAY); err != nil {
+		return 0, 0, err
+	}
+	if aw != cols || ah != rows {
+		if err := analysis.ResizeImage(aw, ah, FILTER_BOX, 1); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := analysis.EdgeImage(1); err != nil {
+		return 0, 0, err
+	}
+	energy := make([]byte, aw*ah)
+	if err := analysis.ExportImagePixelsInto(0, 0, aw, ah, "I", PIXEL_CHAR, energy); err != nil {
+		return 0, 0, err
+	}
+
+	ww, wh := minUint(scaledSize(width, scale), aw), minUint(scaledSize(height, scale), ah)
+	cx, cy := gravityOffset(aw, ah, ww, wh, GRAVITY_CENTER)
+	bx, by, ok := bestWindow(energy, int(aw), int(ah), int(ww), int(wh), cx, cy)
+	if !ok {
+		return centerX, centerY, nil
+	}
+	// Back to full resolution, clamped to the image
+	x = minInt(int(math.Round(float64(bx)/scale)), int(cols-width))
+	y = minInt(int(math.Round(float64(by)/scale)), int(rows-height))
+	return x, y, nil
+}
+
+// Returns the offset of the ww x wh window of an energy map of w x h values
+// with the largest sum, the one closest to cx, cy among equal ones. Not ok
+// if all windows have the same sum.
+func bestWindow(energy []byte, w, h, ww, wh, cx, cy int) (x, y int, ok bool) {
+	// Summed area table with a zero first row and column
+	sums := make([]int64, (w+1)*(h+1))
+	for j := 0; j < h; j++ {
+		var row int64
+		for i := 0; i < w; i++ {
+			row += int64(energy[j*w+i])
+			sums[(j+1)*(w+1)+i+1] = sums[j*(w+1)+i+1] + row
+		}
+	}
+
+	best, worst := int64(-1), int64(math.MaxInt64)
+	bestDistance := 0
+	for j := 0; j+wh <= h; j++ {
+		for i := 0; i+ww <= w; i++ {
+			sum := sums[(j+wh)*(w+1)+i+ww] - sums[j*(w+1)+i+ww] - sums[(j+wh)*(w+1)+i] + sums[j*(w+1)+i]
+			distance := (i-cx)*(i-cx) + (j-cy)*(j-cy)
+			if sum > best || sum == best && distance < bestDistance {
+				best, bestDistance, x, y = sum, distance, i, j
+			}
+			if sum < worst {
+				worst = sum
+			}
+		}
+	}
+	return x, y, best > worst
+}
+
+// Returns size scaled by scale, at least 1
+func scaledSize(size uint, scale float64) uint {
+	if scaled := uint(math.Round(float64(size) * scale)); scaled > 0 {
+		return scaled
+	}
+	return 1
+}
+
+func minUint(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestSmartCrop(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	// All detail is in the bottom right corner
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:white[400x300]"); err != nil {
+		t.Fatal(err)
+	}
+	detail := NewMagickWand()
+	defer detail.Destroy()
+	if err := detail.ReadImage("pattern:checkerboard[80x80]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.CompositeImage(detail, COMPOSITE_OP_COPY, 320, 220); err != nil {
+		t.Fatal(err)
+	}
+
+	x, y, err := mw.smartCropWindow(150, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x < 247 || y < 147 {
+		t.Errorf("Expected the window to cover the bottom right corner, got %d,%d", x, y)
+	}
+	if err := mw.SmartCrop(150, 150); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 150 || h != 150 {
+		t.Errorf("Expected 150x150, got %dx%d", w, h)
+	}
+	if _, _, x, y, _ := mw.GetImagePage(); x != 0 || y != 0 {
+		t.Errorf("Expected the page offset to be reset, got %+d%+d", x, y)
+	}
+
+	// Uniform images are cropped at the center
+	uniform := NewMagickWand()
+	defer uniform.Destroy()
+	if err := uniform.ReadImage("xc:gray[400x300]"); err != nil {
+		t.Fatal(err)
+	}
+	if x, y, err := uniform.smartCropWindow(100, 100); err != nil || x != 150 || y != 100 {
+		t.Errorf("Expected the center window 150,100, got %d,%d (%v)", x, y, err)
+	}
+	// A target larger than the image keeps it whole along that axis
+	if err := uniform.SmartCrop(500, 100); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := uniform.GetImageWidth(), uniform.GetImageHeight(); w != 400 || h != 100 {
+		t.Errorf("Expected 400x100, got %dx%d", w, h)
+	}
+}
+
+func TestBestWindow(t *testing.T) {
+	energy := make([]byte, 10*10)
+	if _, _, ok := bestWindow(energy, 10, 10, 4, 4, 3, 3); ok {
+		t.Error("Expected no best window in a uniform map")
+	}
+	energy[9*10+9] = 255
+	if x, y, ok := bestWindow(energy, 10, 10, 4, 4, 3, 3); !ok || x != 6 || y != 6 {
+		t.Errorf("Expected the window at 6,6, got %d,%d (%v)", x, y, ok)
+	}
+	// Windows holding the same energy tie, the one nearest the center wins
+	energy[9*10+9] = 0
+	energy[5*10+5] = 255
+	if x, y, ok := bestWindow(energy, 10, 10, 4, 4, 3, 3); !ok || x != 3 || y != 3 {
+		t.Errorf("Expected the window at 3,3, got %d,%d (%v)", x, y, ok)
+	}
+}
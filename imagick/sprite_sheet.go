@@ -0,0 +1,87 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+)
+
+// SpriteRect is the position and size of an image in a sprite sheet
+type SpriteRect struct {
+	X, Y          int
+	Width, Height uint
+}
+
+// Places the current image of each wand onto a grid with columns cells per
+// row and returns the sheet along with the rectangle of every image, in the
+// order of images. A column is as wide as its widest image and a row as high
+// as its highest image, each image is placed at the top left of its cell.
+// padding pixels of background separate the cells and surround the sheet.
+// A nil background is transparent.
+func BuildSpriteSheet(images []*MagickWand, columns uint, padding uint, background *PixelWand) (*MagickWand, []SpriteRect, error) {
+	if len(images) == 0 {
+		return nil, nil, errors.New("BuildSpriteSheet: no images")
+	}
+	if columns == 0 {
+		return nil, nil, errors.New("BuildSpriteSheet: columns must not be 0")
+	}
+	if uint(len(images)) < columns {
+		columns = uint(len(images))
+	}
+	rows := (uint(len(images)) + columns - 1) / columns
+
+	colWidths, rowHeights := make([]uint, columns), make([]uint, rows)
+	rects := make([]SpriteRect, len(images))
+	for i, img := range images {
+		w, h := img.GetImageWidth(), img.GetImageHeight()
+		if w == 0 || h == 0 {
+			return nil, nil, errors.New("BuildSpriteSheet: wand has no image")
+		}
+		col, row := uint(i)%columns, uint(i)/columns
+		if w > colWidths[col] {
+			colWidths[col] = w
+		}
+		if h > rowHeights[row] {
+			rowHeights[row] = h
+		}
+		rects[i].Width, rects[i].Height = w, h
+	}
+
+	width := cellOffsets(colWidths, padding)
+	height := cellOffsets(rowHeights, padding)
+	for i := range rects {
+		rects[i].X = int(colWidths[uint(i)%columns])
+		rects[i].Y = int(rowHeights[uint(i)/columns])
+	}
+
+	if background == nil {
+		background = NewPixelWand()
+		defer background.Destroy()
+		background.SetColor("none")
+	}
+	sheet := NewMagickWand()
+	if err := sheet.NewImage(width, height, background); err != nil {
+		sheet.Destroy()
+		return nil, nil, err
+	}
+	for i, img := range images {
+		if err := sheet.CompositeImage(img, COMPOSITE_OP_OVER, rects[i].X, rects[i].Y); err != nil {
+			sheet.Destroy()
+			return nil, nil, err
+		}
+	}
+	return sheet, rects, nil
+}
+
+// Replaces each of sizes with its offset when laid out with padding around
+// every element, and returns the total length.
+func cellOffsets(sizes []uint, padding uint) uint {
+	pos := padding
+	for i, size := range sizes {
+		sizes[i] = pos
+		pos += size + padding
+	}
+	return pos
+}
@@ -0,0 +1,75 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestBuildSpriteSheet(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	sprites := []struct {
+		image string
+		color string
+	}{
+		{"xc:red[30x10]", "red"},
+		{"xc:lime[10x20]", "lime"},
+		{"xc:blue[15x15]", "blue"},
+	}
+	var images []*MagickWand
+	for _, s := range sprites {
+		mw := NewMagickWand()
+		defer mw.Destroy()
+		if err := mw.ReadImage(s.image); err != nil {
+			t.Fatal(err)
+		}
+		images = append(images, mw)
+	}
+
+	sheet, rects, err := BuildSpriteSheet(images, 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Destroy()
+
+	expected := []SpriteRect{
+		{2, 2, 30, 10},
+		{34, 2, 10, 20},
+		{2, 24, 15, 15},
+	}
+	for i, rect := range rects {
+		if rect != expected[i] {
+			t.Errorf("Sprite %d: expected %+v, got %+v", i, expected[i], rect)
+		}
+	}
+	if w, h := sheet.GetImageWidth(), sheet.GetImageHeight(); w != 46 || h != 41 {
+		t.Fatalf("Expected a 46x41 sheet, got %dx%d", w, h)
+	}
+
+	for i, rect := range rects {
+		// Both corners of each sprite hold its color
+		expectPixelColor(t, sheet, rect.X, rect.Y, sprites[i].color)
+		expectPixelColor(t, sheet, rect.X+int(rect.Width)-1, rect.Y+int(rect.Height)-1, sprites[i].color)
+	}
+
+	// The cell of the blue sprite is wider than the sprite
+	pw, err := sheet.GetImagePixelColor(rects[2].X+int(rects[2].Width), rects[2].Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Destroy()
+	if alpha := pw.GetAlpha(); alpha != 0 {
+		t.Fatalf("Expected a transparent background next to the sprite, got alpha %f", alpha)
+	}
+
+	if _, _, err := BuildSpriteSheet(nil, 2, 0, nil); err == nil {
+		t.Fatal("Expected an error without images")
+	}
+}
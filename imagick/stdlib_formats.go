@@ -0,0 +1,117 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// Magic prefixes of the formats RegisterStdlibFormats can register, "?"
+// matches any byte as in image.RegisterFormat
+var stdlibFormatMagic = map[string][]string{
+	"AVIF": {"????ftypavif", "????ftypavis"},
+	"BMP":  {"BM"},
+	"HEIC": {"????ftypheic", "????ftypheix", "????ftypmif1", "????ftypmsf1"},
+	"JP2":  {"\x00\x00\x00\x0cjP  \r\n\x87\n"},
+	"PSD":  {"8BPS"},
+	"TIFF": {"II*\x00", "MM\x00*"},
+	"WEBP": {"RIFF????WEBP"},
+}
+
+var (
+	stdlibFormatsMu sync.Mutex
+	stdlibFormats   = make(map[string]bool)
+)
+
+// Registers ImageMagick decoders with image.RegisterFormat, so that
+// image.Decode and image.DecodeConfig read the formats, e.g. "TIFF",
+// "BMP", "PSD" or "HEIC", through ImageMagick. The other formats known
+// are "AVIF", "JP2" and "WEBP". Decoded images are converted as by ToImage,
+// and DecodeConfig only pings the image. Formats already registered are
+// skipped. Returns an error naming unknown formats, the others are
+// registered anyway. ImageMagick must stay initialized while the decoders
+// are in use, see Initialize.
+func RegisterStdlibFormats(formats ...string) error {
+	stdlibFormatsMu.Lock()
+	defer stdlibFormatsMu.Unlock()
+	var unknown []string
+	for _, format := range formats {
+		format = strings.ToUpper(format)
+		magics, ok := stdlibFormatMagic[format]
+		if !ok {
+			unknown = append(unknown, format)
+			continue
+		}
+		if stdlibFormats[format] {
+			continue
+		}
+		stdlibFormats[format] = true
+		for _, magic := range magics {
+			image.RegisterFormat(strings.ToLower(format), magic, decodeStdlib, decodeStdlibConfig)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("RegisterStdlibFormats: unknown formats %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Decodes an image for image.Decode
+func decodeStdlib(r io.Reader) (image.Image, error) {
+	mw, err := readStdlib(r, (*MagickWand).ReadImageBlob)
+	if err != nil {
+		return nil, err
+	}
+	defer mw.Destroy()
+	return mw.ToImage()
+}
+
+// Pings an image for image.DecodeConfig. The color model is the one
+// ToImage returns, as far as it is known without reading the pixels.
+func decodeStdlibConfig(r io.Reader) (image.Config, error) {
+	mw, err := readStdlib(r, (*MagickWand).PingImageBlob)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer mw.Destroy()
+
+	model := color.NRGBAModel
+	switch {
+	case mw.GetImageDepth() > 8:
+		model = color.NRGBA64Model
+	case mw.GetImageColorspace() == COLORSPACE_GRAY:
+		model = color.GrayModel
+	}
+	return image.Config{
+		ColorModel: model,
+		Width:      int(mw.GetImageWidth()),
+		Height:     int(mw.GetImageHeight()),
+	}, nil
+}
+
+// Returns a new wand holding the image read from r with read. Warnings are
+// ignored when an image was read.
+func readStdlib(r io.Reader, read func(mw *MagickWand, blob []byte) error) (*MagickWand, error) {
+	blob, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	mw := NewMagickWand()
+	err = read(mw, blob)
+	var merr *MagickError
+	if err != nil && !(errors.As(err, &merr) && merr.IsWarning() && mw.GetNumberImages() > 0) {
+		mw.Destroy()
+		return nil, err
+	}
+	mw.ResetIterator()
+	return mw, nil
+}
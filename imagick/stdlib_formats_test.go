@@ -0,0 +1,74 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestRegisterStdlibFormats(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	if err := RegisterStdlibFormats("tiff", "BMP", "NOSUCHFORMAT"); err == nil {
+		t.Error("Expected an error naming the unknown format")
+	}
+	if err := RegisterStdlibFormats("TIFF"); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:red[2000x1500]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetImageDepth(8); err != nil {
+		t.Fatal(err)
+	}
+	tiff, err := mw.ExportBlob("TIFF")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	img, format, err := image.Decode(bytes.NewReader(tiff))
+	decodeTime := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "tiff" {
+		t.Errorf("Expected format tiff, got %s", format)
+	}
+	if b := img.Bounds(); b.Dx() != 2000 || b.Dy() != 1500 {
+		t.Errorf("Expected 2000x1500, got %v", b)
+	}
+	if c := color.NRGBAModel.Convert(img.At(10, 10)); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected red, got %v", c)
+	}
+
+	start = time.Now()
+	config, format, err := image.DecodeConfig(bytes.NewReader(tiff))
+	configTime := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "tiff" || config.Width != 2000 || config.Height != 1500 {
+		t.Errorf("Expected a 2000x1500 tiff, got %s %+v", format, config)
+	}
+	if configTime > decodeTime {
+		t.Errorf("Expected DecodeConfig (%s) to be faster than Decode (%s)", configTime, decodeTime)
+	}
+
+	if _, _, err := image.Decode(bytes.NewReader([]byte("II*\x00garbage"))); err == nil {
+		t.Error("Expected an error decoding a corrupt TIFF")
+	}
+}
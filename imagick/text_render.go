@@ -0,0 +1,185 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// TextRenderOptions configures RenderText
+type TextRenderOptions struct {
+	// A font name as listed by QueryFonts, or the path of a font file. Empty
+	// uses the ImageMagick default font.
+	Font string
+	// The text size in points, 12 if 0
+	PointSize float64
+	// The text color, black if nil
+	Fill *PixelWand
+	// The outline color and width. The text is not outlined if Stroke is
+	// nil or StrokeWidth is 0.
+	Stroke      *PixelWand
+	StrokeWidth float64
+	// The image background, transparent if nil
+	Background *PixelWand
+	// Pixels added on every side of the text
+	Padding uint
+	// If not 0, lines are wrapped to fit this width using the caption:
+	// pseudo format
+	MaxWidth uint
+}
+
+// Returns a new wand holding text rendered as one image, trimmed to the
+// text and extended by opts.Padding on every side. Lines are separated by \n.
+// Returns an error wrapping ErrNotFound if opts.Font is neither a known font
+// nor a font file, instead of falling back to the default font.
+func RenderText(text string, opts TextRenderOptions) (*MagickWand, error) {
+	if text == "" {
+		return nil, errors.New("RenderText: text is empty")
+	}
+	if opts.Font != "" && !fontExists(opts.Font) {
+		return nil, fmt.Errorf("RenderText: font %q: %w", opts.Font, ErrNotFound)
+	}
+	if opts.PointSize <= 0 {
+		opts.PointSize = 12
+	}
+	background := opts.Background
+	if background == nil {
+		background = NewPixelWand()
+		defer background.Destroy()
+		background.SetColor("none")
+	}
+
+	var mw *MagickWand
+	var err error
+	if opts.MaxWidth > 0 {
+		mw, err = renderCaption(text, opts, background)
+	} else {
+		mw, err = renderAnnotation(text, opts, background)
+	}
+	if err == nil {
+		err = mw.trimText(opts.Padding, background)
+	}
+	if err != nil {
+		if mw != nil {
+			mw.Destroy()
+		}
+		return nil, fmt.Errorf("RenderText: %w", err)
+	}
+	return mw, nil
+}
+
+// Draws text on a canvas sized from its font metrics
+func renderAnnotation(text string, opts TextRenderOptions, background *PixelWand) (*MagickWand, error) {
+	dw := NewDrawingWand()
+	defer dw.Destroy()
+	if opts.Font != "" {
+		if err := dw.SetFont(opts.Font); err != nil {
+			return nil, err
+		}
+	}
+	dw.SetFontSize(opts.PointSize)
+	if opts.Fill != nil {
+		dw.SetFillColor(opts.Fill)
+	}
+	if opts.Stroke != nil && opts.StrokeWidth > 0 {
+		dw.SetStrokeColor(opts.Stroke)
+		dw.SetStrokeWidth(opts.StrokeWidth)
+	}
+
+	// The metrics depend on the image resolution, so they are queried on a
+	// scratch image
+	scratch := NewMagickWand()
+	defer scratch.Destroy()
+	if err := scratch.NewImage(1, 1, background); err != nil {
+		return nil, err
+	}
+	metrics, err := scratch.QueryMultilineFontMetrics(dw, text)
+	if err != nil {
+		return nil, err
+	}
+
+	// The margin leaves room for the stroke, italic overhangs and descenders
+	// beyond the metrics, it is trimmed afterwards
+	margin := math.Ceil(opts.StrokeWidth + opts.PointSize/2)
+	width := uint(math.Ceil(metrics.TextWidth + 2*margin))
+	height := uint(math.Ceil(metrics.TextHeight + 2*margin))
+
+	mw := NewMagickWand()
+	if err := mw.NewImage(width, height, background); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	if err := mw.AnnotateImage(dw, margin, margin+metrics.Ascender, 0, text); err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Renders text wrapped to opts.MaxWidth with the caption: pseudo format
+func renderCaption(text string, opts TextRenderOptions, background *PixelWand) (*MagickWand, error) {
+	mw := NewMagickWand()
+	err := mw.setCaptionSettings(opts.MaxWidth, 0, opts.Font, opts.PointSize, opts.Fill, background)
+	if err == nil && opts.Stroke != nil && opts.StrokeWidth > 0 {
+		err = mw.SetOption("stroke", opts.Stroke.GetColorAsString())
+		if err == nil {
+			err = mw.SetOption("strokewidth", fmt.Sprint(opts.StrokeWidth))
+		}
+	}
+	if err == nil {
+		err = mw.SetOption("filename", "caption:"+escapeImageProperties(text))
+	}
+	if err == nil {
+		err = mw.ReadImage("caption:")
+	}
+	if err == nil {
+		err = mw.DeleteOption("filename")
+	}
+	if err != nil {
+		mw.Destroy()
+		return nil, err
+	}
+	return mw, nil
+}
+
+// Crops the current image to its content and adds padding on every side.
+// Text without any visible glyph, e.g. spaces, keeps its rendered size.
+func (mw *MagickWand) trimText(padding uint, background *PixelWand) error {
+	box, err := mw.GetImageBoundingBox(0)
+	if err == nil {
+		err = mw.CropImage(box.GetWidth(), box.GetHeight(), box.GetX(), box.GetY())
+	} else if errors.Is(err, ErrEmptyBoundingBox) {
+		err = nil
+	}
+	if err == nil {
+		err = mw.ResetImagePage("0x0+0+0")
+	}
+	if err == nil && padding > 0 {
+		width := mw.GetImageWidth() + 2*padding
+		height := mw.GetImageHeight() + 2*padding
+		err = mw.ExtentImageGravity(width, height, GRAVITY_CENTER, background)
+	}
+	return err
+}
+
+// Returns whether font names a font known to ImageMagick, ignoring case, or
+// an existing file
+func fontExists(font string) bool {
+	if info, err := os.Stat(font); err == nil && !info.IsDir() {
+		return true
+	}
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	for _, name := range mw.QueryFonts("*") {
+		if strings.EqualFold(name, font) {
+			return true
+		}
+	}
+	return false
+}
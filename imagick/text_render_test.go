@@ -0,0 +1,66 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw, err := RenderText("Hello", TextRenderOptions{PointSize: 48, Padding: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mw.Destroy()
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width < 40 || height < 20 || width <= height {
+		t.Fatalf("Expected a wide image of 48pt text, got %dx%d", width, height)
+	}
+
+	corner, err := mw.GetImagePixelColor(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corner.Destroy()
+	if alpha := corner.GetAlpha(); alpha != 0 {
+		t.Errorf("Expected a transparent corner, got alpha %v", alpha)
+	}
+
+	alpha, err := mw.ExportImagePixels(0, 0, width, height, "A", PIXEL_CHAR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opaque := 0
+	for _, a := range alpha.([]byte) {
+		if a == 255 {
+			opaque++
+		}
+	}
+	if opaque == 0 {
+		t.Error("Expected opaque glyph pixels")
+	}
+
+	wrapped, err := RenderText(strings.Repeat("Hello world ", 8), TextRenderOptions{PointSize: 24, MaxWidth: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wrapped.Destroy()
+	if w, h := wrapped.GetImageWidth(), wrapped.GetImageHeight(); w > 150 || h < 48 {
+		t.Errorf("Expected several lines within 150 pixels, got %dx%d", w, h)
+	}
+
+	font := "No Such Font 7f3a"
+	if _, err := RenderText("Hello", TextRenderOptions{Font: font}); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), font) {
+		t.Errorf("Expected ErrNotFound naming %q, got %v", font, err)
+	}
+}
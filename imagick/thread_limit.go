@@ -0,0 +1,47 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"sync"
+)
+
+// Serializes changes of the thread limit, see WithThreadLimit
+var threadLimitMu sync.Mutex
+
+// Limits the number of OpenMP threads ImageMagick uses to parallelize a
+// single operation such as BlurImage, the same as the MAGICK_THREAD_LIMIT
+// environment variable but at runtime. Use 1 when operations already run in
+// parallel goroutines, to avoid oversubscribing the CPUs. The limit applies
+// to all wands of the process, ImageMagick has no per-wand thread limit.
+// It cannot be raised above the limit ImageMagick started with.
+func SetThreadLimit(n uint) error {
+	if n == 0 {
+		return errors.New("SetThreadLimit: n must not be 0")
+	}
+	threadLimitMu.Lock()
+	defer threadLimitMu.Unlock()
+	return SetResourceLimit(RESOURCE_THREAD, uint64(n))
+}
+
+// Calls fn with the thread limit set to n, see SetThreadLimit, and restores
+// the previous limit afterwards, also if fn panics. Calls are serialized, so
+// concurrent calls run fn one at a time, each with its own limit. As the
+// limit is process-wide, operations of other goroutines running at the same
+// time are limited too.
+func WithThreadLimit(n uint, fn func() error) error {
+	if n == 0 {
+		return errors.New("WithThreadLimit: n must not be 0")
+	}
+	threadLimitMu.Lock()
+	defer threadLimitMu.Unlock()
+	previous := GetResourceLimit(RESOURCE_THREAD)
+	if err := SetResourceLimit(RESOURCE_THREAD, uint64(n)); err != nil {
+		return err
+	}
+	defer SetResourceLimit(RESOURCE_THREAD, previous)
+	return fn()
+}
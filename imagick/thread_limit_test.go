@@ -0,0 +1,123 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestThreadLimit(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	previous := GetResourceLimit(RESOURCE_THREAD)
+	defer SetResourceLimit(RESOURCE_THREAD, previous)
+
+	if err := SetThreadLimit(1); err != nil {
+		t.Fatal(err)
+	}
+	if limit := GetResourceLimit(RESOURCE_THREAD); limit != 1 {
+		t.Fatalf("Expected thread limit 1, got %d", limit)
+	}
+	if err := SetThreadLimit(0); err == nil {
+		t.Fatal("Expected an error for a thread limit of 0")
+	}
+	SetResourceLimit(RESOURCE_THREAD, previous)
+
+	var inside uint64
+	errFn := errors.New("fn failed")
+	if err := WithThreadLimit(1, func() error {
+		inside = GetResourceLimit(RESOURCE_THREAD)
+		return errFn
+	}); err != errFn {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+	if inside != 1 {
+		t.Errorf("Expected thread limit 1 inside fn, got %d", inside)
+	}
+	if limit := GetResourceLimit(RESOURCE_THREAD); limit != previous {
+		t.Errorf("Expected thread limit %d to be restored, got %d", previous, limit)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected the panic of fn to propagate")
+			}
+		}()
+		WithThreadLimit(1, func() error {
+			panic("fn panicked")
+		})
+	}()
+	if limit := GetResourceLimit(RESOURCE_THREAD); limit != previous {
+		t.Errorf("Expected thread limit %d to be restored after a panic, got %d", previous, limit)
+	}
+}
+
+// Compare with and without the limit: with a limit of 1 the 8 goroutines
+// share the CPUs instead of each blur starting a thread per CPU
+func BenchmarkConcurrentBlurThreadLimit1(b *testing.B) {
+	benchmarkConcurrentBlur(b, 1)
+}
+
+func BenchmarkConcurrentBlurUnlimited(b *testing.B) {
+	benchmarkConcurrentBlur(b, 0)
+}
+
+func benchmarkConcurrentBlur(b *testing.B, threads uint) {
+	Initialize()
+	defer Terminate()
+
+	source := NewMagickWand()
+	defer source.Destroy()
+	if err := source.ReadImage("logo:"); err != nil {
+		b.Fatal(err)
+	}
+
+	blur := func() error {
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			errs := make(chan error, 8)
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					mw := source.Clone()
+					defer mw.Destroy()
+					errs <- mw.BlurImage(0, 4)
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	b.ResetTimer()
+	var err error
+	if threads == 0 {
+		err = blur()
+	} else {
+		err = WithThreadLimit(threads, func() error {
+			if limit := GetResourceLimit(RESOURCE_THREAD); limit != uint64(threads) {
+				b.Fatalf("Expected thread limit %d, got %d", threads, limit)
+			}
+			return blur()
+		})
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TileOptions describes the tile pyramid of GenerateTiles
+type TileOptions struct {
+	// Width and height of the tiles, 0 means 256
+	TileSize uint
+	// Pixels each tile shares with its neighbours on every inner side, as
+	// in Deep Zoom images
+	Overlap uint
+	// Lowest zoom level generated. Level 0 is the image scaled to 1x1, each
+	// level doubles the size up to the full image.
+	MinLevel int
+	// Format of the tiles, e.g. "JPEG". Empty keeps the image format.
+	Format string
+	// Whether to pad edge tiles with the background color of the image to
+	// the size of inner tiles
+	Pad bool
+}
+
+// Cuts the current image into a pyramid of tiles for map viewers such as
+// OpenSeadragon, following the Deep Zoom layout: the highest level is the
+// full image, and each level below halves the one above, rounding up, down
+// to a single pixel at level 0. The levels are generated from the highest
+// down to opts.MinLevel by resizing a copy of the image, and each tile is
+// passed to emit with its level, column and row. The tile is destroyed
+// once emit returns, emit must clone it to keep it. An error returned by
+// emit stops GenerateTiles, which returns it. The image is not modified.
+func (mw *MagickWand) GenerateTiles(opts TileOptions, emit func(level, x, y int, tile *MagickWand) error) error {
+	tileSize := opts.TileSize
+	if tileSize == 0 {
+		tileSize = 256
+	}
+	if opts.Overlap >= tileSize {
+		return errors.New("GenerateTiles: Overlap must be less than TileSize")
+	}
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return errors.New("GenerateTiles: wand has no image")
+	}
+	maxLevel := tileLevels(width, height)
+	if opts.MinLevel < 0 || opts.MinLevel > maxLevel {
+		return fmt.Errorf("GenerateTiles: MinLevel must be between 0 and %d", maxLevel)
+	}
+
+	level, err := mw.currentImage()
+	if err != nil {
+		return err
+	}
+	defer level.Destroy()
+	if err := level.ResetImagePage(""); err != nil {
+		return err
+	}
+
+	for l := maxLevel; l >= opts.MinLevel; l-- {
+		if l < maxLevel {
+			width, height = (width+1)/2, (height+1)/2
+			if err := level.ResizeImage(width, height, FILTER_LANCZOS, 1); err != nil {
+				return err
+			}
+		}
+		columns, rows := tileCount(width, tileSize), tileCount(height, tileSize)
+		for row := 0; row < rows; row++ {
+			for column := 0; column < columns; column++ {
+				if err := level.emitTile(opts, tileSize, l, column, row, emit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Cuts the tile at column, row out of the level image and passes it to emit
+func (mw *MagickWand) emitTile(opts TileOptions, tileSize uint, level, column, row int, emit func(level, x, y int, tile *MagickWand) error) error {
+	x, w, padW := tileSpan(mw.GetImageWidth(), tileSize, opts.Overlap, column)
+	y, h, padH := tileSpan(mw.GetImageHeight(), tileSize, opts.Overlap, row)
+	tile, err := mw.GetImageRegion(w, h, x, y)
+	if err != nil {
+		return fmt.Errorf("GenerateTiles: level %d tile %d,%d: %w", level, column, row, err)
+	}
+	defer tile.Destroy()
+	if err := tile.ResetImagePage(""); err != nil {
+		return err
+	}
+	if opts.Pad && (w != padW || h != padH) {
+		if err := tile.ExtentImage(padW, padH, 0, 0); err != nil {
+			return err
+		}
+	}
+	if opts.Format != "" {
+		if err := tile.SetImageFormat(opts.Format); err != nil {
+			return err
+		}
+	}
+	return emit(level, column, row, tile)
+}
+
+// Returns the highest zoom level of an image, the number of halvings which
+// reduce it to a single pixel
+func tileLevels(width, height uint) int {
+	levels := 0
+	for size := maxUint(width, height); size > 1; size = (size + 1) / 2 {
+		levels++
+	}
+	return levels
+}
+
+// Returns the number of tiles covering size pixels
+func tileCount(size, tileSize uint) int {
+	return int((size + tileSize - 1) / tileSize)
+}
+
+// Returns the offset and length of tile i along an axis of size pixels, and
+// the length of an inner tile at that position
+func tileSpan(size, tileSize, overlap uint, i int) (offset int, length, padded uint) {
+	start := uint(i) * tileSize
+	end := start + tileSize
+	padded = tileSize
+	if i > 0 {
+		start -= overlap
+		padded += overlap
+	}
+	if int(end/tileSize) < tileCount(size, tileSize) {
+		end += overlap
+		padded += overlap
+	}
+	if end > size {
+		end = size
+	}
+	return int(start), end - start, padded
+}
+
+func maxUint(a, b uint) uint {
+	if a > b {
+		return a
+	}
+	return b
+}
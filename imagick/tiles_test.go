@@ -0,0 +1,98 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateTiles(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("gradient:red-blue[1000x750]"); err != nil {
+		t.Fatal(err)
+	}
+
+	type size struct{ w, h uint }
+	tiles := make(map[int]map[[2]int]size)
+	opts := TileOptions{TileSize: 256, Overlap: 1, MinLevel: 8, Format: "PNG"}
+	err := mw.GenerateTiles(opts, func(level, x, y int, tile *MagickWand) error {
+		if tiles[level] == nil {
+			tiles[level] = make(map[[2]int]size)
+		}
+		tiles[level][[2]int{x, y}] = size{tile.GetImageWidth(), tile.GetImageHeight()}
+		if f := tile.GetImageFormat(); f != "PNG" {
+			t.Errorf("Expected PNG tiles, got %s", f)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1000x750 at level 10, 500x375 at 9 and 250x188 at 8
+	for level, count := range map[int]int{10: 12, 9: 4, 8: 1} {
+		if n := len(tiles[level]); n != count {
+			t.Errorf("Expected %d tiles at level %d, got %d", count, level, n)
+		}
+	}
+	if len(tiles) != 3 {
+		t.Errorf("Expected 3 levels, got %d", len(tiles))
+	}
+	expected := map[int]map[[2]int]size{
+		10: {{0, 0}: {257, 257}, {1, 1}: {258, 258}, {3, 0}: {233, 257}, {3, 2}: {233, 239}},
+		9:  {{1, 1}: {245, 120}},
+		8:  {{0, 0}: {250, 188}},
+	}
+	for level, sizes := range expected {
+		for pos, want := range sizes {
+			if got := tiles[level][pos]; got != want {
+				t.Errorf("Level %d tile %v is %dx%d, expected %dx%d", level, pos, got.w, got.h, want.w, want.h)
+			}
+		}
+	}
+	if w, h := mw.GetImageWidth(), mw.GetImageHeight(); w != 1000 || h != 750 {
+		t.Errorf("Expected the image to be left untouched, got %dx%d", w, h)
+	}
+
+	// Padded edge tiles have the size of inner tiles
+	opts = TileOptions{TileSize: 256, Overlap: 1, MinLevel: 10, Pad: true}
+	err = mw.GenerateTiles(opts, func(level, x, y int, tile *MagickWand) error {
+		w, h := uint(258), uint(258)
+		if x == 0 || x == 3 {
+			w = 257
+		}
+		if y == 0 || y == 2 {
+			h = 257
+		}
+		if tile.GetImageWidth() != w || tile.GetImageHeight() != h {
+			t.Errorf("Padded tile %d,%d is %dx%d, expected %dx%d", x, y, tile.GetImageWidth(), tile.GetImageHeight(), w, h)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+	emitted := 0
+	err = mw.GenerateTiles(TileOptions{}, func(level, x, y int, tile *MagickWand) error {
+		emitted++
+		if emitted == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || emitted != 3 {
+		t.Errorf("Expected GenerateTiles to stop after 3 tiles with errStop, got %v after %d", err, emitted)
+	}
+}
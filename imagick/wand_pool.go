@@ -0,0 +1,73 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"sync"
+)
+
+// WandPool keeps up to a fixed number of idle MagickWands for reuse, saving
+// the allocation of a new wand per request. Wands are Reset when they are
+// returned, so a wand from Get is indistinguishable from a new one. It is
+// safe for concurrent use.
+type WandPool struct {
+	mu     sync.Mutex
+	idle   chan *MagickWand
+	closed bool
+}
+
+// Returns a pool which keeps at most size idle wands
+func NewWandPool(size int) *WandPool {
+	if size < 0 {
+		size = 0
+	}
+	return &WandPool{idle: make(chan *MagickWand, size)}
+}
+
+// Returns an idle wand of the pool, or a new wand if there is none
+func (p *WandPool) Get() *MagickWand {
+	select {
+	case mw := <-p.idle:
+		return mw
+	default:
+		return NewMagickWand()
+	}
+}
+
+// Resets mw and returns it to the pool. The wand is destroyed if the pool is
+// full or closed. mw must not be used after Put.
+func (p *WandPool) Put(mw *MagickWand) {
+	if err := mw.Reset(); err != nil {
+		mw.Destroy()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		select {
+		case p.idle <- mw:
+			return
+		default:
+		}
+	}
+	mw.Destroy()
+}
+
+// Destroys the idle wands of the pool. Wands returned later with Put are
+// destroyed, Get still returns new wands.
+func (p *WandPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for {
+		select {
+		case mw := <-p.idle:
+			mw.Destroy()
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWandPool(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	pool := NewWandPool(2)
+	defer pool.Close()
+
+	mw := pool.Get()
+	if err := mw.SetSize(32, 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("logo:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetFormat("JPEG"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.SetOption("jpeg:size", "64x64"); err != nil {
+		t.Fatal(err)
+	}
+	mw.SetProgressMonitor(func(string, int64, int64) bool { return true })
+	pool.Put(mw)
+
+	reused := pool.Get()
+	if reused != mw {
+		t.Fatal("Expected the idle wand to be reused")
+	}
+	if n := reused.GetNumberImages(); n != 0 {
+		t.Errorf("Expected no images, got %d", n)
+	}
+	if f := reused.GetFormat(); f != "" {
+		t.Errorf("Expected no format, got %q", f)
+	}
+	if v, _ := reused.GetOption("jpeg:size"); v != "" {
+		t.Errorf("Expected no options, got jpeg:size %q", v)
+	}
+	if cols, rows, _ := reused.GetSize(); cols != 0 || rows != 0 {
+		t.Errorf("Expected no size, got %dx%d", cols, rows)
+	}
+	if reused.GetIteratorIndex() != 0 || reused.progressMonitor != 0 {
+		t.Error("Expected the iterator and progress monitor to be reset")
+	}
+	if err := reused.ReadImage("rose:"); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := reused.GetImageWidth(), reused.GetImageHeight(); w != 70 || h != 46 {
+		t.Errorf("Expected rose: to be read at 70x46, got %dx%d", w, h)
+	}
+	pool.Put(reused)
+
+	// Excess wands are destroyed
+	var wands []*MagickWand
+	for i := 0; i < 4; i++ {
+		wands = append(wands, pool.Get())
+	}
+	for _, w := range wands {
+		pool.Put(w)
+	}
+	if n := len(pool.idle); n != 2 {
+		t.Errorf("Expected 2 idle wands, got %d", n)
+	}
+	if !wands[2].destroyed || !wands[3].destroyed {
+		t.Error("Expected the wands beyond the pool size to be destroyed")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				w := pool.Get()
+				if err := w.ReadImage("xc:red[8x8]"); err != nil {
+					t.Error(err)
+				}
+				pool.Put(w)
+			}
+		}()
+	}
+	wg.Wait()
+
+	pool.Close()
+	if n := len(pool.idle); n != 0 {
+		t.Errorf("Expected Close to destroy the idle wands, %d left", n)
+	}
+}
+
+func benchmarkReadResizeEncode(b *testing.B, get func() *MagickWand, put func(*MagickWand)) {
+	Initialize()
+	defer Terminate()
+
+	source := NewMagickWand()
+	defer source.Destroy()
+	source.ReadImage("logo:")
+	source.SetImageFormat("PNG")
+	blob := source.GetImageBlob()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mw := get()
+		if err := mw.ReadImageBlob(blob); err != nil {
+			b.Fatal(err)
+		}
+		if err := mw.ResizeImage(64, 48, FILTER_LANCZOS, 1); err != nil {
+			b.Fatal(err)
+		}
+		if err := mw.SetImageFormat("JPEG"); err != nil {
+			b.Fatal(err)
+		}
+		if len(mw.GetImageBlob()) == 0 {
+			b.Fatal("Expected a JPEG")
+		}
+		put(mw)
+	}
+}
+
+func BenchmarkNewMagickWandPerRequest(b *testing.B) {
+	benchmarkReadResizeEncode(b, NewMagickWand, (*MagickWand).Destroy)
+}
+
+func BenchmarkWandPool(b *testing.B) {
+	pool := NewWandPool(4)
+	defer pool.Close()
+	benchmarkReadResizeEncode(b, pool.Get, pool.Put)
+}
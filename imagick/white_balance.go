@@ -0,0 +1,100 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"math"
+)
+
+// Balances the colors of the current image assuming that the scene averages
+// to gray: red and blue are scaled so that their means match the mean of
+// green. Images which are already neutral are left about unchanged.
+func (mw *MagickWand) AutoWhiteBalance() error {
+	var means [3]float64
+	for i, channel := range []ChannelType{CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE} {
+		mean, _, err := mw.GetImageChannelMean(channel)
+		if err != nil {
+			return err
+		}
+		means[i] = mean
+	}
+	if means[1] == 0 {
+		return errors.New("AutoWhiteBalance: green channel is black")
+	}
+
+	scale := [3]float64{means[1] / means[0], 1, means[1] / means[2]}
+	if means[0] == 0 {
+		scale[0] = 1
+	}
+	if means[2] == 0 {
+		scale[2] = 1
+	}
+	return mw.scaleChannels(scale)
+}
+
+// Shifts the white balance of the current image by deltaKelvin relative to
+// daylight (6500K). Positive values warm the image up, as if it had been
+// shot under light of a lower color temperature, negative values cool it
+// down. Green is kept so that the brightness stays about the same.
+func (mw *MagickWand) AdjustColorTemperature(deltaKelvin float64) error {
+	const daylight = 6500
+	target := daylight - deltaKelvin
+	if target < 1000 || target > 40000 {
+		return errors.New("AdjustColorTemperature: resulting temperature must be between 1000K and 40000K")
+	}
+
+	from, to := kelvinToRGB(daylight), kelvinToRGB(target)
+	var scale [3]float64
+	for i := range scale {
+		scale[i] = to[i] / from[i]
+	}
+	for i := range scale {
+		scale[i] /= scale[1]
+	}
+	return mw.scaleChannels(scale)
+}
+
+// Multiplies red, green and blue by scale, clamping to the quantum range
+func (mw *MagickWand) scaleChannels(scale [3]float64) error {
+	for i, channel := range []ChannelType{CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE} {
+		if scale[i] == 1 {
+			continue
+		}
+		if err := mw.EvaluateImageChannel(channel, EVAL_OP_MULTIPLY, scale[i]); err != nil {
+			return err
+		}
+	}
+	return mw.ClampImage()
+}
+
+// Returns the color of a black body at the given temperature as red, green
+// and blue from 0 to 1, using Tanner Helland's approximation.
+func kelvinToRGB(kelvin float64) [3]float64 {
+	t := kelvin / 100
+	var r, g, b float64
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+
+	rgb := [3]float64{r, g, b}
+	for i, v := range rgb {
+		// Keep a floor so that channel ratios stay finite
+		rgb[i] = math.Max(1, math.Min(255, v)) / 255
+	}
+	return rgb
+}
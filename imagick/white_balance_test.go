@@ -0,0 +1,90 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func channelMeans(t *testing.T, mw *MagickWand) [3]float64 {
+	t.Helper()
+
+	_, quantumRange := GetQuantumRange()
+	var means [3]float64
+	for i, channel := range []ChannelType{CHANNEL_RED, CHANNEL_GREEN, CHANNEL_BLUE} {
+		mean, _, err := mw.GetImageChannelMean(channel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		means[i] = mean / float64(quantumRange)
+	}
+	return means
+}
+
+func TestAutoWhiteBalance(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.SetSize(16, 64); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.ReadImage("gradient:rgb(40,60,120)-rgb(140,160,220)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.AutoWhiteBalance(); err != nil {
+		t.Fatal(err)
+	}
+	means := channelMeans(t, mw)
+	if diff := means[0] - means[1]; diff < -0.01 || diff > 0.01 {
+		t.Errorf("Expected red to match green, got means %v", means)
+	}
+	if diff := means[2] - means[1]; diff < -0.01 || diff > 0.01 {
+		t.Errorf("Expected blue to match green, got means %v", means)
+	}
+
+	neutral := NewMagickWand()
+	defer neutral.Destroy()
+	if err := neutral.ReadImage("xc:gray50[8x8]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := neutral.AutoWhiteBalance(); err != nil {
+		t.Fatal(err)
+	}
+	expectPixelColor(t, neutral, 4, 4, "gray50")
+}
+
+func TestAdjustColorTemperature(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	mw := NewMagickWand()
+	defer mw.Destroy()
+	if err := mw.ReadImage("xc:gray50[8x8]"); err != nil {
+		t.Fatal(err)
+	}
+	before := channelMeans(t, mw)
+	if err := mw.AdjustColorTemperature(1000); err != nil {
+		t.Fatal(err)
+	}
+	after := channelMeans(t, mw)
+	if after[0] <= before[0] {
+		t.Errorf("Expected warming to raise red, got %f -> %f", before[0], after[0])
+	}
+	if after[2] >= before[2] {
+		t.Errorf("Expected warming to lower blue, got %f -> %f", before[2], after[2])
+	}
+
+	if err := mw.AdjustColorTemperature(6000); err == nil {
+		t.Fatal("Expected an error for a temperature of 500K")
+	}
+}
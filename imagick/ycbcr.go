@@ -0,0 +1,56 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Returns the current image as planar 8-bit Y, Cb and Cr with 4:2:0 chroma
+// subsampling, as expected by video encoders. The conversion is done by
+// ImageMagick's YCbCr colorspace, which uses the full range BT.601 matrix:
+//
+//	Y  =  0.299    R + 0.587    G + 0.114    B
+//	Cb = -0.168736 R - 0.331264 G + 0.5      B + 128
+//	Cr =  0.5      R - 0.418688 G - 0.081312 B + 128
+//
+// y has strideY bytes per row and the height of the image. The chroma planes
+// are half the size of the image, rounded up for odd sizes, and scaled with
+// a triangle filter. They have strideC bytes per row. Alpha is ignored.
+func (mw *MagickWand) ExportYCbCr420() (y, cb, cr []byte, strideY, strideC int, err error) {
+	width, height := mw.GetImageWidth(), mw.GetImageHeight()
+	if width == 0 || height == 0 {
+		return nil, nil, nil, 0, 0, errors.New("ExportYCbCr420: wand has no image")
+	}
+	ycc, err := mw.currentImage()
+	if err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+	defer ycc.Destroy()
+	if err := ycc.TransformImageColorspace(COLORSPACE_YCBCR); err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+
+	// After the transform the red channel holds Y, green Cb and blue Cr
+	y = make([]byte, int(width)*int(height))
+	if err := ycc.exportPixels(0, 0, width, height, "R", PIXEL_CHAR, unsafe.Pointer(&y[0])); err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+	if err := ycc.ResizeImage(chromaWidth, chromaHeight, FILTER_TRIANGLE, 1); err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+	cb = make([]byte, int(chromaWidth)*int(chromaHeight))
+	if err := ycc.exportPixels(0, 0, chromaWidth, chromaHeight, "G", PIXEL_CHAR, unsafe.Pointer(&cb[0])); err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+	cr = make([]byte, len(cb))
+	if err := ycc.exportPixels(0, 0, chromaWidth, chromaHeight, "B", PIXEL_CHAR, unsafe.Pointer(&cr[0])); err != nil {
+		return nil, nil, nil, 0, 0, err
+	}
+	return y, cb, cr, int(width), int(chromaWidth), nil
+}
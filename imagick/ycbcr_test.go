@@ -0,0 +1,57 @@
+// Copyright 2013 Herbert G. Fischer. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagick
+
+import (
+	"testing"
+)
+
+func TestExportYCbCr420(t *testing.T) {
+	Initialize()
+	defer func(t *testing.T) {
+		checkGC(t)
+	}(t)
+	defer Terminate()
+
+	tests := []struct {
+		color     string
+		y, cb, cr int
+	}{
+		{"rgb(128,128,128)", 128, 128, 128},
+		// 0.299*255, 128-0.168736*255, 128+0.5*255 clamped
+		{"red", 76, 85, 255},
+	}
+	for _, test := range tests {
+		mw := NewMagickWand()
+		pw := NewPixelWand()
+		pw.SetColor(test.color)
+		// Odd sizes round the chroma planes up
+		if err := mw.NewImage(9, 5, pw); err != nil {
+			t.Fatal(err)
+		}
+
+		y, cb, cr, strideY, strideC, err := mw.ExportYCbCr420()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strideY != 9 || strideC != 5 || len(y) != 9*5 || len(cb) != 5*3 || len(cr) != 5*3 {
+			t.Fatalf("%s: unexpected plane sizes, strides %d and %d, lengths %d, %d and %d",
+				test.color, strideY, strideC, len(y), len(cb), len(cr))
+		}
+		for _, plane := range []struct {
+			name   string
+			values []byte
+			want   int
+		}{{"Y", y, test.y}, {"Cb", cb, test.cb}, {"Cr", cr, test.cr}} {
+			for i, v := range plane.values {
+				if d := int(v) - plane.want; d < -2 || d > 2 {
+					t.Fatalf("%s: %s[%d] is %d, want %d", test.color, plane.name, i, v, plane.want)
+				}
+			}
+		}
+		pw.Destroy()
+		mw.Destroy()
+	}
+}